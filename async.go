@@ -0,0 +1,65 @@
+package config
+
+import "sync"
+
+// OptAsyncHandlers dispatches reload handlers concurrently on up to workers
+// goroutines, instead of serially on the reload goroutine, so that a slow
+// handler delays neither the other handlers nor the next reload. Each handler
+// still receives configurations one at a time and in order.
+func OptAsyncHandlers(workers int) Option {
+	return func(c *Loader) {
+		if workers < 1 {
+			workers = 1
+		}
+		c.dispatcher = &dispatcher{
+			sem:    make(chan struct{}, workers),
+			queues: make(map[uint64]*dispatchQueue),
+		}
+	}
+}
+
+// dispatcher runs tasks concurrently with a bounded number of workers, while
+// running tasks sharing the same key sequentially, in order
+type dispatcher struct {
+	sem chan struct{}
+
+	mu     sync.Mutex
+	queues map[uint64]*dispatchQueue
+}
+
+type dispatchQueue struct {
+	tasks []func()
+}
+
+func (d *dispatcher) dispatch(key uint64, task func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	q, running := d.queues[key]
+	if !running {
+		q = &dispatchQueue{}
+		d.queues[key] = q
+	}
+	q.tasks = append(q.tasks, task)
+	if !running {
+		go d.drain(key, q)
+	}
+}
+
+func (d *dispatcher) drain(key uint64, q *dispatchQueue) {
+	for {
+		d.mu.Lock()
+		if len(q.tasks) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		task := q.tasks[0]
+		q.tasks = q.tasks[1:]
+		d.mu.Unlock()
+
+		d.sem <- struct{}{}
+		task()
+		<-d.sem
+	}
+}
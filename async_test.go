@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestAsyncHandlers(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var mu sync.Mutex
+	var slow, fast []string
+	slowDone := make(chan struct{})
+	fastDone := make(chan struct{})
+
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.OptAsyncHandlers(2),
+		config.ReloadHandler(func(cfg interface{}) {
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			slow = append(slow, cfg.(*testConfig).Name)
+			if len(slow) == 3 {
+				close(slowDone)
+			}
+		}),
+		config.ReloadHandler(func(cfg interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			fast = append(fast, cfg.(*testConfig).Name)
+			if len(fast) == 3 {
+				close(fastDone)
+			}
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		c.Update([]byte(fmt.Sprintf("name: update%v\n", i)))
+	}
+
+	select {
+	case <-fastDone:
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for fast handler")
+	}
+	mu.Lock()
+	assert.That(len(slow) < 3, pred.IsEqualTo(true))
+	mu.Unlock()
+
+	select {
+	case <-slowDone:
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for slow handler")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"update0", "update1", "update2"}
+	assert.That(slow, pred.IsEqualTo(expected))
+	assert.That(fast, pred.IsEqualTo(expected))
+}
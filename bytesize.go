@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that can be expressed in configuration files
+// either as a plain integer or as a string with a unit, e.g. "64MiB" or
+// "1.5GB".
+type ByteSize uint64
+
+// Common byte size units, with both decimal (SI) and binary (IEC) multiples.
+const (
+	B ByteSize = 1
+
+	KB ByteSize = 1000 * B
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+
+	KiB ByteSize = 1024 * B
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+)
+
+var byteSizeUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB},
+	{"B", B},
+}
+
+// ParseByteSize parses a byte size made of a number, optionally followed by
+// a decimal (KB, MB, GB, TB, PB) or binary (KiB, MiB, GiB, TiB, PiB) unit.
+// Units are case-insensitive, and a number without unit is a number of
+// bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := str, ""
+	if i != -1 {
+		number, unit = str[:i], strings.TrimSpace(str[i:])
+	}
+
+	multiplier := B
+	if unit != "" {
+		multiplier = 0
+		for _, u := range byteSizeUnits {
+			if strings.EqualFold(unit, u.name) {
+				multiplier = u.size
+				break
+			}
+		}
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil || multiplier == 0 {
+		return 0, fmt.Errorf("invalid byte size %q, expected a value like \"512\", \"64MiB\" or \"1.5GB\"", s)
+	}
+
+	size := f * float64(multiplier)
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q is out of range", s)
+	}
+	return ByteSize(size), nil
+}
+
+// String returns the size expressed in the largest unit that represents it
+// exactly, e.g. "64MiB".
+func (b ByteSize) String() string {
+	if b == 0 {
+		return "0B"
+	}
+	for _, u := range byteSizeUnits {
+		if b%u.size == 0 {
+			return fmt.Sprintf("%d%v", b/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(b))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+// byteSizeDecodeHook decodes ByteSize values from plain integers and strings
+// with units.
+func byteSizeDecodeHook(data interface{}, to reflect.Type) (interface{}, error) {
+	if to != byteSizeType {
+		return data, nil
+	}
+
+	switch v := data.(type) {
+	case string:
+		return ParseByteSize(v)
+	case json.Number:
+		return ParseByteSize(v.String())
+	}
+	return data, nil
+}
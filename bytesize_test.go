@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestParseByteSize(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var tcs = []struct {
+		input    string
+		expected config.ByteSize
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"64MiB", 64 * config.MiB},
+		{"64 mib", 64 * config.MiB},
+		{"1.5GB", 1500 * config.MB},
+		{"2KiB", 2048},
+		{"1TB", config.TB},
+	}
+
+	for _, tc := range tcs {
+		v, err := config.ParseByteSize(tc.input)
+		assert.That(err, pred.IsNil(), "input: %v", tc.input)
+		assert.That(v, pred.IsEqualTo(tc.expected), "input: %v", tc.input)
+	}
+}
+
+func TestParseByteSizeErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	for _, input := range []string{"", "MiB", "12XB", "-1KB", "1.2.3MB"} {
+		_, err := config.ParseByteSize(input)
+		assert.That(err, pred.IsNotNil(), "input: %v", input)
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	assert.That(config.ByteSize(0).String(), pred.IsEqualTo("0B"))
+	assert.That((64 * config.MiB).String(), pred.IsEqualTo("64MiB"))
+	assert.That((1500 * config.MB).String(), pred.IsEqualTo("1500MB"))
+	assert.That(config.ByteSize(1001).String(), pred.IsEqualTo("1001B"))
+}
+
+type byteSizeConfig struct {
+	CacheSize  config.ByteSize  `json:"cache_size"`
+	BufferSize config.ByteSize  `json:"buffer_size"`
+	MaxSize    *config.ByteSize `json:"max_size"`
+}
+
+func TestDecodeByteSize(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+cache_size: 64MiB
+buffer_size: 4096
+max_size: 1.5GB
+`, byteSizeConfig{})
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*byteSizeConfig)
+	assert.That(cfg.CacheSize, pred.IsEqualTo(64*config.MiB))
+	assert.That(cfg.BufferSize, pred.IsEqualTo(config.ByteSize(4096)))
+	assert.That(*cfg.MaxSize, pred.IsEqualTo(1500*config.MB))
+}
+
+func TestDecodeInvalidByteSize(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, `
+cache_size: lots
+`, byteSizeConfig{})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Matches(`^cache_size: invalid byte size "lots"`))
+}
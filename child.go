@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// ChildConfigEnv is the environment variable pointing child processes started
+// through a ChildConfig to their configuration file
+const ChildConfigEnv = "GO_CONFIG_FILE"
+
+// ChildConfig shares the current configuration of a loader with child
+// processes through a file that is re-written on every reload. Child
+// processes can load and watch that file with their own loader, and are
+// optionally signaled after each update.
+type ChildConfig struct {
+	loader        *Loader
+	filename      string
+	signal        os.Signal
+	removeHandler func()
+
+	mu        sync.Mutex
+	closed    bool
+	processes map[*os.Process]struct{}
+}
+
+// NewChildConfig writes the current configuration to a temporary file to be
+// shared with child processes. If sig is not nil, it is sent to all processes
+// started through the ChildConfig after every reload, e.g. syscall.SIGHUP.
+func (c *Loader) NewChildConfig(sig os.Signal) (*ChildConfig, error) {
+	f, err := ioutil.TempFile("", "go-config-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	p := &ChildConfig{
+		loader:    c,
+		filename:  f.Name(),
+		signal:    sig,
+		processes: make(map[*os.Process]struct{}),
+	}
+	if err := p.write(c.Get()); err != nil {
+		os.Remove(p.filename)
+		return nil, err
+	}
+
+	p.removeHandler = c.AddReloadHandler(p.reload)
+	return p, nil
+}
+
+// Filename returns the name of the file holding the shared configuration
+func (p *ChildConfig) Filename() string {
+	return p.filename
+}
+
+// Start starts cmd with ChildConfigEnv added to its environment, and tracks
+// the resulting process to signal it after every reload
+func (p *ChildConfig) Start(cmd *exec.Cmd) error {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env, fmt.Sprintf("%v=%v", ChildConfigEnv, p.filename))
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processes[cmd.Process] = struct{}{}
+	return nil
+}
+
+// Close stops updating the shared configuration file and removes it
+func (p *ChildConfig) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	p.processes = nil
+	p.removeHandler()
+	return os.Remove(p.filename)
+}
+
+// ---------------------------------------------------------------------------
+// ChildConfig implementation
+// ---------------------------------------------------------------------------
+
+func (p *ChildConfig) reload(cfg interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+
+	if err := p.write(cfg); err != nil {
+		p.loader.handleError(err)
+		return
+	}
+	if p.signal == nil {
+		return
+	}
+	for process := range p.processes {
+		err := process.Signal(p.signal)
+		if errors.Is(err, os.ErrProcessDone) {
+			delete(p.processes, process)
+		} else if err != nil {
+			p.loader.handleError(err)
+		}
+	}
+}
+
+// write replaces the content of the shared file atomically, so that child
+// processes never observe a partially written configuration
+func (p *ChildConfig) write(cfg interface{}) error {
+	data, err := p.loader.marshalConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	tmp := p.filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.filename)
+}
@@ -0,0 +1,34 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestChildConfigRewrittenOnReload(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: first\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	p, err := c.NewChildConfig(nil)
+	assert.That(err, pred.IsNil())
+	defer p.Close()
+
+	child, err := config.NewLoader(p.Filename(), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer child.Close()
+	assert.That(child.Get().(*testConfig).Name, pred.IsEqualTo("first"))
+	assert.That(child.Get().(*testConfig).Port, pred.IsEqualTo(1234))
+
+	assert.That(c.Update([]byte("name: second\n")), pred.IsNil())
+	content, err := ioutil.ReadFile(p.Filename())
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.Contains("second"))
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package config_test
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestChildConfigStart(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: first\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	p, err := c.NewChildConfig(syscall.SIGHUP)
+	assert.That(err, pred.IsNil())
+	defer p.Close()
+
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", `cat "$GO_CONFIG_FILE"`)
+	cmd.Stdout = &out
+	assert.That(p.Start(cmd), pred.IsNil())
+	assert.That(cmd.Wait(), pred.IsNil())
+	assert.That(out.String(), pred.Contains("first"))
+
+	cmd = exec.Command("sleep", "10")
+	assert.That(p.Start(cmd), pred.IsNil())
+	assert.That(c.Update([]byte("name: second\n")), pred.IsNil())
+
+	err = cmd.Wait()
+	assert.That(err, pred.IsNotNil())
+	assert.That(strings.Contains(err.Error(), "hangup"), pred.IsEqualTo(true))
+}
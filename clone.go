@@ -0,0 +1,108 @@
+package config
+
+import "reflect"
+
+// Cloner can be implemented by configuration types that need a custom deep
+// copy, e.g. types holding resources that cannot be copied field by field.
+// Clone must return a value of the same type as the receiver.
+type Cloner interface {
+	Clone() interface{}
+}
+
+var clonerType = reflect.TypeOf((*Cloner)(nil)).Elem()
+
+// OptCloneFunc sets the function used to copy the default configuration
+// before decoding content over it, and the configuration returned by Get with
+// FreezeCopy, for configuration types that cannot be copied through
+// reflection, e.g. types embedding sync primitives or cgo handles. f receives
+// a pointer to the configuration and must return a pointer of the same type
+// to an independent copy.
+func OptCloneFunc(f func(interface{}) interface{}) Option {
+	return func(c *Loader) {
+		c.cloneFunc = f
+	}
+}
+
+// cloneDefaults returns a copy of the default configuration
+func (c *Loader) cloneDefaults() interface{} {
+	return c.cloneConfig(c.defaultConfig)
+}
+
+// cloneConfig returns a copy of a configuration, using the function set by
+// OptCloneFunc if any
+func (c *Loader) cloneConfig(cfg interface{}) interface{} {
+	if c.cloneFunc != nil {
+		return c.cloneFunc(cfg)
+	}
+	return cloneStruct(cfg)
+}
+
+// deepClone returns a deep copy of v, so that the copy shares no map, slice
+// or pointed-to value with the original. Unexported struct fields are copied
+// as is, which preserves values like time.Time.
+func deepClone(v reflect.Value) reflect.Value {
+	t := v.Type()
+	c := reflect.New(t).Elem()
+
+	// Pointers and interfaces are cloned through the value they point to
+	if k := v.Kind(); k != reflect.Ptr && k != reflect.Interface {
+		if t.Implements(clonerType) {
+			c.Set(reflect.ValueOf(v.Interface().(Cloner).Clone()))
+			return c
+		}
+		if reflect.PtrTo(t).Implements(clonerType) {
+			p := reflect.New(t)
+			p.Elem().Set(v)
+			c.Set(reflect.ValueOf(p.Interface().(Cloner).Clone()).Elem())
+			return c
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			p := reflect.New(t.Elem())
+			p.Elem().Set(deepClone(v.Elem()))
+			c.Set(p)
+		}
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			c.Set(deepClone(v.Elem()))
+		}
+
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" {
+				c.Field(i).Set(deepClone(v.Field(i)))
+			}
+		}
+
+	case reflect.Map:
+		if !v.IsNil() {
+			c.Set(reflect.MakeMapWithSize(t, v.Len()))
+			iter := v.MapRange()
+			for iter.Next() {
+				c.SetMapIndex(iter.Key(), deepClone(iter.Value()))
+			}
+		}
+
+	case reflect.Slice:
+		if !v.IsNil() {
+			c.Set(reflect.MakeSlice(t, v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				c.Index(i).Set(deepClone(v.Index(i)))
+			}
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepClone(v.Index(i)))
+		}
+
+	default:
+		c.Set(v)
+	}
+	return c
+}
@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type cloneTestItem struct {
+	Name string `json:"name"`
+}
+
+type customClone struct {
+	Value  string
+	clones *int
+}
+
+func (c *customClone) Clone() interface{} {
+	*c.clones++
+	return &customClone{Value: c.Value, clones: c.clones}
+}
+
+type cloneTestConfig struct {
+	Items   []*cloneTestItem          `json:"items"`
+	Tags    map[string][]string       `json:"tags"`
+	Started time.Time                 `json:"started"`
+	Nested  **cloneTestItem           `json:"nested"`
+	Custom  customClone               `json:"-"`
+	ByName  map[string]*cloneTestItem `json:"by_name"`
+}
+
+func TestCloneDefaultsDeeply(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	clones := 0
+	item := &cloneTestItem{Name: "nested"}
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	defaults := &cloneTestConfig{
+		Items:   []*cloneTestItem{{Name: "first"}},
+		Tags:    map[string][]string{"env": {"prod"}},
+		Started: started,
+		Nested:  &item,
+		Custom:  customClone{Value: "custom", clones: &clones},
+		ByName:  map[string]*cloneTestItem{"a": {Name: "a"}},
+	}
+
+	c, err := config.NewLoaderFromBytes([]byte("{}"), defaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	cfg := c.Get().(*cloneTestConfig)
+	cfg.Items[0].Name = "modified"
+	cfg.Tags["env"][0] = "modified"
+	(*cfg.Nested).Name = "modified"
+	cfg.ByName["a"].Name = "modified"
+
+	assert.That(defaults.Items[0].Name, pred.IsEqualTo("first"))
+	assert.That(defaults.Tags["env"][0], pred.IsEqualTo("prod"))
+	assert.That(item.Name, pred.IsEqualTo("nested"))
+	assert.That(defaults.ByName["a"].Name, pred.IsEqualTo("a"))
+	assert.That(cfg.Started.Equal(started), pred.IsEqualTo(true))
+	assert.That(cfg.Custom.Value, pred.IsEqualTo("custom"))
+	assert.That(clones > 0, pred.IsEqualTo(true))
+}
+
+func TestCloneFunc(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var cloned int
+	c, err := config.NewLoaderFromBytes([]byte("name: custom\n"), testConfigDefaults,
+		config.OptCloneFunc(func(v interface{}) interface{} {
+			cloned++
+			cfg := *v.(*testConfig)
+			return &cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(cloned, pred.IsEqualTo(1))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("custom"))
+	assert.That(c.Get().(*testConfig).Port, pred.IsEqualTo(1234))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// runConvert converts a configuration file between the formats listed in
+// formats, reading the input in the format matching its extension. The
+// conversion is lossless for the values of the document, which convert back
+// to the same values and types; values the output format cannot represent,
+// like null values in TOML, are reported as errors. Comments and key order
+// are not part of the document and are not preserved, keys are written in
+// sorted order.
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	to := flags.String("to", "", "output format, yaml, json or toml")
+	output := flags.String("o", "", "write the output to a file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config convert -to format [-o file] config.yaml\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || *to == "" {
+		flags.Usage()
+		return exitUsage
+	}
+	if _, ok := formats[*to]; !ok {
+		fmt.Fprintf(stderr, "go-config: unsupported output format %q\n", *to)
+		return exitUsage
+	}
+
+	doc, err := readDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v: %v\n", flags.Arg(0), err)
+		return exitFailure
+	}
+	var b bytes.Buffer
+	if err = writeDocument(&b, doc, *to); err == nil {
+		if *output != "" {
+			err = ioutil.WriteFile(*output, b.Bytes(), 0666)
+		} else {
+			_, err = stdout.Write(b.Bytes())
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
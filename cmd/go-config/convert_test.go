@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config convert
+// ---------------------------------------------------------------------------
+
+func TestConvertCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "name: base\nport: 80\nratio: 0.5\nservers:\n  - a\n  - b\n",
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("convert", "-to", "json", filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stdout, pred.IsEqualTo(`{
+  "name": "base",
+  "port": 80,
+  "ratio": 0.5,
+  "servers": [
+    "a",
+    "b"
+  ]
+}
+`))
+
+	jsonFile := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(jsonFile, []byte(stdout), 0666)
+	status, _, _ = runCommand("convert", "-to", "yaml", "-o", filepath.Join(dir, "out.yaml"), jsonFile)
+	assert.That(status, pred.IsEqualTo(exitOK))
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "out.yaml"))
+	assert.That(string(content), pred.Contains("port: 80\n"))
+	assert.That(string(content), pred.Contains("ratio: 0.5\n"))
+}
+
+func TestConvertCommandWithUnsupportedFormat(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	status, _, stderr := runCommand("convert", "-to", "xml", "config.yaml")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+	assert.That(stderr, pred.Contains(`unsupported output format "xml"`))
+}
+
+func TestConvertCommandWithTOML(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": `name: base
+port: 80
+ratio: 0.5
+labels:
+  app.kubernetes.io/name: web
+server:
+  tls:
+    enabled: true
+backends:
+  - host: a.local
+  - host: b.local
+    weights: [1, 2]
+`,
+	})
+	defer cleanup()
+
+	tomlFile := filepath.Join(dir, "config.toml")
+	status, _, _ := runCommand("convert", "-to", "toml", "-o", tomlFile, filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitOK))
+	content, _ := ioutil.ReadFile(tomlFile)
+	assert.That(string(content), pred.IsEqualTo(`name = "base"
+port = 80
+ratio = 0.5
+
+[labels]
+"app.kubernetes.io/name" = "web"
+
+[server]
+
+[server.tls]
+enabled = true
+
+[[backends]]
+host = "a.local"
+
+[[backends]]
+host = "b.local"
+weights = [1, 2]
+`))
+
+	status, stdout, _ := runCommand("convert", "-to", "yaml", tomlFile)
+	assert.That(status, pred.IsEqualTo(exitOK))
+	original, _ := readRawDocument(filepath.Join(dir, "config.yaml"))
+	converted, err := decodeYAML([]byte(stdout))
+	assert.That(err, pred.IsNil())
+	assert.That(converted, pred.IsEqualTo(original))
+}
+
+func TestConvertCommandWithNullToTOML(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "name: base\nserver:\n  host: null\n",
+	})
+	defer cleanup()
+
+	status, _, stderr := runCommand("convert", "-to", "toml", filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stderr, pred.Contains("server.host: null values cannot be represented in toml"))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/marcus999/go-config"
+)
+
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	showSecrets := flags.Bool("show-secrets", false, "do not redact secret values")
+	asJSON := flags.Bool("json", false, "print the changes as a JSON array")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config diff [-json] [-show-secrets] old.yaml new.yaml\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	old, err := config.ReadDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v: %v\n", flags.Arg(0), err)
+		return exitUsage
+	}
+	new, err := config.ReadDocument(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v: %v\n", flags.Arg(1), err)
+		return exitUsage
+	}
+
+	changes := config.DiffDocuments(old, new)
+	if !*showSecrets {
+		for i, c := range changes {
+			changes[i] = c.Redacted()
+		}
+	}
+
+	if *asJSON {
+		if changes == nil {
+			changes = []config.Change{}
+		}
+		e := json.NewEncoder(stdout)
+		e.SetIndent("", "  ")
+		e.SetEscapeHTML(false)
+		e.Encode(changes)
+	} else {
+		for _, c := range changes {
+			fmt.Fprintln(stdout, c)
+		}
+	}
+
+	// Like diff(1), exit with status 1 when the files differ
+	if len(changes) != 0 {
+		return exitFailure
+	}
+	return exitOK
+}
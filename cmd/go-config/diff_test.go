@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config diff
+// ---------------------------------------------------------------------------
+
+func TestDiffCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"old.yaml": "name: a\npassword: one\n",
+		"new.json": `{"name": "b", "password": "two"}`,
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("diff",
+		filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.json"))
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stdout, pred.IsEqualTo(
+		"~ name: \"a\" -> \"b\"\n"+
+			"~ password: \"<redacted>\" -> \"<redacted>\"\n"))
+
+	status, stdout, _ = runCommand("diff", "-show-secrets", "-json",
+		filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.json"))
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stdout, pred.Contains(`"kind": "modified"`))
+	assert.That(stdout, pred.Contains(`"new": "two"`))
+}
+
+func TestDiffCommandWithIdenticalFiles(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"old.yaml": "name: a\nport: 80\n",
+		"new.yaml": "port: 80\nname: a\n",
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("diff",
+		filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.yaml"))
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stdout, pred.IsEqualTo(""))
+
+	status, _, _ = runCommand("diff", filepath.Join(dir, "old.yaml"))
+	assert.That(status, pred.IsEqualTo(exitUsage))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marcus999/go-config"
+)
+
+// keyEnvVar is the environment variable providing the default encryption
+// key, avoiding keys on the command line
+const keyEnvVar = "GO_CONFIG_KEY"
+
+// cryptFlags holds the command-line flags shared by the encrypt and decrypt
+// commands
+type cryptFlags struct {
+	key   string
+	paths stringList
+	write bool
+}
+
+func (f *cryptFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.key, "key", os.Getenv(keyEnvVar),
+		"base64 encoded AES key, or @file to read it from a file; defaults to $"+keyEnvVar)
+	flags.Var(&f.paths, "path", "key path of a value, e.g. db.password; can be repeated")
+	flags.BoolVar(&f.write, "w", false, "write the result to the file instead of stdout")
+}
+
+// keyProvider returns the AES key provider for the -key flag
+func (f *cryptFlags) keyProvider() (*config.AESKeyProvider, error) {
+	encoded := f.key
+	if encoded == "" {
+		return nil, fmt.Errorf("missing encryption key, use -key or $%v", keyEnvVar)
+	}
+	if strings.HasPrefix(encoded, "@") {
+		data, err := ioutil.ReadFile(encoded[1:])
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key, %v", err)
+	}
+	return config.NewAESKeyProvider(key)
+}
+
+func runEncrypt(args []string, stdout, stderr io.Writer) int {
+	return runCrypt("encrypt", args, stdout, stderr)
+}
+
+func runDecrypt(args []string, stdout, stderr io.Writer) int {
+	return runCrypt("decrypt", args, stdout, stderr)
+}
+
+// runCrypt implements the encrypt and decrypt commands. Values are rewritten
+// in the raw document, which is then written back as YAML or JSON depending
+// on the file extension; comments and key order are not preserved.
+func runCrypt(name string, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var cf cryptFlags
+	cf.register(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config %v [flags] config.yaml\n\n", name)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || name == "encrypt" && len(cf.paths) == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	filename := flags.Arg(0)
+	p, err := cf.keyProvider()
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitUsage
+	}
+
+	doc, err := readRawDocument(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v: %v\n", filename, err)
+		return exitFailure
+	}
+	paths := cf.paths
+	if name == "decrypt" && len(paths) == 0 {
+		paths = encryptedPaths(doc, "")
+	}
+	for _, path := range paths {
+		err := updateValue(doc, path, func(value string) (string, error) {
+			if name == "encrypt" {
+				if strings.HasPrefix(value, config.EncryptedValuePrefix) {
+					return value, nil
+				}
+				return config.EncryptValue(p, []byte(value))
+			}
+			return config.DecryptValue(p, value)
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "go-config: %v: %v: %v\n", filename, path, err)
+			return exitFailure
+		}
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		format = "json"
+	}
+	if !cf.write {
+		err = writeDocument(stdout, doc, format)
+	} else {
+		var b bytes.Buffer
+		if err = writeDocument(&b, doc, format); err == nil {
+			err = ioutil.WriteFile(filename, b.Bytes(), 0666)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// readRawDocument reads a configuration file as is, without the
+// normalization steps applied by config.ReadDocument
+func readRawDocument(filename string) (interface{}, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return decodeYAML(content)
+}
+
+// updateValue replaces the string value found at path in the raw document
+// with the result of f
+func updateValue(doc interface{}, path string, f func(string) (string, error)) error {
+	segments, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	parent, last := doc, segments[len(segments)-1]
+	for _, s := range segments[:len(segments)-1] {
+		if parent = child(parent, s); parent == nil {
+			return fmt.Errorf("not found")
+		}
+	}
+
+	value, ok := child(parent, last).(string)
+	if !ok {
+		return fmt.Errorf("not a string value")
+	}
+	if value, err = f(value); err != nil {
+		return err
+	}
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		p[i] = value
+	}
+	return nil
+}
+
+// splitPath splits a key path like "servers[0].password" into keys and
+// indices
+func splitPath(path string) ([]string, error) {
+	var segments []string
+	for _, part := range strings.Split(strings.Replace(path, "[", ".[", -1), ".") {
+		if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+			part = part[1 : len(part)-1]
+			if _, err := strconv.Atoi(part); err != nil {
+				return nil, fmt.Errorf("invalid index in key path %q", path)
+			}
+		}
+		if part == "" {
+			return nil, fmt.Errorf("invalid key path %q", path)
+		}
+		segments = append(segments, part)
+	}
+	return segments, nil
+}
+
+// child returns the element of a map or list designated by a path segment,
+// or nil if not found
+func child(v interface{}, segment string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v[segment]
+	case []interface{}:
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil
+		}
+		return v[i]
+	}
+	return nil
+}
+
+// encryptedPaths returns the key paths of all the encrypted values of the
+// raw document
+func encryptedPaths(doc interface{}, path string) []string {
+	var paths []string
+	switch v := doc.(type) {
+	case string:
+		if strings.HasPrefix(v, config.EncryptedValuePrefix) {
+			paths = append(paths, path)
+		}
+	case map[string]interface{}:
+		for key, value := range v {
+			p := key
+			if path != "" {
+				p = path + "." + key
+			}
+			paths = append(paths, encryptedPaths(value, p)...)
+		}
+	case []interface{}:
+		for i, value := range v {
+			paths = append(paths, encryptedPaths(value, fmt.Sprintf("%v[%d]", path, i))...)
+		}
+	}
+	return paths
+}
+
+// stringList is a flag.Value collecting the values of a repeated flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
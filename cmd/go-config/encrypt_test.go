@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config encrypt / decrypt
+// ---------------------------------------------------------------------------
+
+var testKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+
+type cryptConfig struct {
+	DB struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"db"`
+	Tokens []string `json:"tokens"`
+}
+
+func TestEncryptCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "db:\n  user: admin\n  password: s3cr3t\ntokens:\n  - t0k3n\n",
+	})
+	defer cleanup()
+	filename := filepath.Join(dir, "config.yaml")
+
+	status, _, stderr := runCommand("encrypt", "-key", testKey, "-w",
+		"-path", "db.password", "-path", "tokens[0]", filename)
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stderr, pred.IsEqualTo(""))
+
+	content, _ := ioutil.ReadFile(filename)
+	assert.That(string(content), pred.Contains("user: admin"))
+	assert.That(string(content), pred.Matches(`password: "?enc:v1:`))
+	assert.That(string(content), pred.Matches(`- "?enc:v1:`))
+
+	key, _ := base64.StdEncoding.DecodeString(testKey)
+	p, _ := config.NewAESKeyProvider(key)
+	icfg, err := config.Load(filename, cryptConfig{}, config.OptKeyProvider(p))
+	assert.That(err, pred.IsNil())
+	cfg := icfg.(*cryptConfig)
+	assert.That(cfg.DB.Password, pred.IsEqualTo("s3cr3t"))
+	assert.That(cfg.Tokens, pred.IsEqualTo([]string{"t0k3n"}))
+
+	status, stdout, _ := runCommand("decrypt", "-key", testKey, filename)
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stdout, pred.Contains("password: s3cr3t\n"))
+	assert.That(stdout, pred.Contains("- t0k3n\n"))
+}
+
+func TestEncryptCommandErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "db:\n  port: 5432\n",
+		"key":         testKey + "\n",
+	})
+	defer cleanup()
+	filename := filepath.Join(dir, "config.yaml")
+
+	status, _, _ := runCommand("encrypt", "-key", testKey, filename)
+	assert.That(status, pred.IsEqualTo(exitUsage))
+
+	status, _, _ = runCommand("encrypt", "-key", "", "-path", "db.port", filename)
+	assert.That(status, pred.IsEqualTo(exitUsage))
+
+	status, _, stderr := runCommand("encrypt", "-key", "@"+filepath.Join(dir, "key"),
+		"-path", "db.port", filename)
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stderr, pred.Contains("db.port: not a string value"))
+
+	status, _, stderr = runCommand("decrypt", "-key", testKey, "-path", "db.password", filename)
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stderr, pred.Contains("db.password: not a string value"))
+}
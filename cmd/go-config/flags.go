@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/marcus999/go-config"
+)
+
+// loaderFlags holds the command-line flags controlling how configuration
+// files are read, mirroring the corresponding loader options
+type loaderFlags struct {
+	profile        string
+	overlays       bool
+	env            string
+	document       int
+	mergeDocuments bool
+}
+
+func (f *loaderFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.profile, "profile", "", "select the named profile of the configuration")
+	flags.BoolVar(&f.overlays, "overlays", false, "merge the overlay files of the configuration")
+	flags.StringVar(&f.env, "env", "", "environment name of the overlay files, with -overlays")
+	flags.IntVar(&f.document, "document", -1, "select a document of a multi-document file by index")
+	flags.BoolVar(&f.mergeDocuments, "merge-documents", false, "merge all the documents of a multi-document file")
+}
+
+func (f *loaderFlags) options() []config.Option {
+	var opts []config.Option
+	if f.profile != "" {
+		opts = append(opts, config.OptProfile(f.profile))
+	}
+	if f.overlays {
+		opts = append(opts, config.OptOverlays(f.env))
+	}
+	if f.document >= 0 {
+		opts = append(opts, config.OptDocumentIndex(f.document))
+	}
+	if f.mergeDocuments {
+		opts = append(opts, config.OptMergeDocuments())
+	}
+	return opts
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// documentFormat reads and writes raw documents, as returned by
+// readRawDocument, in a given serialization format
+type documentFormat struct {
+	extensions []string
+	decode     func(content []byte) (interface{}, error)
+	encode     func(w io.Writer, doc interface{}) error
+}
+
+// formats lists the serialization formats understood by the commands, by
+// name. YAML being a superset of JSON, JSON content is decoded as YAML.
+var formats = map[string]documentFormat{
+	"json": {[]string{".json"}, decodeYAML, encodeJSON},
+	"toml": {[]string{".toml"}, decodeTOML, encodeTOML},
+	"yaml": {[]string{".yaml", ".yml"}, decodeYAML, encodeYAML},
+}
+
+// formatOf returns the name of the format of filename, based on its
+// extension, defaulting to yaml
+func formatOf(filename string) string {
+	ext := filepath.Ext(filename)
+	for name, f := range formats {
+		for _, e := range f.extensions {
+			if strings.EqualFold(ext, e) {
+				return name
+			}
+		}
+	}
+	return "yaml"
+}
+
+// readDocument reads a raw document from filename, in the format matching
+// its extension
+func readDocument(filename string) (interface{}, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return formats[formatOf(filename)].decode(content)
+}
+
+func decodeYAML(content []byte) (interface{}, error) {
+	j, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func encodeYAML(w io.Writer, doc interface{}) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func encodeJSON(w io.Writer, doc interface{}) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	return e.Encode(doc)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The init command writes a commented configuration file listing all the
+// fields of a configuration type with their default values. Like the schema
+// command, it runs a generated program to load the type.
+
+func runInit(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var p schemaProgram
+	p.Template = true
+	flags.StringVar(&p.Defaults, "defaults", "", "package variable holding the default configuration")
+	output := flags.String("o", "", "write the configuration to a file instead of stdout")
+	force := flags.Bool("f", false, "overwrite the output file if it exists")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config init [flags] package.Type\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	pkg, typeName, err := parseTypeRef(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitUsage
+	}
+	p.TypeName = typeName
+	if err := p.check(); err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitUsage
+	}
+
+	if *output != "" && !*force {
+		if _, err := os.Stat(*output); err == nil {
+			fmt.Fprintf(stderr, "go-config: %v already exists, use -f to overwrite\n", *output)
+			return exitFailure
+		}
+	}
+	if p.ImportPath, err = resolveImportPath(pkg); err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	out, err := p.run(stderr)
+	if err == nil {
+		err = writeOutput(*output, out, stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
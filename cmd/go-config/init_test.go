@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config init
+// ---------------------------------------------------------------------------
+
+func TestInitProgramSource(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p := schemaProgram{
+		ImportPath: "example.com/app/settings",
+		TypeName:   "Config",
+		Template:   true,
+	}
+	src, err := p.source()
+	assert.That(err, pred.IsNil())
+	assert.That(string(src), pred.Contains(`config.WriteTemplate(os.Stdout, defaults)`))
+	assert.That(strings.Contains(string(src), `"encoding/json"`), pred.IsEqualTo(false))
+}
+
+func TestInitCommandWithExistingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "name: existing\n",
+	})
+	defer cleanup()
+
+	status, _, stderr := runCommand("init", "-o", filepath.Join(dir, "config.yaml"),
+		"./pkg/settings.Config")
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stderr, pred.Contains("already exists"))
+}
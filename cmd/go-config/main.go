@@ -0,0 +1,84 @@
+/*
+Command go-config provides tooling around configuration files handled by the
+github.com/marcus999/go-config package.
+
+Usage:
+
+	go-config <command> [arguments]
+
+The commands are:
+
+	convert     convert a configuration file between YAML, JSON and TOML
+	decrypt     decrypt encrypted values of a configuration file
+	diff        compare two configuration files
+	encrypt     encrypt values of a configuration file
+	init        generate a commented configuration file from a Go type
+	render      print the effective content of a configuration file
+	schema      generate a JSON Schema or documentation from a Go type
+	validate    check configuration files for errors
+	watch       print the changes of a configuration file as they happen
+*/
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a go-config subcommand. run receives the arguments following
+// the command name and returns the process exit code.
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"convert":  {"convert a configuration file between YAML, JSON and TOML", runConvert},
+	"decrypt":  {"decrypt encrypted values of a configuration file", runDecrypt},
+	"diff":     {"compare two configuration files", runDiff},
+	"encrypt":  {"encrypt values of a configuration file", runEncrypt},
+	"init":     {"generate a commented configuration file from a Go type", runInit},
+	"render":   {"print the effective content of a configuration file", runRender},
+	"schema":   {"generate a JSON Schema or documentation from a Go type", runSchema},
+	"validate": {"check configuration files for errors", runValidate},
+	"watch":    {"print the changes of a configuration file as they happen", runWatch},
+}
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "go-config: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage:\n\n\tgo-config <command> [arguments]\n\nThe commands are:\n\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%-12v%v\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+}
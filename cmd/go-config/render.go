@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/marcus999/go-config"
+)
+
+func runRender(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var lf loaderFlags
+	lf.register(flags)
+	format := flags.String("o", "yaml", "output format, yaml or json")
+	showSecrets := flags.Bool("show-secrets", false, "do not redact secret values")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config render [flags] config.yaml\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	if *format != "yaml" && *format != "json" {
+		fmt.Fprintf(stderr, "go-config: unsupported output format %q\n", *format)
+		return exitUsage
+	}
+
+	doc, err := config.ReadDocument(flags.Arg(0), lf.options()...)
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v: %v\n", flags.Arg(0), err)
+		return exitFailure
+	}
+	if !*showSecrets {
+		doc = config.RedactDocument(doc)
+	}
+
+	if err := writeDocument(stdout, doc, *format); err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeDocument writes a raw document in the given format, yaml or json
+func writeDocument(w io.Writer, doc interface{}, format string) error {
+	return formats[format].encode(w, doc)
+}
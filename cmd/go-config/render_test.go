@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config render
+// ---------------------------------------------------------------------------
+
+const renderConfig = `
+name: base
+port: 80
+password: hunter2
+profiles:
+  prod:
+    name: production
+`
+
+func TestRenderCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml":       renderConfig,
+		"config.local.yaml": "port: 8080\n",
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("render", "-o", "json",
+		"-profile", "prod", "-overlays", filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stdout, pred.IsEqualTo(`{
+  "name": "production",
+  "password": "<redacted>",
+  "port": 8080
+}
+`))
+}
+
+func TestRenderCommandAsYAML(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": renderConfig,
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("render", "-show-secrets",
+		filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stdout, pred.Contains("name: base\n"))
+	assert.That(stdout, pred.Contains("password: hunter2\n"))
+
+	status, _, _ = runCommand("render", "-o", "xml", filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitUsage))
+}
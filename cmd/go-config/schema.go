@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// The schema command cannot load Go types by itself. Instead, it generates
+// a small program importing the target package, and runs it with `go run`
+// from the current directory, so that the package is resolved within the
+// current module. The command is typically used through go:generate:
+//
+//	//go:generate go-config schema -o schema.json ./pkg/settings.Config
+
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var p schemaProgram
+	flags.BoolVar(&p.Docs, "docs", false, "generate Markdown documentation instead of a JSON Schema")
+	flags.BoolVar(&p.Strict, "strict", false, "reject unknown keys, as with strict parsing")
+	flags.StringVar(&p.Defaults, "defaults", "", "package variable holding the default configuration")
+	output := flags.String("o", "", "write the output to a file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config schema [flags] package.Type\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	pkg, typeName, err := parseTypeRef(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitUsage
+	}
+	p.TypeName = typeName
+	if err := p.check(); err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitUsage
+	}
+
+	if p.ImportPath, err = resolveImportPath(pkg); err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	out, err := p.run(stderr)
+	if err == nil {
+		err = writeOutput(*output, out, stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeOutput writes the output of a command to a file, or to stdout if
+// filename is empty
+func writeOutput(filename string, out []byte, stdout io.Writer) error {
+	if filename != "" {
+		return ioutil.WriteFile(filename, out, 0666)
+	}
+	_, err := stdout.Write(out)
+	return err
+}
+
+// parseTypeRef splits a type reference like "./pkg/settings.Config" or
+// "example.com/app/settings.Config" into its package and type name
+func parseTypeRef(ref string) (pkg, typeName string, err error) {
+	i := strings.LastIndex(ref, ".")
+	if i <= strings.LastIndex(ref, "/") || i == len(ref)-1 {
+		return "", "", fmt.Errorf("invalid type %q, expected package.Type", ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// resolveImportPath returns the import path of a package, possibly given as
+// a relative path
+func resolveImportPath(pkg string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve package %v, %v", pkg, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// schemaProgram describes the program generated to produce the JSON Schema,
+// the Markdown documentation or the configuration template of a type
+type schemaProgram struct {
+	ImportPath string
+	TypeName   string
+	Defaults   string
+	Docs       bool
+	Template   bool
+	Strict     bool
+}
+
+var schemaProgramTemplate = template.Must(template.New("main").Parse(`// Code generated by go-config schema. DO NOT EDIT.
+
+package main
+
+import (
+{{- if not (or .Docs .Template)}}
+	"encoding/json"
+{{- end}}
+	"fmt"
+	"os"
+
+	config "github.com/marcus999/go-config"
+	target {{printf "%q" .ImportPath}}
+)
+
+func main() {
+{{- if .Defaults}}
+	defaults := target.{{.Defaults}}
+{{- else}}
+	var defaults target.{{.TypeName}}
+{{- end}}
+	if err := run(&defaults); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(defaults interface{}) error {
+{{- if .Docs}}
+	return config.WriteMarkdown(os.Stdout, defaults)
+{{- else if .Template}}
+	return config.WriteTemplate(os.Stdout, defaults{{if .Strict}}, config.OptStrictParsing(){{end}})
+{{- else}}
+	s, err := config.GenerateSchema(defaults{{if .Strict}}, config.OptStrictParsing(){{end}})
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(s)
+{{- end}}
+}
+`))
+
+// check verifies that the type and variable names pasted into the source of
+// the program are plain identifiers
+func (p *schemaProgram) check() error {
+	if !token.IsIdentifier(p.TypeName) {
+		return fmt.Errorf("invalid type name %q", p.TypeName)
+	}
+	if p.Defaults != "" && !token.IsIdentifier(p.Defaults) {
+		return fmt.Errorf("invalid defaults variable name %q", p.Defaults)
+	}
+	return nil
+}
+
+// source returns the source code of the program
+func (p *schemaProgram) source() ([]byte, error) {
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := schemaProgramTemplate.Execute(&b, p); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// run writes the program into a temporary directory under the current
+// directory, runs it and returns its output
+func (p *schemaProgram) run(stderr io.Writer) ([]byte, error) {
+	src, err := p.source()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir(".", "go-config-schema-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), src, 0666); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run schema generator, %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config schema
+// ---------------------------------------------------------------------------
+
+func TestParseTypeRef(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	pkg, typeName, err := parseTypeRef("./pkg/settings.Config")
+	assert.That(err, pred.IsNil())
+	assert.That(pkg, pred.IsEqualTo("./pkg/settings"))
+	assert.That(typeName, pred.IsEqualTo("Config"))
+
+	pkg, typeName, err = parseTypeRef("example.com/app/settings.Config")
+	assert.That(err, pred.IsNil())
+	assert.That(pkg, pred.IsEqualTo("example.com/app/settings"))
+	assert.That(typeName, pred.IsEqualTo("Config"))
+
+	for _, ref := range []string{"./pkg/settings", "example.com/app", "settings."} {
+		_, _, err = parseTypeRef(ref)
+		assert.That(err, pred.IsNotNil())
+	}
+}
+
+func TestSchemaProgramSource(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p := schemaProgram{
+		ImportPath: "example.com/app/settings",
+		TypeName:   "Config",
+		Strict:     true,
+	}
+	src, err := p.source()
+	assert.That(err, pred.IsNil())
+	assert.That(string(src), pred.Contains(`target "example.com/app/settings"`))
+	assert.That(string(src), pred.Contains(`var defaults target.Config`))
+	assert.That(string(src), pred.Contains(`config.GenerateSchema(defaults, config.OptStrictParsing())`))
+
+	p = schemaProgram{
+		ImportPath: "example.com/app/settings",
+		TypeName:   "Config",
+		Defaults:   "DefaultConfig",
+		Docs:       true,
+	}
+	src, err = p.source()
+	assert.That(err, pred.IsNil())
+	assert.That(string(src), pred.Contains(`defaults := target.DefaultConfig`))
+	assert.That(string(src), pred.Contains(`config.WriteMarkdown(os.Stdout, defaults)`))
+}
+
+func TestSchemaProgramRejectsInvalidNames(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p := schemaProgram{
+		ImportPath: "example.com/app/settings",
+		TypeName:   "Config{}; func init() { panic(0) }; type X",
+	}
+	_, err := p.source()
+	assert.That(err, pred.IsNotNil())
+
+	p = schemaProgram{
+		ImportPath: "example.com/app/settings",
+		TypeName:   "Config",
+		Defaults:   "DefaultConfig; var _ = os.Exit",
+	}
+	_, err = p.source()
+	assert.That(err, pred.IsNotNil())
+}
+
+func TestSchemaCommandUsage(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	status, _, _ := runCommand("schema")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+
+	status, _, _ = runCommand("schema", "./pkg/settings")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+
+	status, _, _ = runCommand("schema", "-defaults", "a.b", "./pkg/settings.Config")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+}
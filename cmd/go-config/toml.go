@@ -0,0 +1,686 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The TOML codec converts raw documents from and to TOML. Its value model
+// matches the one of JSON: date-time values have no JSON counterpart and are
+// rejected when decoding, and null values, which TOML cannot represent, are
+// rejected when encoding, so that a document never silently changes when
+// converted.
+
+// ---------------------------------------------------------------------------
+// Encoder
+// ---------------------------------------------------------------------------
+
+func encodeTOML(w io.Writer, doc interface{}) error {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("toml documents must be a table at the top level")
+	}
+	var b bytes.Buffer
+	if err := writeTOMLTable(&b, m, nil); err != nil {
+		return err
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeTOMLTable writes the key/value pairs of a table, followed by its
+// sub-tables and arrays of tables under their own headers
+func writeTOMLTable(b *bytes.Buffer, m map[string]interface{}, path []string) error {
+	var tables, arrays []string
+	for _, k := range sortedDocumentKeys(m) {
+		switch v := m[k].(type) {
+		case map[string]interface{}:
+			tables = append(tables, k)
+			continue
+		case []interface{}:
+			if isTableArray(v) {
+				arrays = append(arrays, k)
+				continue
+			}
+		}
+		s, err := tomlValue(m[k], strings.Join(append(path[:len(path):len(path)], k), "."))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%v = %v\n", tomlKey(k), s)
+	}
+
+	for _, k := range tables {
+		p := append(path[:len(path):len(path)], k)
+		tomlHeader(b, "[%v]\n", p)
+		if err := writeTOMLTable(b, m[k].(map[string]interface{}), p); err != nil {
+			return err
+		}
+	}
+	for _, k := range arrays {
+		p := append(path[:len(path):len(path)], k)
+		for _, item := range m[k].([]interface{}) {
+			tomlHeader(b, "[[%v]]\n", p)
+			if err := writeTOMLTable(b, item.(map[string]interface{}), p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tomlHeader(b *bytes.Buffer, format string, path []string) {
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	keys := make([]string, len(path))
+	for i, k := range path {
+		keys[i] = tomlKey(k)
+	}
+	fmt.Fprintf(b, format, strings.Join(keys, "."))
+}
+
+// tomlValue returns the inline TOML representation of a value
+func tomlValue(v interface{}, path string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", fmt.Errorf("%v: null values cannot be represented in toml", path)
+
+	case string:
+		return tomlString(v), nil
+
+	case bool:
+		return strconv.FormatBool(v), nil
+
+	case json.Number:
+		s := v.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+				return "", fmt.Errorf("%v: integer %v is out of range", path, s)
+			}
+		}
+		return s, nil
+
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := tomlValue(item, fmt.Sprintf("%v[%d]", path, i))
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}", nil
+		}
+		var items []string
+		for _, k := range sortedDocumentKeys(v) {
+			s, err := tomlValue(v[k], path+"."+k)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(k)+" = "+s)
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("%v: unsupported value of type %T", path, v)
+}
+
+var bareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(k string) string {
+	if bareKeyPattern.MatchString(k) {
+		return k
+	}
+	return tomlString(k)
+}
+
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func isTableArray(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+func sortedDocumentKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ---------------------------------------------------------------------------
+// Decoder
+// ---------------------------------------------------------------------------
+
+func decodeTOML(content []byte) (interface{}, error) {
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("toml documents must be valid UTF-8")
+	}
+	p := &tomlParser{
+		s:       string(content),
+		root:    make(map[string]interface{}),
+		defined: make(map[uintptr]bool),
+		arrays:  make(map[uintptr]bool),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+// tomlParser parses a TOML document into a raw document. Tables defined by a
+// header and arrays defined as arrays of tables are tracked by identity, to
+// reject tables defined twice and appending to static arrays.
+type tomlParser struct {
+	s       string
+	pos     int
+	root    map[string]interface{}
+	defined map[uintptr]bool
+	arrays  map[uintptr]bool
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.s[:p.pos], "\n") + 1
+	return fmt.Errorf("line %d: %v", line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) parse() error {
+	current := p.root
+	for {
+		p.skipBlankLines()
+		if p.pos >= len(p.s) {
+			return nil
+		}
+		var err error
+		if strings.HasPrefix(p.s[p.pos:], "[[") {
+			p.pos += 2
+			current, err = p.parseArrayHeader()
+		} else if p.s[p.pos] == '[' {
+			p.pos++
+			current, err = p.parseTableHeader()
+		} else {
+			err = p.parseKeyValue(current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tomlParser) parseTableHeader() (map[string]interface{}, error) {
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	t, err := p.descend(p.root, keys)
+	if err != nil {
+		return nil, err
+	}
+	id := reflect.ValueOf(t).Pointer()
+	if p.defined[id] {
+		return nil, p.errorf("table %v is defined twice", strings.Join(keys, "."))
+	}
+	p.defined[id] = true
+	return t, nil
+}
+
+func (p *tomlParser) parseArrayHeader() (map[string]interface{}, error) {
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]]"); err != nil {
+		return nil, err
+	}
+	parent, err := p.descend(p.root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	t := make(map[string]interface{})
+	switch v := parent[last].(type) {
+	case nil:
+		items := []interface{}{t}
+		parent[last] = items
+		p.arrays[reflect.ValueOf(items).Pointer()] = true
+	case []interface{}:
+		if !p.arrays[reflect.ValueOf(v).Pointer()] {
+			return nil, p.errorf("cannot append to static array %v", strings.Join(keys, "."))
+		}
+		items := append(v, t)
+		delete(p.arrays, reflect.ValueOf(v).Pointer())
+		p.arrays[reflect.ValueOf(items).Pointer()] = true
+		parent[last] = items
+	default:
+		return nil, p.errorf("key %v is already defined", strings.Join(keys, "."))
+	}
+	return t, nil
+}
+
+// descend returns the table at the given keys under t, creating missing
+// tables along the way. Arrays of tables resolve to their last element.
+func (p *tomlParser) descend(t map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for i, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			child := make(map[string]interface{})
+			t[k] = child
+			t = child
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			if !p.arrays[reflect.ValueOf(v).Pointer()] {
+				return nil, p.errorf("key %v is already defined", strings.Join(keys[:i+1], "."))
+			}
+			t = v[len(v)-1].(map[string]interface{})
+		default:
+			return nil, p.errorf("key %v is already defined", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return t, nil
+}
+
+func (p *tomlParser) parseKeyValue(t map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	p.skipSpaces()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	t, err = p.descend(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := t[last]; exists {
+		return p.errorf("key %v is already defined", strings.Join(keys, "."))
+	}
+	t[last] = value
+	return nil
+}
+
+// parseKey parses a possibly dotted key, made of bare or quoted keys
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("expected a key")
+		}
+		var key string
+		var err error
+		switch p.s[p.pos] {
+		case '"':
+			key, err = p.parseBasicString()
+		case '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for p.pos < len(p.s) && isBareKeyChar(p.s[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorf("expected a key")
+			}
+			key = p.s[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipSpaces()
+		if p.pos >= len(p.s) || p.s[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("expected a value")
+	}
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], `"""`):
+		return p.parseMultilineString(`"""`)
+	case strings.HasPrefix(p.s[p.pos:], "'''"):
+		return p.parseMultilineString("'''")
+	case p.s[p.pos] == '"':
+		return p.parseBasicString()
+	case p.s[p.pos] == '\'':
+		return p.parseLiteralString()
+	case p.s[p.pos] == '[':
+		return p.parseArray()
+	case p.s[p.pos] == '{':
+		return p.parseInlineTable()
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "":
+		return nil, p.errorf("expected a value")
+	}
+	if dateTimePattern.MatchString(token) {
+		return nil, p.errorf("date-time value %v is not supported", token)
+	}
+	return p.parseNumber(token)
+}
+
+var dateTimePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}|\d{2}:\d{2})`)
+
+// parseNumber returns a TOML integer or float as a json.Number
+func (p *tomlParser) parseNumber(token string) (interface{}, error) {
+	s := strings.ReplaceAll(token, "_", "")
+	if len(s) > 2 && s[0] == '0' && strings.ContainsRune("xob", rune(s[1])) {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[s[1]]
+		i, err := strconv.ParseInt(s[2:], base, 64)
+		if err != nil {
+			return nil, p.errorf("invalid value %v", token)
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	if !strings.ContainsAny(s, ".eEin") {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid value %v", token)
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || strings.ContainsAny(s, "in") {
+		// inf and nan are valid TOML, but have no JSON counterpart
+		return nil, p.errorf("invalid value %v", token)
+	}
+	n := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(n, ".e") {
+		n += ".0"
+	}
+	return json.Number(n), nil
+}
+
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.pos++
+	items := []interface{}{}
+	for {
+		p.skipBlankLines()
+		if p.pos < len(p.s) && p.s[p.pos] == ']' {
+			p.pos++
+			return items, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		p.skipBlankLines()
+		if p.pos < len(p.s) && p.s[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.pos++
+	t := make(map[string]interface{})
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == '}' {
+		p.pos++
+		return t, nil
+	}
+	for {
+		if err := p.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos < len(p.s) && p.s[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect("}"); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.s[p.pos:], "'\n")
+	if end == -1 || p.s[p.pos+end] != '\'' {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// parseMultilineString parses a multi-line basic or literal string, delimited
+// by delim
+func (p *tomlParser) parseMultilineString(delim string) (string, error) {
+	p.pos += len(delim)
+	if strings.HasPrefix(p.s[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if strings.HasPrefix(p.s[p.pos:], "\n") {
+		p.pos++
+	}
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		if strings.HasPrefix(p.s[p.pos:], delim) {
+			// Up to two quotes may precede the closing delimiter
+			for i := 0; i < 2 && strings.HasPrefix(p.s[p.pos+1:], delim); i++ {
+				b.WriteByte(delim[0])
+				p.pos++
+			}
+			p.pos += len(delim)
+			return b.String(), nil
+		}
+		c := p.s[p.pos]
+		if c == '\\' && delim == `"""` {
+			rest := strings.TrimLeft(p.s[p.pos+1:], " \t\r")
+			if strings.HasPrefix(rest, "\n") {
+				// A line ending backslash trims all whitespace up to the next
+				// non-whitespace character
+				rest = strings.TrimLeft(rest, " \t\r\n")
+				p.pos = len(p.s) - len(rest)
+				continue
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.s) {
+		return p.errorf("unterminated string")
+	}
+	c := p.s[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.s) {
+			return p.errorf("invalid escape sequence")
+		}
+		r, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.errorf("invalid escape sequence")
+		}
+		b.WriteRune(rune(r))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+// expect consumes s, after optional spaces
+func (p *tomlParser) expect(s string) error {
+	p.skipSpaces()
+	if !strings.HasPrefix(p.s[p.pos:], s) {
+		return p.errorf("expected %q", s)
+	}
+	p.pos += len(s)
+	return nil
+}
+
+// endOfLine consumes optional spaces and comment up to the end of the line
+func (p *tomlParser) endOfLine() error {
+	p.skipSpaces()
+	p.skipComment()
+	if p.pos < len(p.s) && p.s[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.s) && p.s[p.pos] != '\n' {
+		return p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return nil
+}
+
+func (p *tomlParser) skipSpaces() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	if p.pos < len(p.s) && p.s[p.pos] == '#' {
+		for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlankLines skips whitespace, newlines and comments
+func (p *tomlParser) skipBlankLines() {
+	for {
+		p.skipSpaces()
+		p.skipComment()
+		if p.pos < len(p.s) && (p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// TOML codec
+// ---------------------------------------------------------------------------
+
+func TestDecodeTOML(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	doc, err := decodeTOML([]byte(`# comment
+title = "TOML \"example\"" # trailing comment
+path = 'C:\Users'
+count = 1_000
+mask = 0xff
+ratio = 1e3
+whole = 3.0
+enabled = false
+server.host = "localhost"
+list = [
+  1,
+  2, # comment
+]
+point = { x = 1, y = -2 }
+text = """
+first \
+  second"""
+
+[database]
+"max.connections" = 10
+
+[[products]]
+name = "hammer"
+
+[[products]]
+name = "nail"
+`))
+	assert.That(err, pred.IsNil())
+	assert.That(doc, pred.IsEqualTo(map[string]interface{}{
+		"title":   `TOML "example"`,
+		"path":    `C:\Users`,
+		"count":   json.Number("1000"),
+		"mask":    json.Number("255"),
+		"ratio":   json.Number("1000.0"),
+		"whole":   json.Number("3.0"),
+		"enabled": false,
+		"server":  map[string]interface{}{"host": "localhost"},
+		"list":    []interface{}{json.Number("1"), json.Number("2")},
+		"point":   map[string]interface{}{"x": json.Number("1"), "y": json.Number("-2")},
+		"text":    "first second",
+		"database": map[string]interface{}{
+			"max.connections": json.Number("10"),
+		},
+		"products": []interface{}{
+			map[string]interface{}{"name": "hammer"},
+			map[string]interface{}{"name": "nail"},
+		},
+	}))
+}
+
+func TestDecodeTOMLErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	for content, msg := range map[string]string{
+		"a = 1\na = 2\n":           "line 2: key a is already defined",
+		"[a]\n[a]\n":               "line 2: table a is defined twice",
+		"a = [1]\n[[a]]\n":         "line 2: cannot append to static array a",
+		"a = \"open\n":             "line 1: unterminated string",
+		"a = 1 2\n":                `line 1: unexpected '2'`,
+		"a = 1979-05-27\n":         "line 1: date-time value 1979-05-27 is not supported",
+		"a = inf\n":                "line 1: invalid value inf",
+		"a = 99999999999999999999": "line 1: invalid value 99999999999999999999",
+	} {
+		_, err := decodeTOML([]byte(content))
+		assert.That(err, pred.IsNotNil(), "content: %q", content)
+		if err != nil {
+			assert.That(err.Error(), pred.IsEqualTo(msg), "content: %q", content)
+		}
+	}
+}
+
+func TestEncodeTOMLErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	for _, tc := range []struct {
+		doc interface{}
+		msg string
+	}{
+		{[]interface{}{}, "toml documents must be a table at the top level"},
+		{map[string]interface{}{"a": []interface{}{nil}}, "a[0]: null values cannot be represented in toml"},
+		{map[string]interface{}{"a": json.Number("18446744073709551615")}, "a: integer 18446744073709551615 is out of range"},
+	} {
+		err := encodeTOML(nil, tc.doc)
+		assert.That(err, pred.IsNotNil())
+		if err != nil {
+			assert.That(err.Error(), pred.IsEqualTo(tc.msg))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/schema"
+)
+
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	schemaFile := flags.String("schema", "", "JSON Schema the files must conform to")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config validate [-schema schema.json] config.yaml...\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var opts []config.Option
+	if *schemaFile != "" {
+		s, err := readSchema(*schemaFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "go-config: %v\n", err)
+			return exitFailure
+		}
+		opts = append(opts, config.OptSchema(s))
+	}
+
+	status := exitOK
+	for _, filename := range flags.Args() {
+		err := config.Validate(filename, map[string]interface{}{}, opts...)
+		if err == nil {
+			fmt.Fprintf(stdout, "%v: ok\n", filename)
+			continue
+		}
+		status = exitFailure
+		for _, err := range flattenErrors(err) {
+			fmt.Fprintf(stdout, "%v: %v\n", filename, err)
+		}
+	}
+	return status
+}
+
+func readSchema(filename string) (*schema.Schema, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s, err := schema.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return s, nil
+}
+
+// flattenErrors returns the individual errors of a config.MultiError, or err
+// itself
+func flattenErrors(err error) []error {
+	var m *config.MultiError
+	if errors.As(err, &m) {
+		return m.Errors
+	}
+	return []error{err}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// writeFiles writes the given files into a temporary directory and returns
+// the directory along with a cleanup function
+func writeFiles(t *testing.T, files map[string]string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go-config-test-")
+	if err != nil {
+		t.Fatalf("failed to create temp directory, %v", err)
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0666)
+		if err != nil {
+			t.Fatalf("failed to write %v, %v", name, err)
+		}
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestUnknownCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	status, _, stderr := runCommand("frobnicate")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+	assert.That(stderr, pred.Contains("unknown command"))
+}
+
+// ---------------------------------------------------------------------------
+// go-config validate
+// ---------------------------------------------------------------------------
+
+const validateSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string"},
+		"port": {"type": "integer"}
+	}
+}`
+
+func TestValidateCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"schema.json": validateSchema,
+		"config.yaml": "name: server\nport: 80\n",
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("validate",
+		"-schema", filepath.Join(dir, "schema.json"),
+		filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitOK))
+	assert.That(stdout, pred.Contains("config.yaml: ok"))
+}
+
+func TestValidateCommandListsAllErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"schema.json": validateSchema,
+		"config.yaml": "port: eighty\n",
+	})
+	defer cleanup()
+
+	status, stdout, _ := runCommand("validate",
+		"-schema", filepath.Join(dir, "schema.json"),
+		filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitFailure))
+	assert.That(stdout, pred.Contains("name: required field is missing"))
+	assert.That(stdout, pred.Contains("port: expected integer, got string"))
+}
+
+func TestValidateCommandWithInvalidSyntax(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "name: a\n  port: 80\n",
+	})
+	defer cleanup()
+
+	status, _, _ := runCommand("validate", filepath.Join(dir, "config.yaml"))
+	assert.That(status, pred.IsEqualTo(exitFailure))
+
+	status, _, _ = runCommand("validate", filepath.Join(dir, "missing.yaml"))
+	assert.That(status, pred.IsEqualTo(exitFailure))
+
+	status, _, _ = runCommand("validate")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/watch"
+)
+
+// timestampFormat is the format of the timestamps printed by the watch
+// command
+const timestampFormat = "15:04:05.000"
+
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var w watcher
+	w.loaderFlags.register(flags)
+	flags.DurationVar(&w.interval, "interval", 100*time.Millisecond, "debounce interval of file change events")
+	flags.BoolVar(&w.showSecrets, "show-secrets", false, "do not redact secret values")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: go-config watch [flags] config.yaml\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	w.filename = flags.Arg(0)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := w.run(ctx, stdout); err != nil {
+		fmt.Fprintf(stderr, "go-config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// watcher prints the changes of a configuration file as they are applied,
+// until its context is canceled
+type watcher struct {
+	loaderFlags
+	filename    string
+	interval    time.Duration
+	showSecrets bool
+
+	doc    interface{}
+	loaded bool
+}
+
+func (w *watcher) run(ctx context.Context, out io.Writer) error {
+	fw, err := watch.NewDebouncedFileWatcherWithContext(ctx, w.filename, w.interval, 0)
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	w.reload(out, time.Now())
+	for {
+		select {
+		case e := <-fw.Events():
+			fmt.Fprintf(out, "%v %v\n", e.Time.Format(timestampFormat), e)
+			w.reload(out, e.Time)
+		case err := <-fw.Errors():
+			fmt.Fprintf(out, "%v error: %v\n", time.Now().Format(timestampFormat), err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reload reads the configuration file and prints the changes since the last
+// successful read. On error, the previous content is retained, as the loader
+// would do.
+func (w *watcher) reload(out io.Writer, t time.Time) {
+	timestamp := t.Format(timestampFormat)
+	doc, err := config.ReadDocument(w.filename, w.options()...)
+	if err != nil {
+		fmt.Fprintf(out, "%v error: %v\n", timestamp, err)
+		return
+	}
+	if !w.loaded {
+		fmt.Fprintf(out, "%v loaded %v\n", timestamp, w.filename)
+		w.doc, w.loaded = doc, true
+		return
+	}
+
+	changes := config.DiffDocuments(w.doc, doc)
+	w.doc = doc
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "%v no changes\n", timestamp)
+		return
+	}
+	for _, c := range changes {
+		if !w.showSecrets {
+			c = c.Redacted()
+		}
+		fmt.Fprintf(out, "%v %v\n", timestamp, c)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// go-config watch
+// ---------------------------------------------------------------------------
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestWatchCommand(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeFiles(t, map[string]string{
+		"config.yaml": "name: initial\npassword: hunter2\n",
+	})
+	defer cleanup()
+	filename := filepath.Join(dir, "config.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error)
+	go func() {
+		w := watcher{filename: filename, interval: 20 * time.Millisecond}
+		done <- w.run(ctx, &out)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(filename, []byte("name: updated\npassword: hunter3\n"), 0666)
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	assert.That(<-done, pred.IsNil())
+
+	assert.That(out.String(), pred.Matches(`\d\d:\d\d:\d\d\.\d{3} loaded `))
+	assert.That(out.String(), pred.Contains(`~ name: "initial" -> "updated"`))
+	assert.That(out.String(), pred.Contains(`~ password: "<redacted>" -> "<redacted>"`))
+	assert.That(strings.Contains(out.String(), "hunter"), pred.IsEqualTo(false))
+}
+
+func TestWatchCommandUsage(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	status, _, _ := runCommand("watch")
+	assert.That(status, pred.IsEqualTo(exitUsage))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// CommandFlags holds the standard configuration flags of command-line
+// programs: --config selecting the configuration file, --set overriding
+// individual values, and --check-config validating the configuration without
+// running the program.
+//
+// The flags are defined on a standard library flag.FlagSet rather than on a
+// cobra command, to keep the package free of the cobra and pflag
+// dependencies. Programs built on cobra merge them into their command with
+// pflag's AddGoFlagSet, and create the loader in PersistentPreRunE:
+//
+//	var cf config.CommandFlags
+//	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+//	cf.Register(fs, "config.yaml")
+//	rootCmd.PersistentFlags().AddGoFlagSet(fs)
+//	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+//		if cf.CheckConfig {
+//			return cf.Check(defaults)
+//		}
+//		loader, err = cf.NewLoader(defaults)
+//		return err
+//	}
+//
+// Commands then return from RunE without running when cf.CheckConfig is set.
+type CommandFlags struct {
+	// Filename is the configuration file set with --config
+	Filename string
+
+	// Set lists the key=value overrides set with --set
+	Set []string
+
+	// CheckConfig is true if --check-config is set
+	CheckConfig bool
+}
+
+// Register defines the configuration flags on fs, using defaultFilename when
+// --config is not set
+func (f *CommandFlags) Register(fs *flag.FlagSet, defaultFilename string) {
+	f.Filename = defaultFilename
+	fs.StringVar(&f.Filename, "config", defaultFilename, "configuration file")
+	fs.Var((*setFlag)(&f.Set), "set", "override a configuration value, as key=value; can be repeated")
+	fs.BoolVar(&f.CheckConfig, "check-config", false, "check the configuration and exit")
+}
+
+// NewLoader creates a loader for the configuration file selected with
+// --config, applying the --set overrides. Unlike NewLoader, it returns the
+// error that prevented the initial configuration from loading, along with
+// the loader holding the default configuration.
+func (f *CommandFlags) NewLoader(defaults interface{}, opts ...Option) (*Loader, error) {
+	setOpts, err := f.options()
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewLoader(f.Filename, defaults, append(opts, setOpts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return c, c.Status().LastError
+}
+
+// Check validates the configuration file selected with --config along with
+// the --set overrides, as with Validate
+func (f *CommandFlags) Check(defaults interface{}, opts ...Option) error {
+	setOpts, err := f.options()
+	if err != nil {
+		return err
+	}
+	return Validate(f.Filename, defaults, append(opts, setOpts...)...)
+}
+
+func (f *CommandFlags) options() ([]Option, error) {
+	var opts []Option
+	for _, s := range f.Set {
+		i := strings.Index(s, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid --set value %q, expected key=value", s)
+		}
+		opts = append(opts, OptSet(s[:i], s[i+1:]))
+	}
+	return opts, nil
+}
+
+// setFlag is the flag.Value collecting the values of the repeated --set flag
+type setFlag []string
+
+func (f *setFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+func (f *setFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Type returns the type name displayed by pflag in usage messages
+func (f *setFlag) Type() string {
+	return "key=value"
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// Test command flags
+// ---------------------------------------------------------------------------
+
+func TestCommandFlags(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: loaded\nport: 80\n")
+	defer cleanup()
+
+	var cf config.CommandFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cf.Register(fs, "a/b/c.yaml")
+	assert.That(cf.Filename, pred.IsEqualTo("a/b/c.yaml"))
+
+	err := fs.Parse([]string{"--config", filename, "--set", "Port=8080", "--check-config"})
+	assert.That(err, pred.IsNil())
+	assert.That(cf.CheckConfig, pred.IsEqualTo(true))
+	assert.That(cf.Check(testConfigDefaults), pred.IsNil())
+
+	c, err := cf.NewLoader(testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get(), pred.IsEqualTo(&testConfig{Name: "loaded", Port: 8080}))
+}
+
+func TestCommandFlagsMergedIntoPFlag(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: loaded\nport: 80\n")
+	defer cleanup()
+
+	var cf config.CommandFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cf.Register(fs, "a/b/c.yaml")
+
+	// pflag's AddGoFlagSet sets the values of the merged flags directly,
+	// without parsing the original flag set
+	assert.That(fs.Lookup("config").Value.Set(filename), pred.IsNil())
+	assert.That(fs.Lookup("set").Value.Set("Port=8080"), pred.IsNil())
+	assert.That(fs.Lookup("set").Value.Set("Name=overridden"), pred.IsNil())
+	assert.That(fs.Lookup("check-config").Value.Set("true"), pred.IsNil())
+	assert.That(fs.Parsed(), pred.IsEqualTo(false))
+
+	_, ok := fs.Lookup("set").Value.(pflagValue)
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(cf.CheckConfig, pred.IsEqualTo(true))
+	assert.That(cf.Check(testConfigDefaults), pred.IsNil())
+
+	c, err := cf.NewLoader(testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get(), pred.IsEqualTo(&testConfig{Name: "overridden", Port: 8080}))
+}
+
+func TestCommandFlagsWithErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: loaded\nunknown: 1\n")
+	defer cleanup()
+
+	var cf config.CommandFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cf.Register(fs, filename)
+
+	fs.Parse([]string{"--set", "Port"})
+	_, err := cf.NewLoader(testConfigDefaults)
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("expected key=value"))
+
+	cf.Set = []string{"Port=not-a-number"}
+	c, err := cf.NewLoader(testConfigDefaults)
+	assert.That(err, pred.IsNotNil())
+	defer c.Close()
+	assert.That(c.Get(), pred.IsEqualTo(&testConfigDefaults))
+
+	cf.Set = nil
+	assert.That(cf.Check(testConfigDefaults), pred.IsNotNil())
+}
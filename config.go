@@ -1,16 +1,20 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
 	"io/ioutil"
-	"log"
+	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/ghodss/yaml"
-	"github.com/jinzhu/copier"
 	"github.com/marcus999/go-config/pkg/debounce"
+	"github.com/marcus999/go-config/pkg/schema"
 	"github.com/marcus999/go-config/pkg/watch"
 )
 
@@ -19,21 +23,83 @@ type Loader struct {
 	filename      string
 	defaultConfig interface{}
 	config        atomic.Value
-	watcher       *watch.FileWatcher
-
-	reloadHandlers     []func(interface{})
-	errorHandlers      []func(error)
-	validationHandlers []func(interface{}) (interface{}, error)
-	strictParsing      bool
-	keepLastValid      bool
-	debounceInterval   time.Duration
-	debounceMaxDelay   time.Duration
+	source        Source
+	trigger       chan<- struct{}
+	triggerMu     sync.Mutex
+	triggerClosed bool
+	reloadMu      sync.Mutex
+
+	mu             sync.Mutex
+	closed         bool
+	paused         bool
+	pendingReload  bool
+	status         Status
+	loads          uint64
+	loadErrors     uint64
+	changeHandlers handlerSet[changeHandler]
+
+	decodeHooks         []DecodeHook
+	reloadHandlers      handlerSet[func(interface{})]
+	errorHandlers       handlerSet[func(error)]
+	validationHandlers  handlerSet[func(interface{}) (interface{}, error)]
+	warningHandlers     handlerSet[func(Warning)]
+	prepareHandlers     handlerSet[PrepareFunc]
+	strictParsing       bool
+	caseInsensitiveKeys bool
+	keyNaming           KeyNaming
+	profile             string
+	profilesEnabled     bool
+	overlayEnv          string
+	documentSelector    documentSelector
+	sourceSelects       bool
+	overlaysEnabled     bool
+	unknownFieldHandler func(path string)
+	keepLastValid       bool
+	createIfMissing     bool
+	createPerm          os.FileMode
+	readRetries         int
+	readBackoff         time.Duration
+	errorLimiter        *errorLimiter
+	panicHandler        func(recovered interface{})
+	dispatcher          *dispatcher
+	cloneFunc           func(interface{}) interface{}
+	freezeMode          FreezeMode
+	configFingerprint   []byte
+	keyProvider         KeyProvider
+	dotEnvFile          string
+	pollInterval        time.Duration
+	migrations          []migration
+	schema              *schema.Schema
+	debounceInterval    time.Duration
+	debounceMaxDelay    time.Duration
+	logger              Logger
+	expvarName          string
+	flagSet             *flag.FlagSet
+	setValues           []setValue
+	secretsDirs         []string
+	redactedPaths       map[string]bool
+	watchOptions        []watch.Option
+	sharedDebouncer     *sharedDebouncer
 }
 
 // Option is the base tupe for configuration options
 type Option func(*Loader)
 
+// Logger is the interface used by the loader to report internal events. It is
+// satisfied by the standard library *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...interface{}) {}
+
 const (
+	// StdinFilename is the filename used to load the configuration from the
+	// standard input
+	StdinFilename = "-"
+
 	// DefaultDebounceInterval defines the default debounce interval of 100ms
 	DefaultDebounceInterval = 1000 * time.Millisecond
 
@@ -49,7 +115,7 @@ const (
 // reloaded
 func ReloadHandler(f func(interface{})) Option {
 	return func(c *Loader) {
-		c.reloadHandlers = append(c.reloadHandlers, f)
+		c.reloadHandlers.add(f)
 	}
 }
 
@@ -57,7 +123,7 @@ func ReloadHandler(f func(interface{})) Option {
 // a background opration, e.g. while reloading the configuration file
 func ErrorHandler(f func(err error)) Option {
 	return func(c *Loader) {
-		c.errorHandlers = append(c.errorHandlers, f)
+		c.errorHandlers.add(f)
 	}
 }
 
@@ -67,7 +133,7 @@ func ErrorHandler(f func(err error)) Option {
 // an error.
 func ValidationHandler(f func(interface{}) (interface{}, error)) Option {
 	return func(c *Loader) {
-		c.validationHandlers = append(c.validationHandlers, f)
+		c.validationHandlers.add(f)
 	}
 }
 
@@ -80,6 +146,61 @@ func OptStrictParsing() Option {
 	}
 }
 
+// OptWarnUnknownFields attaches a function to be called with the path of each
+// key of the configuration file that doesn't match any field, e.g.
+// "server.prot". Unlike OptStrictParsing, unknown keys do not prevent the
+// configuration from being applied.
+func OptWarnUnknownFields(f func(path string)) Option {
+	return func(c *Loader) {
+		c.unknownFieldHandler = f
+	}
+}
+
+// OptCaseInsensitiveKeys matches keys of the configuration file regardless of
+// their case. Struct fields are always matched case-insensitively as a
+// fallback, like encoding/json does; with this option, keys that differ only
+// by case and resolve to the same field are reported as an error instead of
+// one silently overriding the other, and keys of string-keyed maps replace
+// existing entries that differ only by case, e.g. entries from the defaults.
+func OptCaseInsensitiveKeys() Option {
+	return func(c *Loader) {
+		c.caseInsensitiveKeys = true
+	}
+}
+
+// OptProfile selects the named profile of the configuration. The content of
+// the matching entry of the top-level "profiles" section, e.g.
+// "profiles: {prod: {...}}", is merged over the base configuration, and the
+// "profiles" section itself is never decoded into the config struct.
+func OptProfile(name string) Option {
+	return func(c *Loader) {
+		c.profile = name
+		c.profilesEnabled = true
+	}
+}
+
+// OptProfileFromEnv selects the profile of the configuration like OptProfile,
+// with the profile name read from the given environment variable. If the
+// variable is not set, only the base configuration is used.
+func OptProfileFromEnv(name string) Option {
+	return OptProfile(os.Getenv(name))
+}
+
+// OptOverlays merges optional overlay files over the configuration file, in
+// increasing order of precedence: "config.<env>.yaml" then
+// "config.local.yaml" for a "config.yaml" configuration file. Overlay files
+// are watched like the configuration file and ignored when missing. Maps are
+// merged recursively; any other value replaces the value from the files with
+// lower precedence. If env is empty, only the local overlay is used. Document
+// selection options apply to each file before merging; an overlay with a
+// single document applies as is.
+func OptOverlays(env string) Option {
+	return func(c *Loader) {
+		c.overlayEnv = env
+		c.overlaysEnabled = true
+	}
+}
+
 // OptKeepLatestOnFailure activate an option that will keep the latest valid
 // configuration if the new configuration fails to load. The default behavior
 // is to revert to the default settings. This option is not recommended for
@@ -90,6 +211,16 @@ func OptKeepLatestOnFailure() Option {
 	}
 }
 
+// OptCreateIfMissing writes the default configuration to the configuration
+// file, creating parent directories as needed, if no file exists when the
+// loader is created. The file is then loaded and watched as usual.
+func OptCreateIfMissing(perm os.FileMode) Option {
+	return func(c *Loader) {
+		c.createIfMissing = true
+		c.createPerm = perm
+	}
+}
+
 // OptDebounceInterval set the debounce interval for rapid changes to the
 // configuration file. Default interval is 100ms
 func OptDebounceInterval(v time.Duration) Option {
@@ -107,79 +238,161 @@ func OptDebounceMaxDelay(v time.Duration) Option {
 	}
 }
 
+// OptLogger sets the logger used to report internal events of the loader and
+// its underlying file watcher. By default, or if l is nil, nothing is logged.
+func OptLogger(l Logger) Option {
+	return func(c *Loader) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		c.logger = l
+	}
+}
+
 // ---------------------------------------------------------------------------
 // config loader interface
 // ---------------------------------------------------------------------------
 
-// NewLoader creates a new configuration loader from a filename and a set of defaults
+// NewLoader creates a new configuration loader from a filename and a set of
+// defaults. The special filename "-" reads the configuration once from the
+// standard input.
 func NewLoader(filename string, defaultConfig interface{}, opts ...Option) (*Loader, error) {
+	if filename == StdinFilename {
+		return NewLoaderFromReader(os.Stdin, defaultConfig, opts...)
+	}
 
 	filename, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	w, err := watch.NewFileWatcher(filename)
+	c := newLoader(defaultConfig, opts)
+	c.filename = filename
+
+	if c.createIfMissing {
+		if err := c.createConfigFile(); err != nil {
+			c.handleError(err)
+		}
+	}
+
+	var watched []string
+	if c.dotEnvFile != "" {
+		watched = append(watched, c.dotEnvFile)
+	}
+	watched = append(watched, c.secretFilenames()...)
+	overlays := c.overlayFilenames()
+	c.sourceSelects = len(overlays) > 0
+	src := newFileSource(filename, overlays, watched, c.pollInterval, c.logger, c.watchOptions)
+	src.selector = c.documentSelector
+	c.start(src)
+	return c, nil
+}
+
+// Load reads and decodes the configuration once, going through the same
+// steps as NewLoader but without watching for changes or starting any
+// goroutine. On failure, the default configuration is returned along with the
+// error, and error handlers are not invoked.
+func Load(filename string, defaultConfig interface{}, opts ...Option) (interface{}, error) {
+	c := newLoader(defaultConfig, opts)
+	if filename == StdinFilename {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return c.cloneDefaults(), err
+		}
+		c.source = &bytesSource{data: data}
+	} else {
+		filename, err := filepath.Abs(filename)
+		if err != nil {
+			return c.cloneDefaults(), err
+		}
+		c.filename = filename
+		if c.createIfMissing {
+			if err := c.createConfigFile(); err != nil {
+				return c.cloneDefaults(), err
+			}
+		}
+		c.source = c.newStaticFileSource(filename)
+	}
+
+	content, err := c.readSource()
+	err = c.applyContent(content, err, false)
+	return c.Get(), err
+}
+
+// NewLoaderFromSource creates a new configuration loader reading its content
+// from an arbitrary source
+func NewLoaderFromSource(src Source, defaultConfig interface{}, opts ...Option) (*Loader, error) {
+	c := newLoader(defaultConfig, opts)
+	c.start(src)
+	return c, nil
+}
+
+// NewLoaderFromBytes creates a new configuration loader from in-memory
+// content. The configuration can be changed later with Update.
+func NewLoaderFromBytes(data []byte, defaultConfig interface{}, opts ...Option) (*Loader, error) {
+	return NewLoaderFromSource(&bytesSource{data: data}, defaultConfig, opts...)
+}
+
+// NewLoaderFromReader creates a new configuration loader from content read
+// once from r, e.g. from the standard input. The configuration can be changed
+// later with Update.
+func NewLoaderFromReader(r io.Reader, defaultConfig interface{}, opts ...Option) (*Loader, error) {
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
+	return NewLoaderFromBytes(data, defaultConfig, opts...)
+}
 
+func newLoader(defaultConfig interface{}, opts []Option) *Loader {
 	c := &Loader{
-		filename:         filename,
 		defaultConfig:    normalizeToSinglePtr(defaultConfig),
-		watcher:          w,
 		debounceInterval: DefaultDebounceInterval,
 		debounceMaxDelay: DefaultDebounceInterval,
+		logger:           nopLogger{},
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	return c
+}
 
-	cfg := cloneStruct(c.defaultConfig)
-	err = c.loadConfigFile(filename, cfg)
-	if err != nil {
-		c.handleError(err)
+// overlayFilenames returns the overlay files of the configuration file, if
+// enabled
+func (c *Loader) overlayFilenames() []string {
+	if !c.overlaysEnabled {
+		return nil
 	}
+	return overlayFilenames(c.filename, c.overlayEnv)
+}
 
-	c.applyValidations(cfg)
-	c.config.Store(cfg)
+// newStaticFileSource returns an unwatched source reading the configuration
+// file and its overlays, if enabled
+func (c *Loader) newStaticFileSource(filename string) *fileSource {
+	overlays := c.overlayFilenames()
+	c.sourceSelects = len(overlays) > 0
+	return &fileSource{filename: filename, overlays: overlays, selector: c.documentSelector}
+}
 
-	if c.debounceInterval != 0 {
-		in, out := debounce.New(c.debounceInterval, c.debounceMaxDelay)
-		go func() {
-			for {
-				e, ok := <-c.watcher.UpdateChannel()
-				if !ok {
-					return
-				}
-				log.Printf("watcher event: %v", e)
-				in <- debounce.Event
-			}
-		}()
-		go func() {
-			for {
-				_, ok := <-out
-				if !ok {
-					return
-				}
-				log.Printf("debounce event\n")
-				c.reloadConfig()
-			}
-		}()
-	} else {
-		go func() {
-			for {
-				_, ok := <-c.watcher.UpdateChannel()
-				if !ok {
-					return
-				}
-				c.reloadConfig()
-			}
-		}()
+// start loads the initial configuration from the source, and starts
+// processing the change notifications of the source
+func (c *Loader) start(src Source) {
+	c.source = src
+	c.publishExpvar()
+
+	content, err := c.readSource()
+	if err := c.applyContent(content, err, false); err != nil {
+		c.handleError(err)
 	}
 
-	return c, nil
+	in, out := c.newReloadPipeline()
+	c.trigger = in
+	go c.forwardSourceChanges()
+	if es, ok := src.(ErrorSource); ok {
+		go c.forwardSourceErrors(es.Errors())
+	}
+	go c.processReloads(out)
 }
 
 // Error types:
@@ -189,7 +402,11 @@ func NewLoader(filename string, defaultConfig interface{}, opts ...Option) (*Loa
 
 // Get returns the current version of the configuraiton stored in the loader
 func (c *Loader) Get() interface{} {
-	return c.config.Load()
+	cfg := c.config.Load()
+	if c.freezeMode == FreezeCopy && cfg != nil {
+		return c.cloneConfig(cfg)
+	}
+	return cfg
 }
 
 // GetDefaults returns a copy of the default config
@@ -197,66 +414,398 @@ func (c *Loader) GetDefaults() interface{} {
 	return c.defaultConfig
 }
 
+// OnChange attaches a function to be called after a reload when the value
+// found at the given key path, e.g. "log.level", differs between the previous
+// and the new configuration. Missing values are reported as nil. It returns a
+// function that detaches the handler.
+func (c *Loader) OnChange(path string, f func(old, new interface{})) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.changeHandlers.add(changeHandler{path: path, f: f})
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.changeHandlers.remove(id)
+	}
+}
+
+// Pause suspends the propagation of configuration changes until Resume is
+// called. Changes detected in the meantime are not lost; they are applied
+// once when reloading is resumed.
+func (c *Loader) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = true
+}
+
+// Resume resumes the propagation of configuration changes after a call to
+// Pause, triggering a single debounced reload if the configuration changed
+// while paused.
+func (c *Loader) Resume() {
+	c.mu.Lock()
+	pending := c.paused && c.pendingReload
+	c.paused = false
+	c.pendingReload = false
+	c.mu.Unlock()
+
+	if pending {
+		c.triggerReload()
+	}
+}
+
+// Update applies new content to the loader, through the same decode,
+// validation and notification process as a reload from the source. It
+// returns the error that prevented the content from being applied, if any.
+func (c *Loader) Update(data []byte) error {
+	if c.sourceSelects {
+		doc, err := selectDocument(data, c.documentSelector)
+		if err == nil {
+			data, err = json.Marshal(doc)
+		}
+		if err != nil {
+			return c.applyContent(nil, &ParseError{Err: err}, true)
+		}
+	}
+	return c.applyContent(data, nil, true)
+}
+
+// ErrClosed is returned by Reload once the loader is closed
+var ErrClosed = errors.New("loader is closed")
+
+// Reload reads the configuration source and applies its content immediately,
+// bypassing debouncing, through the same decode, validation and notification
+// process as an automatic reload. Like Update, it applies even while paused.
+// It returns the error that prevented the configuration from being applied,
+// if any.
+func (c *Loader) Reload() error {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	content, err := c.readSource()
+	return c.applyContent(content, err, true)
+}
+
+// Close stops watching the configuration source and releases associated
+// resources. The last loaded configuration remains available.
+func (c *Loader) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	if err := c.source.Close(); err != nil {
+		c.handleError(&WatchError{Err: err})
+	}
+	c.closeTrigger()
+}
+
 // ---------------------------------------------------------------------------
 // config loader implemetation
 // ---------------------------------------------------------------------------
 
-func (c *Loader) loadConfigFile(filename string, cfg interface{}) error {
-	content, err := ioutil.ReadFile(filename)
+// decodeContent decodes content over a copy of the default configuration, and
+// applies the overrides of secret files, `env:"..."` tagged fields and
+// command-line flags. The paths of encrypted values and of fields set from
+// the environment are recorded in redacted.
+func (c *Loader) decodeContent(content []byte, redacted map[string]bool) (interface{}, error) {
+	doc, err := c.parseContent(content, redacted)
 	if err != nil {
-		return err
+		return nil, asParseError(err)
+	}
+	if err := c.checkSchema(doc); err != nil {
+		return nil, err
 	}
 
-	var opts []yaml.JSONOpt
-	if c.strictParsing {
-		opts = append(opts, yaml.DisallowUnknownFields)
+	cfg := c.cloneDefaults()
+	if err := c.newDecoder().decode(doc, cfg); err != nil {
+		return nil, err
+	}
+	if err := c.applySecretFiles(cfg); err != nil {
+		return nil, err
+	}
+	var dotEnv map[string]string
+	if c.dotEnvFile != "" {
+		if dotEnv, err = readDotEnv(c.dotEnvFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.applyEnvOverrides(cfg, dotEnv, redacted); err != nil {
+		return nil, err
+	}
+	if err := c.applyFlagOverrides(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyContent decodes content and makes the result the active
+// configuration. If the content cannot be read or decoded, the default
+// configuration is applied instead, unless OptKeepLatestOnFailure is set and
+// a configuration is already active. When notifying, the configuration is only
+// applied once all prepare handlers succeed.
+func (c *Loader) applyContent(content []byte, err error, notify bool) error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	c.setChecksum(content, err)
+	var cfg interface{}
+	redacted := make(map[string]bool)
+	if err == nil {
+		cfg, err = c.decodeContent(content, redacted)
+	}
+	if err == nil {
+		cfg, err = c.applyValidations(cfg)
 	}
 
-	err = yaml.Unmarshal(content, cfg, opts...)
+	previous := c.config.Load()
+	c.checkMutations(previous)
+	if err != nil {
+		if c.keepLastValid && previous != nil {
+			c.setLoadResult(err, false, false)
+			return err
+		}
+		cfg = c.cloneDefaults()
+		redacted = nil
+	}
+
+	var commits []func()
+	if notify {
+		var perr error
+		if commits, perr = c.prepare(cfg); perr != nil {
+			perr = joinErrors([]error{err, perr})
+			c.setLoadResult(perr, false, false)
+			return perr
+		}
+	}
+
+	if c.freezeMode == FreezeDetect {
+		c.configFingerprint = c.fingerprint(cfg)
+	}
+	c.storeConfig(cfg, redacted)
+	c.setLoadResult(err, true, err != nil)
+	if err == nil && c.errorLimiter != nil {
+		c.errorLimiter.reset()
+	}
+	for _, commit := range commits {
+		if commit != nil {
+			c.invokeHandler(commit)
+		}
+	}
+	if notify {
+		c.notifyReloadHandlers(cfg)
+		c.notifyChangeHandlers(previous, cfg)
+	}
+	return err
+}
+
+// storeConfig makes cfg the active configuration, along with the paths of its
+// values to redact
+func (c *Loader) storeConfig(cfg interface{}, redacted map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redactedPaths = redacted
+	c.config.Store(cfg)
+}
+
+// newReloadPipeline returns the input and output channels of the reload
+// pipeline, debounced unless the debounce interval is set to 0. Loaders
+// created by a Manager share its debouncer when using the same settings.
+func (c *Loader) newReloadPipeline() (chan<- struct{}, <-chan struct{}) {
+	if d := c.sharedDebouncer; d != nil && d.matches(c) {
+		return d.pipeline(c)
+	}
+	if c.debounceInterval != 0 {
+		return debounce.New(c.debounceInterval, c.debounceMaxDelay)
+	}
+	ch := make(chan struct{})
+	return ch, ch
+}
+
+func (c *Loader) forwardSourceChanges() {
+	changes := c.source.Changes()
+	if changes == nil {
+		return
+	}
+	for {
+		_, ok := <-changes
+		if !ok {
+			c.closeTrigger()
+			return
+		}
+		c.triggerReload()
+	}
+}
+
+// forwardSourceErrors passes the errors reported by the source to the error
+// handlers, until the source is closed
+func (c *Loader) forwardSourceErrors(errs <-chan error) {
+	for err := range errs {
+		c.handleError(&WatchError{Err: err})
+	}
+}
+
+// triggerReload feeds an event into the reload pipeline
+func (c *Loader) triggerReload() {
+	c.triggerMu.Lock()
+	defer c.triggerMu.Unlock()
+	if !c.triggerClosed {
+		c.trigger <- debounce.Event
+	}
+}
+
+func (c *Loader) closeTrigger() {
+	c.triggerMu.Lock()
+	defer c.triggerMu.Unlock()
+	if !c.triggerClosed {
+		c.triggerClosed = true
+		close(c.trigger)
+	}
+}
+
+func (c *Loader) processReloads(out <-chan struct{}) {
+	for {
+		_, ok := <-out
+		if !ok {
+			return
+		}
+
+		c.mu.Lock()
+		closed, paused := c.closed, c.paused
+		if paused {
+			c.pendingReload = true
+		}
+		c.mu.Unlock()
+
+		if closed {
+			continue
+		}
+		if paused {
+			c.logger.Printf("reload event while paused")
+			continue
+		}
+		c.logger.Printf("reload event")
+		c.reloadConfig()
+	}
+}
+
+// createConfigFile writes the default configuration to the configuration
+// file if it doesn't exist
+func (c *Loader) createConfigFile() error {
+	if _, err := os.Stat(c.filename); !os.IsNotExist(err) {
+		return nil
+	}
+
+	content, err := c.marshalConfig(c.defaultConfig)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(c.filename), 0755)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	f, err := os.OpenFile(c.filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, c.createPerm)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 func (c *Loader) reloadConfig() {
-	cfg := cloneStruct(c.defaultConfig)
-	err := c.loadConfigFile(c.filename, cfg)
-	if err != nil {
+	content, err := c.readSource()
+	if err := c.applyContent(content, err, true); err != nil {
 		c.handleError(err)
-		if c.keepLastValid {
-			return
+	}
+}
+
+func (c *Loader) notifyReloadHandlers(cfg interface{}) {
+	c.mu.Lock()
+	handlers := c.reloadHandlers.handlers()
+	c.mu.Unlock()
+
+	for _, h := range handlers {
+		handler := h.f
+		if c.dispatcher != nil {
+			c.dispatcher.dispatch(h.id, func() {
+				c.invokeHandler(func() { handler(cfg) })
+			})
 		} else {
-			cfg = cloneStruct(c.defaultConfig)
+			c.invokeHandler(func() { handler(cfg) })
 		}
 	}
+}
 
-	c.applyValidations(cfg)
-	c.config.Store(cfg)
-	c.notifyReloadHandlers(cfg)
+type changeHandler struct {
+	path string
+	f    func(old, new interface{})
 }
 
-func (c *Loader) notifyReloadHandlers(cfg interface{}) {
-	for _, handler := range c.reloadHandlers {
-		handler(cfg)
+func (c *Loader) notifyChangeHandlers(previous, cfg interface{}) {
+	c.mu.Lock()
+	handlers := c.changeHandlers.handlers()
+	c.mu.Unlock()
+
+	for _, e := range handlers {
+		h := e.f
+		old := valueAtPath(previous, h.path, c.keyNaming)
+		new := valueAtPath(cfg, h.path, c.keyNaming)
+		if !reflect.DeepEqual(old, new) {
+			c.invokeHandler(func() { h.f(old, new) })
+		}
 	}
 }
 
 func (c *Loader) handleError(err error) {
-	for _, handler := range c.errorHandlers {
-		handler(err)
+	if c.errorLimiter != nil {
+		if err = c.errorLimiter.filter(err); err == nil {
+			return
+		}
+	}
+	c.mu.Lock()
+	handlers := c.errorHandlers.handlers()
+	c.mu.Unlock()
+
+	for _, h := range handlers {
+		c.invokeHandler(func() { h.f(err) })
 	}
 }
 
+// applyValidations runs all validation handlers in order, and returns the
+// resulting config or the errors reported by all failing handlers
 func (c *Loader) applyValidations(cfg interface{}) (interface{}, error) {
-	for _, validate := range c.validationHandlers {
-		var err error
-		cfg, err = validate(cfg)
+	c.mu.Lock()
+	handlers := c.validationHandlers.handlers()
+	c.mu.Unlock()
+
+	var errs []error
+	for _, h := range handlers {
+		validated, err := c.invokeValidationHandler(h.f, cfg)
 		if err != nil {
-			return nil, err
+			errs = append(errs, &ValidationError{Err: err})
+			continue
+		}
+		if validated != nil {
+			cfg = validated
 		}
 	}
+	if err := joinErrors(errs); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -264,10 +813,9 @@ func (c *Loader) applyValidations(cfg interface{}) (interface{}, error) {
 // configuration struct helpers
 // ---------------------------------------------------------------------------
 
+// cloneStruct returns a deep copy of a pointer to a configuration struct
 func cloneStruct(v interface{}) interface{} {
-	var cfg = reflect.New(reflect.TypeOf(v).Elem()).Interface()
-	copier.Copy(cfg, v)
-	return cfg
+	return deepClone(reflect.ValueOf(v)).Interface()
 }
 
 func normalizeToSinglePtr(v interface{}) interface{} {
@@ -285,7 +833,7 @@ func normalizeToSinglePtr(v interface{}) interface{} {
 
 	if rvp == rv {
 		rvp = reflect.New(baseType)
-		copier.Copy(rvp.Interface(), rv.Interface())
+		rvp.Elem().Set(rv)
 	}
 
 	return rvp.Interface()
@@ -1,8 +1,11 @@
 package config_test
 
 import (
+	"io/ioutil"
 	"reflect"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/marcus999/go-config"
 
@@ -88,3 +91,127 @@ func TestWithDefaultAsPtr(t *testing.T) {
 	assert.That(cfg.Name, pred.IsEqualTo(testConfigDefaults.Name))
 	assert.That(cfg.Port, pred.IsEqualTo(testConfigDefaults.Port))
 }
+
+// ---------------------------------------------------------------------------
+// Test load-once mode
+// ---------------------------------------------------------------------------
+
+func TestLoad(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: loaded\n")
+	defer cleanup()
+
+	goroutines := runtime.NumGoroutine()
+	icfg, err := config.Load(filename, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	assert.That(runtime.NumGoroutine(), pred.IsEqualTo(goroutines))
+
+	cfg := icfg.(*testConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("loaded"))
+	assert.That(cfg.Port, pred.IsEqualTo(testConfigDefaults.Port))
+}
+
+func TestLoadWithMissingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, err := config.Load("a/b/c.yaml", testConfigDefaults)
+	assert.That(err, pred.IsNotNil())
+
+	cfg := icfg.(*testConfig)
+	assert.That(cfg.Name, pred.IsEqualTo(testConfigDefaults.Name))
+	assert.That(cfg.Port, pred.IsEqualTo(testConfigDefaults.Port))
+}
+
+// ---------------------------------------------------------------------------
+// Test config reloading
+// ---------------------------------------------------------------------------
+
+func TestPauseAndResume(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: initial\n")
+	defer cleanup()
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	time.Sleep(100 * time.Millisecond)
+
+	c.Pause()
+	ioutil.WriteFile(filename, []byte("name: first\n"), 0666)
+	time.Sleep(50 * time.Millisecond)
+	ioutil.WriteFile(filename, []byte("name: second\n"), 0666)
+	time.Sleep(100 * time.Millisecond)
+
+	assert.That(reloaded, pred.Length(pred.IsEqualTo(0)))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("initial"))
+
+	c.Resume()
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("second"))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for reload after resume")
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.That(reloaded, pred.Length(pred.IsEqualTo(0)))
+}
+
+func TestReloadWithNilLogger(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: initial\n")
+	defer cleanup()
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.OptLogger(nil),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(filename, []byte("name: updated\n"), 0666)
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("updated"))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for reload")
+	}
+}
+
+func TestClose(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: initial\n")
+	defer cleanup()
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	time.Sleep(100 * time.Millisecond)
+
+	c.Close()
+	c.Pause()
+	c.Resume()
+	ioutil.WriteFile(filename, []byte("name: updated\n"), 0666)
+	time.Sleep(100 * time.Millisecond)
+
+	assert.That(reloaded, pred.Length(pred.IsEqualTo(0)))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("initial"))
+}
@@ -0,0 +1,609 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// DecodeHook is a function called for every value decoded from the
+// configuration file, before it is assigned to its target. It receives the
+// raw decoded value (map[string]interface{}, []interface{}, string,
+// json.Number, bool or nil) and the type of the target, and returns either the
+// value unchanged if the hook doesn't apply, or a replacement value, typically
+// of the target type.
+type DecodeHook func(data interface{}, to reflect.Type) (interface{}, error)
+
+// OptDecodeHook adds a custom decode hook, applied after the built-in hooks.
+func OptDecodeHook(h DecodeHook) Option {
+	return func(c *Loader) {
+		c.decodeHooks = append(c.decodeHooks, h)
+	}
+}
+
+// builtinDecodeHooks lists the hooks applied by all loaders
+var builtinDecodeHooks = []DecodeHook{
+	durationDecodeHook,
+	byteSizeDecodeHook,
+	urlDecodeHook,
+	ipNetDecodeHook,
+	rawSectionDecodeHook,
+}
+
+// ---------------------------------------------------------------------------
+// built-in decode hooks
+// ---------------------------------------------------------------------------
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// durationDecodeHook decodes time.Duration values from strings like "250ms"
+// or "2h45m", and from bare numbers interpreted as seconds.
+func durationDecodeHook(data interface{}, to reflect.Type) (interface{}, error) {
+	if to != durationType {
+		return data, nil
+	}
+
+	switch v := data.(type) {
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q, expected a value like \"250ms\" or \"2h45m\"", v)
+		}
+		return d, nil
+
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i > maxDurationSeconds || i < -maxDurationSeconds {
+				return nil, fmt.Errorf("duration %v seconds is out of range", v)
+			}
+			return time.Duration(i) * time.Second, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %v", v)
+		}
+		if !(math.Abs(f) <= float64(maxDurationSeconds)) {
+			return nil, fmt.Errorf("duration %v seconds is out of range", v)
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	return data, nil
+}
+
+// maxDurationSeconds is the largest number of seconds that fits in a
+// time.Duration, about 292 years
+const maxDurationSeconds = math.MaxInt64 / int64(time.Second)
+
+// ---------------------------------------------------------------------------
+// decoder
+// ---------------------------------------------------------------------------
+
+// decoder assigns a raw decoded configuration document onto a configuration
+// struct. It follows the encoding/json semantics, with the addition of decode
+// hooks, and merges nested structs, maps and pointed-to values field by field
+// over the existing values. Existing maps and pointed-to values are copied
+// before being modified, so that values shared with the defaults are never
+// altered.
+//
+// Unmarshaling the document with encoding/json would not do: it has no way to
+// intercept values before they are assigned, so that a time.Duration would
+// only decode from a number of nanoseconds, and its errors do not report the
+// key path of the offending value.
+type decoder struct {
+	hooks    []DecodeHook
+	strict   bool
+	foldKeys bool
+	naming   KeyNaming
+	unknown  func(path string)
+	warn     func(w Warning)
+}
+
+func (c *Loader) newDecoder() *decoder {
+	hooks := make([]DecodeHook, 0, len(builtinDecodeHooks)+len(c.decodeHooks))
+	hooks = append(hooks, builtinDecodeHooks...)
+	hooks = append(hooks, c.decodeHooks...)
+	return &decoder{
+		hooks:    hooks,
+		strict:   c.strictParsing,
+		foldKeys: c.caseInsensitiveKeys,
+		naming:   c.keyNaming,
+		unknown:  c.unknownFieldHandler,
+		warn:     c.handleWarning,
+	}
+}
+
+// parseDocument converts YAML or JSON content into a raw document
+func parseDocument(content []byte) (interface{}, error) {
+	j, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decode assigns the raw document onto v, which must be a non-nil pointer
+func (d *decoder) decode(doc interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cannot decode into non-pointer %v", rv.Type())
+	}
+	return d.decodeValue(doc, rv.Elem(), "")
+}
+
+func (d *decoder) decodeValue(data interface{}, v reflect.Value, path string) error {
+	for _, hook := range d.hooks {
+		var err error
+		data, err = hook(data, v.Type())
+		if err != nil {
+			return decodeErrorf(path, "%w", err)
+		}
+	}
+
+	if data == nil {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	if dv := reflect.ValueOf(data); dv.Type().AssignableTo(v.Type()) && !isRawType(dv.Type()) {
+		v.Set(dv)
+		return nil
+	}
+
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		if u, ok := v.Addr().Interface().(json.Unmarshaler); ok {
+			j, err := json.Marshal(data)
+			if err != nil {
+				return decodeErrorf(path, "%w", err)
+			}
+			if err := u.UnmarshalJSON(j); err != nil {
+				return decodeErrorf(path, "%w", err)
+			}
+			return nil
+		}
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if s, ok := data.(string); ok {
+				if err := u.UnmarshalText([]byte(s)); err != nil {
+					return decodeErrorf(path, "%w", err)
+				}
+				return nil
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		// Decode into a copy of the existing value, which may be shared with
+		// the defaults
+		p := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			p.Elem().Set(v.Elem())
+		}
+		err := d.decodeValue(data, p.Elem(), path)
+		v.Set(p)
+		return err
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return decodeErrorf(path, "cannot decode into non-empty interface %v", v.Type())
+		}
+		if existing, ok := v.Interface().(map[string]interface{}); ok {
+			data = mergeDocuments(plainValue(existing), data)
+		}
+		v.Set(reflect.ValueOf(plainValue(data)))
+		return nil
+
+	case reflect.Struct:
+		return d.decodeStruct(data, v, path)
+
+	case reflect.Map:
+		return d.decodeMap(data, v, path)
+
+	case reflect.Slice:
+		if s, ok := data.(string); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return decodeErrorf(path, "%w", err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		items, ok := data.([]interface{})
+		if !ok {
+			return typeMismatch(path, data, v.Type())
+		}
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		var errs []error
+		for i, item := range items {
+			if err := d.decodeValue(item, s.Index(i), indexPath(path, i)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		v.Set(s)
+		return joinErrors(errs)
+
+	case reflect.Array:
+		items, ok := data.([]interface{})
+		if !ok {
+			return typeMismatch(path, data, v.Type())
+		}
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			if i < len(items) {
+				if err := d.decodeValue(items[i], v.Index(i), indexPath(path, i)); err != nil {
+					errs = append(errs, err)
+				}
+			} else {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+		}
+		return joinErrors(errs)
+
+	case reflect.String:
+		switch s := data.(type) {
+		case string:
+			v.SetString(s)
+		case json.Number:
+			v.SetString(s.String())
+		case bool:
+			v.SetString(strconv.FormatBool(s))
+		default:
+			return typeMismatch(path, data, v.Type())
+		}
+		return nil
+
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return typeMismatch(path, data, v.Type())
+		}
+		v.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return typeMismatch(path, data, v.Type())
+		}
+		i, err := strconv.ParseInt(n.String(), 10, 64)
+		if err != nil || v.OverflowInt(i) {
+			return decodeErrorf(path, "cannot decode %v into %v", n, v.Type())
+		}
+		v.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := data.(json.Number)
+		if !ok {
+			return typeMismatch(path, data, v.Type())
+		}
+		u, err := strconv.ParseUint(n.String(), 10, 64)
+		if err != nil || v.OverflowUint(u) {
+			return decodeErrorf(path, "cannot decode %v into %v", n, v.Type())
+		}
+		v.SetUint(u)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return typeMismatch(path, data, v.Type())
+		}
+		f, err := strconv.ParseFloat(n.String(), v.Type().Bits())
+		if err != nil || v.OverflowFloat(f) {
+			return decodeErrorf(path, "cannot decode %v into %v", n, v.Type())
+		}
+		v.SetFloat(f)
+		return nil
+	}
+
+	return decodeErrorf(path, "unsupported type %v", v.Type())
+}
+
+func (d *decoder) decodeStruct(data interface{}, v reflect.Value, path string) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return typeMismatch(path, data, v.Type())
+	}
+
+	fields := structFields(v.Type(), d.naming)
+	matched := make(map[*structField]string)
+	var errs []error
+	for _, key := range sortedKeys(m) {
+		value := m[key]
+		f := matchField(fields, key)
+		if f == nil {
+			if d.strict {
+				errs = append(errs, decodeErrorf(keyPath(path, key), "unknown field"))
+			} else if d.unknown != nil {
+				d.unknown(keyPath(path, key))
+			}
+			continue
+		}
+		if d.foldKeys {
+			if other, ok := matched[f]; ok {
+				errs = append(errs, decodeErrorf(keyPath(path, f.name), "ambiguous keys %q and %q", other, key))
+				continue
+			}
+			matched[f] = key
+		}
+		if f.deprecated && d.warn != nil {
+			d.warn(deprecationWarning(keyPath(path, key), f.deprecation))
+		}
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			errs = append(errs, decodeErrorf(keyPath(path, key), "%w", err))
+			continue
+		}
+		if err := d.decodeValue(value, fv, keyPath(path, f.name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
+
+func (d *decoder) decodeMap(data interface{}, v reflect.Value, path string) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return typeMismatch(path, data, v.Type())
+	}
+
+	// Merge into a copy of the existing map, which may be shared with the
+	// defaults
+	t := v.Type()
+	merged := reflect.MakeMapWithSize(t, v.Len()+len(m))
+	iter := v.MapRange()
+	for iter.Next() {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	var errs []error
+	for _, key := range sortedKeys(m) {
+		value := m[key]
+		kv, err := mapKey(key, t.Key())
+		if err != nil {
+			errs = append(errs, decodeErrorf(keyPath(path, key), "%w", err))
+			continue
+		}
+		if d.foldKeys && t.Key().Kind() == reflect.String {
+			deleteFoldedMapKey(merged, kv)
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		if existing := merged.MapIndex(kv); existing.IsValid() {
+			ev.Set(existing)
+		}
+		if err := d.decodeValue(value, ev, keyPath(path, key)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		merged.SetMapIndex(kv, ev)
+	}
+	v.Set(merged)
+	return joinErrors(errs)
+}
+
+// ---------------------------------------------------------------------------
+// decoder helpers
+// ---------------------------------------------------------------------------
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+type structField struct {
+	name        string
+	index       []int
+	deprecated  bool
+	deprecation string
+}
+
+// structFields returns the fields of a struct type visible from the
+// configuration file, including fields promoted from embedded structs. Fields
+// without an explicit name are renamed with naming, if not nil.
+func structFields(t reflect.Type, naming KeyNaming) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		if naming != nil && !hasExplicitKey(f) {
+			name = naming(f.Name)
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && isStructType(f.Type) {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			for _, ef := range structFields(et, naming) {
+				index := append([]int{i}, ef.index...)
+				ef.index = index
+				fields = append(fields, ef)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		deprecation, deprecated := f.Tag.Lookup("deprecated")
+		fields = append(fields, structField{
+			name:        name,
+			index:       []int{i},
+			deprecated:  deprecated,
+			deprecation: deprecation,
+		})
+	}
+	return fields
+}
+
+// matchField returns the field matching key, preferring an exact match over
+// a case-insensitive one.
+func matchField(fields []structField, key string) *structField {
+	var fold *structField
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+		if fold == nil && strings.EqualFold(fields[i].name, key) {
+			fold = &fields[i]
+		}
+	}
+	return fold
+}
+
+// deleteFoldedMapKey removes from the map v any string key equal to key under
+// case-folding, but not identical to it.
+func deleteFoldedMapKey(v reflect.Value, key reflect.Value) {
+	for _, k := range v.MapKeys() {
+		if k.String() != key.String() && strings.EqualFold(k.String(), key.String()) {
+			v.SetMapIndex(k, reflect.Value{})
+		}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldByIndex is equivalent to reflect.Value.FieldByIndex, but allocates nil
+// embedded struct pointers along the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+func mapKey(key string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		kv := reflect.New(t)
+		err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+		return kv.Elem(), err
+	}
+
+	kv := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		kv.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || kv.OverflowInt(i) {
+			return kv, fmt.Errorf("invalid map key %q for %v", key, t)
+		}
+		kv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || kv.OverflowUint(u) {
+			return kv, fmt.Errorf("invalid map key %q for %v", key, t)
+		}
+		kv.SetUint(u)
+	default:
+		return kv, fmt.Errorf("unsupported map key type %v", t)
+	}
+	return kv, nil
+}
+
+// isRawType returns true for the types produced by parseDocument, which are
+// never assigned as-is to a target unless it is an empty interface.
+func isRawType(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(map[string]interface{}{}),
+		reflect.TypeOf([]interface{}{}),
+		reflect.TypeOf(json.Number("")):
+		return true
+	}
+	return false
+}
+
+// plainValue converts json.Number values nested in a raw document into
+// float64, matching what encoding/json stores in an interface{}.
+func plainValue(data interface{}) interface{} {
+	switch v := data.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = plainValue(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, e := range v {
+			s[i] = plainValue(e)
+		}
+		return s
+	}
+	return data
+}
+
+func keyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%v[%d]", path, i)
+}
+
+func decodeErrorf(path, format string, args ...interface{}) error {
+	return &ParseError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+func typeMismatch(path string, data interface{}, t reflect.Type) error {
+	return decodeErrorf(path, "cannot decode %v into %v", rawTypeName(data), t)
+}
+
+func rawTypeName(data interface{}) string {
+	switch data.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return fmt.Sprintf("%T", data)
+}
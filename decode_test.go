@@ -0,0 +1,378 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// writeConfigFile writes content to a new temporary config file and returns
+// its name, along with a function to remove it.
+func writeConfigFile(t *testing.T, content string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go-config-test-")
+	if err != nil {
+		t.Fatalf("failed to create temp directory, %v", err)
+	}
+	filename := filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(filename, []byte(content), 0666)
+	if err != nil {
+		t.Fatalf("failed to write config file, %v", err)
+	}
+	return filename, func() { os.RemoveAll(dir) }
+}
+
+// loadConfig loads content into a copy of defaults and returns the resulting
+// config along with the errors reported by the loader.
+func loadConfig(t *testing.T, content string, defaults interface{}, opts ...config.Option) (interface{}, []error) {
+	t.Helper()
+	filename, cleanup := writeConfigFile(t, content)
+	defer cleanup()
+
+	var errs []error
+	opts = append(opts, config.ErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	c, err := config.NewLoader(filename, defaults, opts...)
+	if err != nil {
+		t.Fatalf("failed to create loader, %v", err)
+	}
+	defer c.Close()
+	return c.Get(), errs
+}
+
+type durationConfig struct {
+	Timeout  time.Duration  `json:"timeout"`
+	Interval *time.Duration `json:"interval"`
+	Delays   []time.Duration
+}
+
+func TestDecodeDurations(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+timeout: 2h45m
+interval: 250ms
+delays:
+  - 1s
+  - 30
+  - 1.5
+`, durationConfig{})
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*durationConfig)
+	assert.That(cfg.Timeout, pred.IsEqualTo(2*time.Hour+45*time.Minute))
+	assert.That(*cfg.Interval, pred.IsEqualTo(250*time.Millisecond))
+	assert.That(cfg.Delays, pred.IsEqualTo([]time.Duration{
+		time.Second, 30 * time.Second, 1500 * time.Millisecond,
+	}))
+}
+
+func TestDecodeInvalidDuration(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+timeout: 5 minutes
+`, durationConfig{Timeout: time.Second})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Matches(`^timeout: invalid duration "5 minutes"`))
+
+	cfg := icfg.(*durationConfig)
+	assert.That(cfg.Timeout, pred.IsEqualTo(time.Second))
+}
+
+type nestedConfig struct {
+	Name   string `json:"name"`
+	Server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	} `json:"server"`
+	Labels map[string]string `json:"labels"`
+	Extra  interface{}       `json:"extra"`
+}
+
+func TestDecodeNestedValues(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := nestedConfig{Name: "default"}
+	defaults.Server.Host = "localhost"
+	icfg, errs := loadConfig(t, `
+server:
+  port: 8080
+labels:
+  team: core
+extra:
+  count: 3
+`, defaults)
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*nestedConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("default"))
+	assert.That(cfg.Server.Host, pred.IsEqualTo("localhost"))
+	assert.That(cfg.Server.Port, pred.IsEqualTo(8080))
+	assert.That(cfg.Labels, pred.IsEqualTo(map[string]string{"team": "core"}))
+	assert.That(cfg.Extra, pred.IsEqualTo(map[string]interface{}{"count": 3.0}))
+}
+
+type deepMergeConfig struct {
+	Limits   map[string]map[string]int `json:"limits"`
+	Backends map[string]struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	} `json:"backends"`
+	TLS *struct {
+		Cert string `json:"cert"`
+		Key  string `json:"key"`
+	} `json:"tls"`
+	Extra interface{} `json:"extra"`
+}
+
+func TestDecodeDeepMergeOverDefaults(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := deepMergeConfig{
+		Limits: map[string]map[string]int{
+			"api": {"rate": 100, "burst": 10},
+		},
+		Extra: map[string]interface{}{"a": 1.0, "b": 2.0},
+	}
+	defaults.Backends = map[string]struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}{"db": {Host: "localhost", Port: 5432}}
+	defaults.TLS = &struct {
+		Cert string `json:"cert"`
+		Key  string `json:"key"`
+	}{Cert: "cert.pem", Key: "key.pem"}
+
+	icfg, errs := loadConfig(t, `
+limits:
+  api:
+    rate: 200
+backends:
+  db:
+    host: db.internal
+tls:
+  key: other.pem
+extra:
+  b: 3
+`, defaults)
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*deepMergeConfig)
+	assert.That(cfg.Limits["api"], pred.IsEqualTo(map[string]int{"rate": 200, "burst": 10}))
+	assert.That(cfg.Backends["db"].Host, pred.IsEqualTo("db.internal"))
+	assert.That(cfg.Backends["db"].Port, pred.IsEqualTo(5432))
+	assert.That(cfg.TLS.Cert, pred.IsEqualTo("cert.pem"))
+	assert.That(cfg.TLS.Key, pred.IsEqualTo("other.pem"))
+	assert.That(cfg.Extra, pred.IsEqualTo(map[string]interface{}{"a": 1.0, "b": 3.0}))
+
+	assert.That(defaults.Limits["api"]["rate"], pred.IsEqualTo(100))
+	assert.That(defaults.Backends["db"].Host, pred.IsEqualTo("localhost"))
+	assert.That(defaults.TLS.Key, pred.IsEqualTo("key.pem"))
+	assert.That(defaults.Extra, pred.IsEqualTo(map[string]interface{}{"a": 1.0, "b": 2.0}))
+}
+
+func TestDecodeTypeMismatch(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, `
+server:
+  port: eighty
+`, nestedConfig{})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.IsEqualTo("server.port: cannot decode string into int"))
+}
+
+func TestDecodeStrictParsing(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, `
+server:
+  prot: 8080
+`, nestedConfig{}, config.OptStrictParsing())
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.IsEqualTo("server.prot: unknown field"))
+}
+
+func TestDecodeMixedCaseKeys(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+NAME: upper
+Server:
+  PORT: 8080
+`, nestedConfig{}, config.OptStrictParsing())
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*nestedConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("upper"))
+	assert.That(cfg.Server.Port, pred.IsEqualTo(8080))
+}
+
+func TestDecodeCaseInsensitiveKeys(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := nestedConfig{Labels: map[string]string{"team": "default", "tier": "web"}}
+	icfg, errs := loadConfig(t, `
+Name: mixed
+labels:
+  Team: core
+`, defaults, config.OptCaseInsensitiveKeys(), config.OptStrictParsing())
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*nestedConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("mixed"))
+	assert.That(cfg.Labels, pred.IsEqualTo(map[string]string{"Team": "core", "tier": "web"}))
+}
+
+func TestDecodeCaseInsensitiveKeysAmbiguous(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, `
+server:
+  port: 80
+  Port: 8080
+`, nestedConfig{}, config.OptCaseInsensitiveKeys())
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.IsEqualTo(`server.port: ambiguous keys "Port" and "port"`))
+}
+
+func TestDecodeCaseInsensitiveKeysUnknown(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, `
+SERVER:
+  PROT: 8080
+`, nestedConfig{}, config.OptCaseInsensitiveKeys(), config.OptStrictParsing())
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.IsEqualTo("server.PROT: unknown field"))
+}
+
+func TestDecodeWarnUnknownFields(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var unknown []string
+	icfg, errs := loadConfig(t, `
+name: test
+nmae: typo
+server:
+  prot: 8080
+  port: 80
+`, nestedConfig{}, config.OptWarnUnknownFields(func(path string) {
+		unknown = append(unknown, path)
+	}))
+	assert.That(errs, pred.IsEmpty())
+	assert.That(unknown, pred.IsEqualTo([]string{"nmae", "server.prot"}))
+
+	cfg := icfg.(*nestedConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("test"))
+	assert.That(cfg.Server.Port, pred.IsEqualTo(80))
+}
+
+func TestDecodeDurationOutOfRange(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+timeout: 9223372036
+delays:
+  - 9223372037
+  - 1.0e+300
+`, durationConfig{Timeout: time.Second})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("Delays[0]: duration 9223372037 seconds is out of range"))
+	assert.That(errs[0].Error(), pred.Contains("Delays[1]: duration 1e+300 seconds is out of range"))
+
+	cfg := icfg.(*durationConfig)
+	assert.That(cfg.Timeout, pred.IsEqualTo(time.Second))
+}
+
+type text struct {
+	Value string
+}
+
+func (t *text) UnmarshalText(b []byte) error {
+	t.Value = strings.ToUpper(string(b))
+	return nil
+}
+
+type embeddedConfig struct {
+	Level string `json:"level"`
+}
+
+type typesConfig struct {
+	embeddedConfig
+	Small  int8           `json:"small"`
+	Count  uint           `json:"count"`
+	Ratio  float32        `json:"ratio"`
+	Label  string         `json:"label"`
+	Text   text           `json:"text"`
+	Data   []byte         `json:"data"`
+	Pair   [2]int         `json:"pair"`
+	Ptr    *int           `json:"ptr"`
+	ByPort map[int]string `json:"by_port"`
+}
+
+func TestDecodeTypes(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	ptr := 3
+	icfg, errs := loadConfig(t, `
+level: debug
+small: -128
+count: 42
+ratio: 0.25
+label: 8080
+text: hello
+data: aGVsbG8=
+pair: [1, 2]
+ptr: null
+by_port:
+  80: http
+`, typesConfig{Ptr: &ptr}, config.OptStrictParsing())
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*typesConfig)
+	assert.That(cfg.Level, pred.IsEqualTo("debug"))
+	assert.That(cfg.Small, pred.IsEqualTo(int8(-128)))
+	assert.That(cfg.Count, pred.IsEqualTo(uint(42)))
+	assert.That(cfg.Ratio, pred.IsEqualTo(float32(0.25)))
+	assert.That(cfg.Label, pred.IsEqualTo("8080"))
+	assert.That(cfg.Text.Value, pred.IsEqualTo("HELLO"))
+	assert.That(string(cfg.Data), pred.IsEqualTo("hello"))
+	assert.That(cfg.Pair, pred.IsEqualTo([2]int{1, 2}))
+	assert.That(cfg.Ptr == nil, pred.IsEqualTo(true))
+	assert.That(cfg.ByPort, pred.IsEqualTo(map[int]string{80: "http"}))
+}
+
+func TestDecodeErrorsReportAllPaths(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+small: 128
+count: -1
+pair: [1, x]
+by_port:
+  http: web
+`, typesConfig{Small: 1})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	for _, msg := range []string{
+		"small: cannot decode 128 into int8",
+		"count: cannot decode -1 into uint",
+		"pair[1]: cannot decode string into int",
+		`by_port.http: invalid map key "http" for int`,
+	} {
+		assert.That(errs[0].Error(), pred.Contains(msg))
+	}
+
+	cfg := icfg.(*typesConfig)
+	assert.That(cfg.Small, pred.IsEqualTo(int8(1)))
+}
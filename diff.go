@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change between two configuration documents
+type ChangeKind int
+
+// The kinds of changes reported by DiffDocuments
+const (
+	Added ChangeKind = iota + 1
+	Removed
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// MarshalText encodes the change kind by name
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Change describes a value that differs between two raw configuration
+// documents. Path is the dotted path of the value, e.g. "server.port" or
+// "servers[0].host"; Old is nil for added values and New is nil for removed
+// values.
+type Change struct {
+	Kind ChangeKind  `json:"kind"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %v: %v", c.Path, displayValue(c.New))
+	case Removed:
+		return fmt.Sprintf("- %v: %v", c.Path, displayValue(c.Old))
+	}
+	return fmt.Sprintf("~ %v: %v -> %v", c.Path, displayValue(c.Old), displayValue(c.New))
+}
+
+// Redacted returns a copy of the change where secret values are replaced by
+// RedactedValue, following the same rules as RedactDocument. Changes to
+// secret values are still reported, without revealing either value.
+func (c Change) Redacted() Change {
+	if isSecretPath(c.Path) {
+		if c.Old != nil {
+			c.Old = RedactedValue
+		}
+		if c.New != nil {
+			c.New = RedactedValue
+		}
+		return c
+	}
+	c.Old = RedactDocument(c.Old)
+	c.New = RedactDocument(c.New)
+	return c
+}
+
+// DiffDocuments compares two raw configuration documents, as returned by
+// ReadDocument, and returns the changes between them ordered by path. Nested
+// objects are compared key by key, while arrays are compared item by item.
+func DiffDocuments(old, new interface{}) []Change {
+	var changes []Change
+	diffValues(old, new, "", &changes)
+	return changes
+}
+
+func diffValues(old, new interface{}, path string, changes *[]Change) {
+	if om, ok := old.(map[string]interface{}); ok {
+		if nm, ok := new.(map[string]interface{}); ok {
+			diffMaps(om, nm, path, changes)
+			return
+		}
+	}
+	if ol, ok := old.([]interface{}); ok {
+		if nl, ok := new.([]interface{}); ok {
+			diffLists(ol, nl, path, changes)
+			return
+		}
+	}
+	if !equalValues(old, new) {
+		*changes = append(*changes, Change{Kind: Modified, Path: path, Old: old, New: new})
+	}
+}
+
+func diffMaps(old, new map[string]interface{}, path string, changes *[]Change) {
+	keys := make(map[string]bool, len(old)+len(new))
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		ov, inOld := old[k]
+		nv, inNew := new[k]
+		p := keyPath(path, k)
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{Kind: Added, Path: p, New: nv})
+		case !inNew:
+			*changes = append(*changes, Change{Kind: Removed, Path: p, Old: ov})
+		default:
+			diffValues(ov, nv, p, changes)
+		}
+	}
+}
+
+func diffLists(old, new []interface{}, path string, changes *[]Change) {
+	for i := 0; i < len(old) || i < len(new); i++ {
+		p := indexPath(path, i)
+		switch {
+		case i >= len(old):
+			*changes = append(*changes, Change{Kind: Added, Path: p, New: new[i]})
+		case i >= len(new):
+			*changes = append(*changes, Change{Kind: Removed, Path: p, Old: old[i]})
+		default:
+			diffValues(old[i], new[i], p, changes)
+		}
+	}
+}
+
+// equalValues compares two raw values, treating numbers as equal when they
+// have the same value regardless of their representation
+func equalValues(a, b interface{}) bool {
+	if an, ok := a.(json.Number); ok {
+		if bn, ok := b.(json.Number); ok {
+			af, aerr := an.Float64()
+			bf, berr := bn.Float64()
+			if aerr == nil && berr == nil {
+				return af == bf
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// displayValue formats a raw value for display, as compact JSON
+func displayValue(v interface{}) string {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
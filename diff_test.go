@@ -0,0 +1,110 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func rawDocument(t *testing.T, s string) interface{} {
+	t.Helper()
+	filename, cleanup := writeConfigFile(t, s)
+	defer cleanup()
+	doc, err := config.ReadDocument(filename)
+	if err != nil {
+		t.Fatalf("failed to read document, %v", err)
+	}
+	return doc
+}
+
+func changeStrings(changes []config.Change) (r []string) {
+	for _, c := range changes {
+		r = append(r, c.String())
+	}
+	return
+}
+
+// ---------------------------------------------------------------------------
+// Test config.ReadDocument()
+// ---------------------------------------------------------------------------
+
+func TestReadDocument(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	doc := rawDocument(t, "name: test\nserver:\n  port: 80\n")
+	assert.That(doc, pred.IsEqualTo(map[string]interface{}{
+		"name": "test",
+		"server": map[string]interface{}{
+			"port": json.Number("80"),
+		},
+	}))
+}
+
+func TestReadDocumentWithMissingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := config.ReadDocument("a/b/c.yaml")
+	assert.That(err, pred.IsNotNil())
+}
+
+// ---------------------------------------------------------------------------
+// Test config.DiffDocuments()
+// ---------------------------------------------------------------------------
+
+func TestDiffDocuments(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	old := rawDocument(t, `
+name: test
+port: 80
+tags: [a, b]
+server:
+  host: localhost
+  timeout: 1.0
+`)
+	new := rawDocument(t, `
+name: test
+port: 8080
+tags: [a]
+server:
+  host: localhost
+  timeout: 1
+  tls: true
+`)
+
+	changes := config.DiffDocuments(old, new)
+	assert.That(changeStrings(changes), pred.IsEqualTo([]string{
+		`~ port: 80 -> 8080`,
+		`+ server.tls: true`,
+		`- tags[1]: "b"`,
+	}))
+	assert.That(changes[0].Kind, pred.IsEqualTo(config.Modified))
+}
+
+func TestDiffIdenticalDocuments(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	doc := rawDocument(t, "name: test\nport: 80\n")
+	assert.That(config.DiffDocuments(doc, doc), pred.IsEmpty())
+}
+
+func TestChangeRedacted(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	old := rawDocument(t, "db:\n  password: old\n  host: a\n")
+	new := rawDocument(t, "db:\n  password: new\n  host: b\napi:\n  token: xyz\n  url: u\n")
+
+	var redacted []config.Change
+	for _, c := range config.DiffDocuments(old, new) {
+		redacted = append(redacted, c.Redacted())
+	}
+	assert.That(changeStrings(redacted), pred.IsEqualTo([]string{
+		`+ api: {"token":"<redacted>","url":"u"}`,
+		`~ db.host: "a" -> "b"`,
+		`~ db.password: "<redacted>" -> "<redacted>"`,
+	}))
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// documentSelector reduces the documents of a multi-document YAML file to
+// the single document to decode
+type documentSelector func(docs []interface{}) (interface{}, error)
+
+// OptDocumentIndex selects the document to decode by its index in a
+// multi-document YAML file, starting at 0. By default, only the first
+// document is decoded.
+func OptDocumentIndex(index int) Option {
+	return func(c *Loader) {
+		c.documentSelector = func(docs []interface{}) (interface{}, error) {
+			if index < 0 || index >= len(docs) {
+				return nil, fmt.Errorf("document index %v out of range, found %v documents", index, len(docs))
+			}
+			return docs[index], nil
+		}
+	}
+}
+
+// OptDocumentMatching selects the first document of a multi-document YAML
+// file with a top-level key set to the given value, e.g. "kind" and
+// "AppConfig".
+func OptDocumentMatching(key, value string) Option {
+	return func(c *Loader) {
+		c.documentSelector = func(docs []interface{}) (interface{}, error) {
+			for _, doc := range docs {
+				m, ok := doc.(map[string]interface{})
+				if ok && fmt.Sprint(m[key]) == value {
+					return doc, nil
+				}
+			}
+			return nil, fmt.Errorf("no document with %v '%v'", key, value)
+		}
+	}
+}
+
+// OptMergeDocuments merges all the documents of a multi-document YAML file in
+// order, with later documents taking precedence. Maps are merged recursively;
+// any other value replaces the value from earlier documents.
+func OptMergeDocuments() Option {
+	return func(c *Loader) {
+		c.documentSelector = func(docs []interface{}) (interface{}, error) {
+			var merged interface{}
+			for _, doc := range docs {
+				merged = mergeDocuments(merged, doc)
+			}
+			return merged, nil
+		}
+	}
+}
+
+// parseContent converts the content of the configuration into the raw
+// document to decode, after document selection, profile resolution and
+// decryption of encrypted values, whose paths are recorded in encrypted if not
+// nil. Content read from a file source with overlays is already reduced to a
+// single document.
+func (c *Loader) parseContent(content []byte, encrypted map[string]bool) (interface{}, error) {
+	var doc interface{}
+	var err error
+	if c.sourceSelects {
+		doc, err = parseDocument(content)
+	} else {
+		doc, err = selectDocument(content, c.documentSelector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.profilesEnabled {
+		doc = applyProfile(doc, c.profile)
+	}
+	if doc, err = applyMigrations(doc, c.migrations); err != nil {
+		return nil, err
+	}
+	return decryptDocument(doc, c.keyProvider, "", encrypted)
+}
+
+// ReadDocument reads a configuration file and returns its raw content, after
+// the normalization steps applied by the loader before decoding: overlays,
+// document selection, profile resolution, migrations and decryption of
+// encrypted values, as enabled by opts. The special filename "-" reads the
+// standard input.
+func ReadDocument(filename string, opts ...Option) (interface{}, error) {
+	c := newLoader(map[string]interface{}{}, opts)
+	if filename == StdinFilename {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, &IOError{Err: err}
+		}
+		c.source = &bytesSource{data: data}
+	} else {
+		filename, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+		c.filename = filename
+		c.source = c.newStaticFileSource(filename)
+	}
+
+	content, err := c.readSource()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.parseContent(content, nil)
+	if err != nil {
+		return nil, asParseError(err)
+	}
+	return doc, nil
+}
+
+// selectDocument converts content into the single raw document selected by
+// selector, or into its first document if selector is nil
+func selectDocument(content []byte, selector documentSelector) (interface{}, error) {
+	if selector == nil {
+		return parseDocument(content)
+	}
+	docs, err := parseDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	return selector(docs)
+}
+
+// selectOverlayDocument converts the content of an overlay file into the raw
+// document to merge. Like the configuration file, multi-document overlays are
+// reduced with selector; a single document applies as is.
+func selectOverlayDocument(content []byte, selector documentSelector) (interface{}, error) {
+	docs, err := parseDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(docs) == 0:
+		return nil, nil
+	case len(docs) == 1 || selector == nil:
+		return docs[0], nil
+	}
+	return selector(docs)
+}
+
+// parseDocuments converts every non-empty document of multi-document YAML
+// content into a raw document
+func parseDocuments(content []byte) ([]interface{}, error) {
+	var docs []interface{}
+	for i, part := range splitDocuments(content) {
+		doc, err := parseDocument(part)
+		if err != nil {
+			return nil, fmt.Errorf("document %v: %v", i, err)
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// splitDocuments splits YAML content on "---" document markers. Markers are
+// only recognized at the start of a line, where YAML reserves them.
+func splitDocuments(content []byte) [][]byte {
+	var parts [][]byte
+	var current bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			parts = append(parts, append([]byte(nil), current.Bytes()...))
+			current.Reset()
+			line = strings.TrimPrefix(line[3:], " ")
+		}
+		if line == "..." {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+	return append(parts, current.Bytes())
+}
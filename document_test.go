@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+const multiDocumentContent = `---
+kind: Service
+name: first
+port: 80
+---
+kind: AppConfig
+name: second
+...
+--- # last document
+port: 8080
+`
+
+func TestMultiDocumentDefaultsToFirst(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, multiDocumentContent, testConfigDefaults)
+	assert.That(errs, pred.IsEmpty())
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("first"))
+	assert.That(cfg.(*testConfig).Port, pred.IsEqualTo(80))
+}
+
+func TestMultiDocumentIndex(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, multiDocumentContent, testConfigDefaults,
+		config.OptDocumentIndex(2),
+	)
+	assert.That(errs, pred.IsEmpty())
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("defaultName"))
+	assert.That(cfg.(*testConfig).Port, pred.IsEqualTo(8080))
+
+	_, errs = loadConfig(t, multiDocumentContent, testConfigDefaults,
+		config.OptDocumentIndex(3),
+	)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+}
+
+func TestMultiDocumentMatching(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, multiDocumentContent, testConfigDefaults,
+		config.OptDocumentMatching("kind", "AppConfig"),
+	)
+	assert.That(errs, pred.IsEmpty())
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("second"))
+	assert.That(cfg.(*testConfig).Port, pred.IsEqualTo(1234))
+
+	_, errs = loadConfig(t, multiDocumentContent, testConfigDefaults,
+		config.OptDocumentMatching("kind", "Deployment"),
+	)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+}
+
+func TestMultiDocumentMerge(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, multiDocumentContent, testConfigDefaults,
+		config.OptMergeDocuments(),
+	)
+	assert.That(errs, pred.IsEmpty())
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("second"))
+	assert.That(cfg.(*testConfig).Port, pred.IsEqualTo(8080))
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OptDotEnv reads variables from a .env file, used to resolve the fields
+// tagged with `env:"NAME"` when the variable is not set in the process
+// environment. The file is optional, and watched like the configuration file
+// when loading from a file.
+func OptDotEnv(filename string) Option {
+	return func(c *Loader) {
+		c.dotEnvFile = filename
+	}
+}
+
+// LoadDotEnv reads variables from a .env file into the process environment.
+// Variables already set in the environment are left unchanged.
+func LoadDotEnv(filename string) error {
+	vars, err := readDotEnv(filename)
+	if err != nil {
+		return err
+	}
+	for k, v := range vars {
+		if _, ok := os.LookupEnv(k); !ok {
+			if err := os.Setenv(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookupEnv returns the value of an environment variable from the process
+// environment, or from the .env variables
+func lookupEnv(name string, dotEnv map[string]string) (string, bool) {
+	if v, ok := os.LookupEnv(name); ok {
+		return v, true
+	}
+	v, ok := dotEnv[name]
+	return v, ok
+}
+
+// readDotEnv reads and parses a .env file, returning no variables if the
+// file does not exist
+func readDotEnv(filename string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &IOError{Err: err}
+	}
+	vars, err := parseDotEnv(content)
+	if err != nil {
+		return nil, &ParseError{Err: fmt.Errorf("%v: %w", filename, err)}
+	}
+	return vars, nil
+}
+
+// parseDotEnv parses lines of the form `KEY=value`, optionally prefixed with
+// `export`. Values can be double quoted with escape sequences, single quoted
+// taken literally, or unquoted with trailing comments removed.
+func parseDotEnv(content []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %v: expected KEY=value", n)
+		}
+		key := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(line[idx+1:])
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			v, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: invalid quoted value", n)
+			}
+			value = v
+		case strings.HasPrefix(value, "'"):
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return nil, fmt.Errorf("line %v: invalid quoted value", n)
+			}
+			value = value[1 : len(value)-1]
+		default:
+			if idx := strings.Index(value, " #"); idx != -1 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
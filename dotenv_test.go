@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+const testDotEnv = `
+# Local development settings
+export TEST_DB_PORT=6543
+TEST_DB_PASSWORD="abc \"def\""
+TEST_DB_TIMEOUT=5s # trailing comment
+TEST_DB_REPLICAS='[db1, db2]'
+`
+
+func TestLoadDotEnv(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, testDotEnv)
+	defer cleanup()
+	defer setTestEnv(t, map[string]string{"TEST_DB_PORT": "1111"})()
+	defer os.Unsetenv("TEST_DB_PASSWORD")
+	defer os.Unsetenv("TEST_DB_TIMEOUT")
+	defer os.Unsetenv("TEST_DB_REPLICAS")
+
+	err := config.LoadDotEnv(filename)
+	assert.That(err, pred.IsNil())
+	assert.That(os.Getenv("TEST_DB_PORT"), pred.IsEqualTo("1111"))
+	assert.That(os.Getenv("TEST_DB_PASSWORD"), pred.IsEqualTo(`abc "def"`))
+	assert.That(os.Getenv("TEST_DB_TIMEOUT"), pred.IsEqualTo("5s"))
+	assert.That(os.Getenv("TEST_DB_REPLICAS"), pred.IsEqualTo("[db1, db2]"))
+}
+
+func TestLoadDotEnvWithInvalidLine(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "TEST_DB_PORT=1\nnot a variable\n")
+	defer cleanup()
+
+	err := config.LoadDotEnv(filename)
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("line 2"))
+}
+
+func TestOptDotEnv(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, testDotEnv)
+	defer cleanup()
+	defer setTestEnv(t, map[string]string{"TEST_DB_PORT": "1111"})()
+
+	icfg, errs := loadConfig(t, `
+db:
+  host: localhost
+  port: 5432
+  password: changeme
+`, envTestConfig{}, config.OptDotEnv(filename))
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*envTestConfig)
+	assert.That(cfg.DB.Port, pred.IsEqualTo(1111))
+	assert.That(cfg.DB.Password, pred.IsEqualTo(`abc "def"`))
+	assert.That(cfg.DB.Timeout, pred.IsEqualTo(5*time.Second))
+	assert.That(cfg.DB.Replicas, pred.IsEqualTo([]string{"db1", "db2"}))
+	_, found := os.LookupEnv("TEST_DB_PASSWORD")
+	assert.That(found, pred.IsEqualTo(false))
+}
+
+func TestOptDotEnvReloadsOnChange(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "db:\n  port: 5432\n")
+	defer cleanup()
+	dotEnvFilename := filepath.Join(filepath.Dir(filename), ".env")
+	ioutil.WriteFile(dotEnvFilename, []byte("TEST_DB_PORT=6543\n"), 0666)
+	defer os.Remove(dotEnvFilename)
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, envTestConfig{},
+		config.OptDotEnv(dotEnvFilename),
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get().(*envTestConfig).DB.Port, pred.IsEqualTo(6543))
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(dotEnvFilename, []byte("TEST_DB_PORT=7654\n"), 0666)
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*envTestConfig).DB.Port, pred.IsEqualTo(7654))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for reload after .env change")
+	}
+}
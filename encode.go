@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// encodeValue converts a configuration value into a raw document, as would
+// be produced by parseDocument, applying the same naming rules as the
+// decoder. It is the reverse of decoder.decode.
+func encodeValue(v reflect.Value, naming KeyNaming) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Interface {
+			return encodeValue(v.Elem(), naming)
+		}
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String(), nil
+	case url.URL:
+		return x.String(), nil
+	case *url.URL:
+		return x.String(), nil
+	case net.IPNet:
+		return x.String(), nil
+	case *net.IPNet:
+		return x.String(), nil
+	case json.Number:
+		return x, nil
+	case json.Marshaler:
+		j, err := x.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		return parseDocument(j)
+	case encoding.TextMarshaler:
+		text, err := x.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		return encodeValue(v.Elem(), naming)
+
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for _, f := range structFields(v.Type(), naming) {
+			fv, ok := fieldByIndexNoAlloc(v, f.index)
+			if !ok {
+				continue
+			}
+			e, err := encodeValue(fv, naming)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", f.name, err)
+			}
+			m[f.name] = e
+		}
+		return m, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			key, err := encodeMapKey(k)
+			if err != nil {
+				return nil, err
+			}
+			e, err := encodeValue(v.MapIndex(k), naming)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", key, err)
+			}
+			m[key] = e
+		}
+		return m, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return nil, nil
+			}
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+			}
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			e, err := encodeValue(v.Index(i), naming)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			s[i] = e
+		}
+		return s, nil
+
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return json.Number(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())), nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %v", v.Type())
+}
+
+func encodeMapKey(k reflect.Value) (string, error) {
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %v", k.Type())
+}
+
+// fieldByIndexNoAlloc is equivalent to reflect.Value.FieldByIndex, but
+// returns false instead of panicking on nil embedded struct pointers.
+func fieldByIndexNoAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// marshalConfig serializes a configuration object into YAML
+func (c *Loader) marshalConfig(cfg interface{}) ([]byte, error) {
+	doc, err := encodeValue(reflect.ValueOf(cfg), c.keyNaming)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+// Marshal returns the active configuration serialized as YAML, following the
+// same naming rules as the decoder, so that it loads back into the same
+// configuration. Secret values are included as is.
+func (c *Loader) Marshal() ([]byte, error) {
+	return c.marshalConfig(c.Get())
+}
+
+// MarshalRedacted returns the active configuration serialized as YAML like
+// Marshal, with secret values redacted as in the response of Handler.
+func (c *Loader) MarshalRedacted() ([]byte, error) {
+	doc, err := c.redactedConfig()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
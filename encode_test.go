@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type createConfig struct {
+	Name     string            `json:"name"`
+	Timeout  time.Duration     `json:"timeout"`
+	Cache    config.ByteSize   `json:"cache"`
+	Endpoint *url.URL          `json:"endpoint"`
+	Listen   config.HostPort   `json:"listen"`
+	Labels   map[string]string `json:"labels"`
+	Server   struct {
+		Port int `json:"port"`
+	} `json:"server"`
+}
+
+func TestCreateIfMissing(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, err := ioutil.TempDir("", "go-config-test-")
+	assert.That(err, pred.IsNil())
+	defer os.RemoveAll(dir)
+
+	endpoint, _ := url.Parse("https://example.com/api")
+	defaults := createConfig{
+		Name:     "default",
+		Timeout:  90 * time.Second,
+		Cache:    64 * config.MiB,
+		Endpoint: endpoint,
+		Listen:   config.HostPort{Port: 8080},
+		Labels:   map[string]string{"team": "core"},
+	}
+	defaults.Server.Port = 443
+
+	filename := filepath.Join(dir, "a", "b", "config.yaml")
+	c, err := config.NewLoader(filename, defaults, config.OptCreateIfMissing(0600))
+	assert.That(err, pred.IsNil())
+	assert.That(c.Status().LastError, pred.IsNil())
+	assert.That(c.Status().UsingDefaults, pred.IsEqualTo(false))
+
+	info, err := os.Stat(filename)
+	assert.That(err, pred.IsNil())
+	assert.That(info.Mode().Perm(), pred.IsEqualTo(os.FileMode(0600)))
+
+	cfg := c.Get().(*createConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("default"))
+	assert.That(cfg.Timeout, pred.IsEqualTo(90*time.Second))
+	assert.That(cfg.Cache, pred.IsEqualTo(64*config.MiB))
+	assert.That(cfg.Endpoint.String(), pred.IsEqualTo("https://example.com/api"))
+	assert.That(cfg.Listen, pred.IsEqualTo(config.HostPort{Port: 8080}))
+	assert.That(cfg.Labels, pred.IsEqualTo(map[string]string{"team": "core"}))
+	assert.That(cfg.Server.Port, pred.IsEqualTo(443))
+}
+
+func TestCreateIfMissingKeepsExistingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: existing\n")
+	defer cleanup()
+
+	c, err := config.NewLoader(filename, createConfig{Name: "default"}, config.OptCreateIfMissing(0600))
+	assert.That(err, pred.IsNil())
+	assert.That(c.Get().(*createConfig).Name, pred.IsEqualTo("existing"))
+
+	content, err := ioutil.ReadFile(filename)
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.IsEqualTo("name: existing\n"))
+}
+
+func TestMarshal(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: loaded\ntimeout: 1m\n")
+	defer cleanup()
+	c, err := config.NewLoader(filename, createConfig{})
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	content, err := c.Marshal()
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.Contains("name: loaded\n"))
+	assert.That(string(content), pred.Contains("timeout: 1m0s\n"))
+
+	icfg, errs := loadConfig(t, string(content), createConfig{})
+	assert.That(errs, pred.IsEmpty())
+	assert.That(icfg, pred.IsEqualTo(c.Get()))
+}
+
+func TestMarshalRedacted(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	type secretConfig struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+		Key      string `json:"key" secret:"api_key"`
+	}
+	filename, cleanup := writeConfigFile(t, "name: loaded\npassword: s3cr3t\nkey: k3y\n")
+	defer cleanup()
+	c, err := config.NewLoader(filename, secretConfig{})
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	content, err := c.MarshalRedacted()
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.Contains("name: loaded\n"))
+	assert.That(string(content), pred.Contains("password: <redacted>\n"))
+	assert.That(string(content), pred.Contains("key: <redacted>\n"))
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EncryptedValuePrefix is the prefix of encrypted string values in
+// configuration files, followed by the base64 encoded ciphertext
+const EncryptedValuePrefix = "enc:v1:"
+
+// KeyProvider decrypts the values of the configuration file written as
+// "enc:v1:<ciphertext>". Implementations can use a local key, or delegate to
+// a KMS or a Vault transit engine.
+type KeyProvider interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// Encrypter is implemented by key providers that can also produce encrypted
+// values
+type Encrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+}
+
+// OptKeyProvider sets the key provider used to decrypt encrypted values of
+// the configuration file before decoding. Without a key provider, encrypted
+// values are reported as errors.
+func OptKeyProvider(p KeyProvider) Option {
+	return func(c *Loader) {
+		c.keyProvider = p
+	}
+}
+
+// EncryptValue encrypts plaintext with e and returns the resulting value to
+// write in a configuration file, e.g. "enc:v1:AAECAw..."
+func EncryptValue(e Encrypter, plaintext []byte) (string, error) {
+	ciphertext, err := e.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return EncryptedValuePrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptValue decrypts a value produced by EncryptValue and returns its
+// plaintext
+func DecryptValue(p KeyProvider, value string) (string, error) {
+	if !strings.HasPrefix(value, EncryptedValuePrefix) {
+		return "", errors.New("value is not encrypted")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(value[len(EncryptedValuePrefix):])
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value, %v", err)
+	}
+	plaintext, err := p.Decrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value, %v", err)
+	}
+	return string(plaintext), nil
+}
+
+// ---------------------------------------------------------------------------
+// AES key provider
+// ---------------------------------------------------------------------------
+
+// AESKeyProvider is a KeyProvider and Encrypter using a local AES key in GCM
+// mode. The ciphertext is prefixed with the random nonce used to seal it.
+type AESKeyProvider struct {
+	aead cipher.AEAD
+}
+
+// NewAESKeyProvider creates a new AESKeyProvider from a 16, 24 or 32 bytes
+// key, selecting AES-128, AES-192 or AES-256
+func NewAESKeyProvider(key []byte) (*AESKeyProvider, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESKeyProvider{aead: aead}, nil
+}
+
+// Encrypt seals plaintext with a random nonce
+func (p *AESKeyProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return p.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt
+func (p *AESKeyProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	n := p.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return p.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
+
+// ---------------------------------------------------------------------------
+// raw document decryption
+// ---------------------------------------------------------------------------
+
+// decryptDocument replaces all encrypted string values of the raw document
+// with their plaintext, recording their paths in encrypted if not nil
+func decryptDocument(doc interface{}, p KeyProvider, path string, encrypted map[string]bool) (interface{}, error) {
+	switch v := doc.(type) {
+	case string:
+		if !strings.HasPrefix(v, EncryptedValuePrefix) {
+			return v, nil
+		}
+		if p == nil {
+			return nil, decodeErrorf(path, "encrypted value without key provider")
+		}
+		plaintext, err := DecryptValue(p, v)
+		if err != nil {
+			return nil, decodeErrorf(path, "%v", err)
+		}
+		if encrypted != nil {
+			encrypted[path] = true
+		}
+		return plaintext, nil
+
+	case map[string]interface{}:
+		var errs []error
+		for _, key := range sortedKeys(v) {
+			value, err := decryptDocument(v[key], p, keyPath(path, key), encrypted)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			v[key] = value
+		}
+		return v, joinErrors(errs)
+
+	case []interface{}:
+		var errs []error
+		for i, item := range v {
+			value, err := decryptDocument(item, p, indexPath(path, i), encrypted)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			v[i] = value
+		}
+		return v, joinErrors(errs)
+	}
+	return doc, nil
+}
@@ -0,0 +1,76 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type secretConfig struct {
+	User     string   `json:"user"`
+	Password string   `json:"password"`
+	Tokens   []string `json:"tokens"`
+}
+
+func TestDecryptEncryptedValues(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p, err := config.NewAESKeyProvider([]byte("0123456789abcdef0123456789abcdef"))
+	assert.That(err, pred.IsNil())
+	password, err := config.EncryptValue(p, []byte("s3cr3t"))
+	assert.That(err, pred.IsNil())
+	assert.That(password, pred.Matches(`^enc:v1:`))
+	token, err := config.EncryptValue(p, []byte("t0k3n"))
+	assert.That(err, pred.IsNil())
+
+	icfg, errs := loadConfig(t, "user: admin\npassword: "+password+"\ntokens:\n  - "+token+"\n",
+		secretConfig{}, config.OptKeyProvider(p))
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*secretConfig)
+	assert.That(cfg.User, pred.IsEqualTo("admin"))
+	assert.That(cfg.Password, pred.IsEqualTo("s3cr3t"))
+	assert.That(cfg.Tokens, pred.IsEqualTo([]string{"t0k3n"}))
+}
+
+func TestEncryptedValueWithoutKeyProvider(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p, _ := config.NewAESKeyProvider([]byte("0123456789abcdef"))
+	password, _ := config.EncryptValue(p, []byte("s3cr3t"))
+
+	_, errs := loadConfig(t, "password: "+password+"\n", secretConfig{})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.IsEqualTo("password: encrypted value without key provider"))
+}
+
+func TestEncryptedValueWithWrongKey(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p, _ := config.NewAESKeyProvider([]byte("0123456789abcdef"))
+	other, _ := config.NewAESKeyProvider([]byte("fedcba9876543210"))
+	password, _ := config.EncryptValue(p, []byte("s3cr3t"))
+
+	_, errs := loadConfig(t, "password: "+password+"\n", secretConfig{}, config.OptKeyProvider(other))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("password: failed to decrypt value"))
+}
+
+func TestDecryptValue(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p, _ := config.NewAESKeyProvider([]byte("0123456789abcdef"))
+	password, _ := config.EncryptValue(p, []byte("s3cr3t"))
+
+	plaintext, err := config.DecryptValue(p, password)
+	assert.That(err, pred.IsNil())
+	assert.That(plaintext, pred.IsEqualTo("s3cr3t"))
+
+	_, err = config.DecryptValue(p, "s3cr3t")
+	assert.That(err, pred.IsNotNil())
+	_, err = config.DecryptValue(p, "enc:v1:!!!")
+	assert.That(err, pred.IsNotNil())
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// applyEnvOverrides overrides the fields of cfg tagged with `env:"NAME"` with
+// the value of the corresponding environment variable, when it is set in the
+// process environment or in the .env variables. Values
+// are decoded like scalar YAML values, e.g. "8080" or "[a, b]", unless the
+// field accepts a plain string. The paths of the overridden fields are
+// recorded in overridden if not nil.
+func (c *Loader) applyEnvOverrides(cfg interface{}, dotEnv map[string]string, overridden map[string]bool) error {
+	d := c.newDecoder()
+	var errs []error
+	walkNamedFields(reflect.ValueOf(cfg), "", c.keyNaming, func(f fieldInfo) {
+		name := f.Field.Tag.Get("env")
+		if name == "" || !f.Value.CanSet() {
+			return
+		}
+		value, ok := lookupEnv(name, dotEnv)
+		if !ok {
+			return
+		}
+		if overridden != nil {
+			overridden[f.Path] = true
+		}
+
+		if err := decodeScalar(d, value, f); err != nil {
+			errs = append(errs, fmt.Errorf("environment variable %v: %w", name, err))
+		}
+	})
+	return joinErrors(errs)
+}
+
+// decodeScalar decodes a string value provided outside of the configuration
+// file into a field, as is if the field accepts a plain string, or as a
+// scalar YAML value otherwise
+func decodeScalar(d *decoder, value string, f fieldInfo) error {
+	err := d.decodeValue(value, f.Value, f.Path)
+	if err != nil {
+		if doc, perr := parseDocument([]byte(value)); perr == nil {
+			err = d.decodeValue(doc, f.Value, f.Path)
+		}
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type envTestConfig struct {
+	Name string `json:"name"`
+	DB   struct {
+		Host     string        `json:"host"`
+		Port     int           `json:"port" env:"TEST_DB_PORT"`
+		Password string        `json:"password" env:"TEST_DB_PASSWORD"`
+		Timeout  time.Duration `json:"timeout" env:"TEST_DB_TIMEOUT"`
+		Replicas []string      `json:"replicas" env:"TEST_DB_REPLICAS"`
+	} `json:"db"`
+}
+
+func setTestEnv(t *testing.T, env map[string]string) func() {
+	t.Helper()
+	for k, v := range env {
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k := range env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestEnvTagOverrides(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defer setTestEnv(t, map[string]string{
+		"TEST_DB_PORT":     "6543",
+		"TEST_DB_PASSWORD": "123456",
+		"TEST_DB_TIMEOUT":  "5s",
+		"TEST_DB_REPLICAS": "[db1, db2]",
+	})()
+
+	icfg, errs := loadConfig(t, `
+db:
+  host: localhost
+  port: 5432
+  password: changeme
+`, envTestConfig{})
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*envTestConfig)
+	assert.That(cfg.DB.Host, pred.IsEqualTo("localhost"))
+	assert.That(cfg.DB.Port, pred.IsEqualTo(6543))
+	assert.That(cfg.DB.Password, pred.IsEqualTo("123456"))
+	assert.That(cfg.DB.Timeout, pred.IsEqualTo(5*time.Second))
+	assert.That(cfg.DB.Replicas, pred.IsEqualTo([]string{"db1", "db2"}))
+}
+
+func TestEnvTagInvalidValue(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defer setTestEnv(t, map[string]string{"TEST_DB_PORT": "not-a-port"})()
+
+	_, errs := loadConfig(t, "db:\n  port: 5432\n", envTestConfig{})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("environment variable TEST_DB_PORT: db.port: "))
+}
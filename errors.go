@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Error types
+// ---------------------------------------------------------------------------
+
+// ParseError reports a configuration that cannot be parsed, or cannot be
+// decoded into the configuration struct. Path is the path of the offending
+// key, e.g. "server.port", or empty if the error applies to the whole
+// content.
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError reports a configuration rejected by a validation handler
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// WatchError reports a failure to watch the configuration source for changes
+type WatchError struct {
+	Err error
+}
+
+func (e *WatchError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *WatchError) Unwrap() error {
+	return e.Err
+}
+
+// IOError reports a failure to read or write the configuration, e.g. a
+// missing or unreadable configuration file
+type IOError struct {
+	Err error
+}
+
+func (e *IOError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *IOError) Unwrap() error {
+	return e.Err
+}
+
+// asParseError wraps err into a ParseError, unless it already is or contains
+// one
+func asParseError(err error) error {
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		return err
+	}
+	return &ParseError{Err: err}
+}
+
+// ---------------------------------------------------------------------------
+// MultiError
+// ---------------------------------------------------------------------------
+
+// MultiError reports several errors at once, e.g. all the problems found
+// while decoding and validating a configuration, so that they can all be
+// fixed in one go. It supports errors.Is and errors.As on any of the
+// underlying errors.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the underlying errors
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether any of the underlying errors matches target
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first underlying error that matches target
+func (e *MultiError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinErrors returns nil if errs is empty, the single error of errs, or a
+// flattened MultiError combining all of them
+func joinErrors(errs []error) error {
+	var flat []error
+	for _, err := range errs {
+		if m, ok := err.(*MultiError); ok {
+			flat = append(flat, m.Errors...)
+		} else if err != nil {
+			flat = append(flat, err)
+		}
+	}
+
+	switch len(flat) {
+	case 0:
+		return nil
+	case 1:
+		return flat[0]
+	}
+	return &MultiError{Errors: flat}
+}
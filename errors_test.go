@@ -0,0 +1,116 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestDecodeErrorsAreAggregated(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, "name:\n  - a\nport: abc\nprot: 80\n", testConfigDefaults,
+		config.OptStrictParsing(),
+	)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+
+	var merr *config.MultiError
+	assert.That(errors.As(errs[0], &merr), pred.IsEqualTo(true))
+	assert.That(merr.Errors, pred.Length(pred.IsEqualTo(3)))
+	assert.That(errs[0].Error(), pred.Contains("Name: "))
+	assert.That(errs[0].Error(), pred.Contains("Port: "))
+	assert.That(errs[0].Error(), pred.Contains("prot: unknown field"))
+}
+
+var errInvalidPort = errors.New("invalid port")
+
+func TestValidationErrorsAreAggregated(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, "name: ''\nport: 0\n", testConfigDefaults,
+		config.ValidationHandler(func(icfg interface{}) (interface{}, error) {
+			if icfg.(*testConfig).Name == "" {
+				return nil, errors.New("name is required")
+			}
+			return icfg, nil
+		}),
+		config.ValidationHandler(func(icfg interface{}) (interface{}, error) {
+			if icfg.(*testConfig).Port == 0 {
+				return nil, errInvalidPort
+			}
+			return icfg, nil
+		}),
+	)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.Is(errs[0], errInvalidPort), pred.IsEqualTo(true))
+	assert.That(errs[0].Error(), pred.IsEqualTo("name is required; invalid port"))
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo(testConfigDefaults.Name))
+}
+
+func TestValidationHandlerCanModifyConfig(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, "name: custom\n", testConfigDefaults,
+		config.ValidationHandler(func(icfg interface{}) (interface{}, error) {
+			c := *icfg.(*testConfig)
+			c.Port++
+			return &c, nil
+		}),
+	)
+	assert.That(errs, pred.IsEmpty())
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("custom"))
+	assert.That(cfg.(*testConfig).Port, pred.IsEqualTo(1235))
+}
+
+func TestTypedErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var ioErr *config.IOError
+	_, errs := loadConfig(t, "", testConfigDefaults, config.OptDotEnv("/"))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.As(errs[0], &ioErr), pred.IsEqualTo(true))
+
+	var parseErr *config.ParseError
+	_, errs = loadConfig(t, "name: a\n  port: 2\n", testConfigDefaults)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.As(errs[0], &parseErr), pred.IsEqualTo(true))
+	assert.That(parseErr.Path, pred.IsEqualTo(""))
+
+	_, errs = loadConfig(t, "port: abc\n", testConfigDefaults)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.As(errs[0], &parseErr), pred.IsEqualTo(true))
+	assert.That(parseErr.Path, pred.IsEqualTo("Port"))
+	assert.That(errs[0].Error(), pred.Matches("^Port: "))
+
+	var validationErr *config.ValidationError
+	_, errs = loadConfig(t, "port: 0\n", testConfigDefaults,
+		config.ValidationHandler(func(icfg interface{}) (interface{}, error) {
+			return nil, errInvalidPort
+		}),
+	)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.As(errs[0], &validationErr), pred.IsEqualTo(true))
+	assert.That(errors.Is(errs[0], errInvalidPort), pred.IsEqualTo(true))
+	assert.That(errors.As(errs[0], &parseErr), pred.IsEqualTo(false))
+}
+
+func TestIOErrorOnMissingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var errs []error
+	c, err := config.NewLoader("a/b/c.yaml", testConfigDefaults,
+		config.ErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	var ioErr *config.IOError
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.As(errs[0], &ioErr), pred.IsEqualTo(true))
+	assert.That(errors.Is(errs[0], os.ErrNotExist), pred.IsEqualTo(true))
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"expvar"
+	"time"
+)
+
+// OptExpvar publishes the state of the loader under name with the expvar
+// package, exposing it on the standard /debug/vars endpoint: the number of
+// load attempts and failures, the generation, the last error and the time of
+// the last reload. Like expvar.Publish, the loader panics if name is already
+// in use.
+func OptExpvar(name string) Option {
+	return func(c *Loader) {
+		c.expvarName = name
+	}
+}
+
+// expvarState is the value published by OptExpvar
+type expvarState struct {
+	Loads         uint64    `json:"loads"`
+	LoadErrors    uint64    `json:"load_errors"`
+	Generation    uint64    `json:"generation"`
+	LastReload    time.Time `json:"last_reload"`
+	LastError     string    `json:"last_error,omitempty"`
+	UsingDefaults bool      `json:"using_defaults"`
+	Paused        bool      `json:"paused"`
+}
+
+func (c *Loader) publishExpvar() {
+	if c.expvarName != "" {
+		expvar.Publish(c.expvarName, expvar.Func(c.expvarValue))
+	}
+}
+
+func (c *Loader) expvarValue() interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v := expvarState{
+		Loads:         c.loads,
+		LoadErrors:    c.loadErrors,
+		Generation:    c.status.Generation,
+		LastReload:    c.status.LastReload,
+		UsingDefaults: c.status.UsingDefaults,
+		Paused:        c.paused,
+	}
+	if c.status.LastError != nil {
+		v.LastError = c.status.LastError.Error()
+	}
+	return v
+}
@@ -0,0 +1,39 @@
+package config_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestExpvar(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: test\n")
+	defer cleanup()
+	// expvar names cannot be reused, even across repeated test runs
+	name := fmt.Sprintf("config_test_%d", time.Now().UnixNano())
+	c, err := config.NewLoader(filename, testConfigDefaults, config.OptExpvar(name))
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	c.Update([]byte("port: not-a-number\n"))
+
+	v := expvar.Get(name)
+	assert.That(v, pred.IsNotNil())
+	var state map[string]interface{}
+	err = json.Unmarshal([]byte(v.String()), &state)
+	assert.That(err, pred.IsNil())
+	assert.That(state["loads"], pred.IsEqualTo(2.0))
+	assert.That(state["load_errors"], pred.IsEqualTo(1.0))
+	assert.That(state["generation"], pred.IsEqualTo(2.0))
+	assert.That(state["using_defaults"], pred.IsEqualTo(true))
+	assert.That(state["last_error"], pred.IsNotNil())
+}
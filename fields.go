@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// configuration struct introspection
+// ---------------------------------------------------------------------------
+
+// fieldInfo describes a single leaf field of a configuration struct, as seen
+// from the configuration file.
+type fieldInfo struct {
+	Path  string
+	Field reflect.StructField
+	Value reflect.Value
+}
+
+// fieldKey returns the key under which a struct field appears in the
+// configuration file, following the encoding/json naming rules. It returns
+// false for fields that are not visible from the configuration file.
+func fieldKey(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if idx := strings.Index(tag, ","); idx != -1 {
+		tag = tag[:idx]
+	}
+	if tag == "" {
+		return f.Name, true
+	}
+	return tag, true
+}
+
+// hasExplicitKey returns true if the field key is set by a struct tag rather
+// than derived from the field name.
+func hasExplicitKey(f reflect.StructField) bool {
+	tag := f.Tag.Get("json")
+	if idx := strings.Index(tag, ","); idx != -1 {
+		tag = tag[:idx]
+	}
+	return tag != "" && tag != "-"
+}
+
+// walkFields calls fn for every leaf field of the struct value v, recursing
+// into nested structs and pointers to structs. Paths are dot separated.
+func walkFields(v reflect.Value, prefix string, fn func(fieldInfo)) {
+	walkNamedFields(v, prefix, nil, fn)
+}
+
+// walkNamedFields is like walkFields, with fields without an explicit key
+// renamed with naming, if not nil, as in the configuration file.
+func walkNamedFields(v reflect.Value, prefix string, naming KeyNaming, fn func(fieldInfo)) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		if naming != nil && !hasExplicitKey(f) {
+			key = naming(f.Name)
+		}
+		fv := v.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" && isStructType(f.Type) {
+			walkNamedFields(fv, prefix, naming, fn)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if isStructType(f.Type) && !isLeafType(f.Type) {
+			walkNamedFields(fv, path, naming, fn)
+			continue
+		}
+		fn(fieldInfo{Path: path, Field: f, Value: fv})
+	}
+}
+
+func isStructType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// isLeafType returns true for struct types that are decoded as a single value
+// rather than as a set of fields, e.g. time.Time.
+func isLeafType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == urlType || t == ipNetType {
+		return true
+	}
+	p := reflect.PtrTo(t)
+	return p.Implements(textUnmarshalerType) || p.Implements(jsonUnmarshalerType)
+}
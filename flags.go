@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// NewFlagSet returns a flag.FlagSet defining one flag for every leaf field of
+// the defaults configuration struct, named after the key path of the field,
+// e.g. "server.port", with its default value and the description found in
+// its `doc:"..."` tag. Values are decoded like environment variable
+// overrides, once the flag set is passed to the loader with OptFlags.
+//
+// Programs based on spf13/pflag or cobra can merge the flags into their own
+// flag set with pflag's AddGoFlagSet; values parsed by pflag are honored the
+// same way. No pflag specific helper is provided, to keep the package free of
+// that dependency.
+func NewFlagSet(name string, defaults interface{}) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	walkFields(reflect.ValueOf(defaults), "", func(f fieldInfo) {
+		t := f.Field.Type
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		fs.Var(&flagValue{
+			def:      flagDefault(f.Value),
+			isBool:   t.Kind() == reflect.Bool,
+			typeName: t.String(),
+		}, f.Path, f.Field.Tag.Get("doc"))
+	})
+	return fs
+}
+
+// OptFlags overrides the fields of the configuration with the flags of fs
+// that were set on the command line. fs is typically created by NewFlagSet;
+// other flags are ignored. Flags take precedence over environment variables,
+// which take precedence over the configuration file.
+func OptFlags(fs *flag.FlagSet) Option {
+	return func(c *Loader) {
+		c.flagSet = fs
+	}
+}
+
+// OptSet overrides the value of the field at the given key path, e.g.
+// "server.port", with the same precedence and decoding rules as flags set
+// through OptFlags. It is the basis of `--set key=value` style command-line
+// options.
+func OptSet(path, value string) Option {
+	return func(c *Loader) {
+		c.setValues = append(c.setValues, setValue{
+			path:   path,
+			value:  value,
+			source: "key " + path,
+		})
+	}
+}
+
+// setValue is a value overriding the field at path. source describes where
+// the value comes from, for error messages.
+type setValue struct {
+	path   string
+	value  string
+	source string
+}
+
+// applyFlagOverrides overrides the fields of cfg with the values of the flags
+// set on the command line, then with the values set through OptSet
+func (c *Loader) applyFlagOverrides(cfg interface{}) error {
+	var values []setValue
+	if c.flagSet != nil {
+		c.flagSet.VisitAll(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok && v.set {
+				values = append(values, setValue{
+					path:   f.Name,
+					value:  v.value,
+					source: "flag -" + f.Name,
+				})
+			}
+		})
+	}
+	values = append(values, c.setValues...)
+	if len(values) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]fieldInfo)
+	walkFields(reflect.ValueOf(cfg), "", func(f fieldInfo) {
+		fields[f.Path] = f
+	})
+	d := c.newDecoder()
+	var errs []error
+	for _, v := range values {
+		f, ok := fields[v.path]
+		if !ok || !f.Value.CanSet() {
+			errs = append(errs, fmt.Errorf("%v: unknown key path", v.source))
+			continue
+		}
+		if err := decodeScalar(d, v.value, f); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", v.source, err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// flagValue is the flag.Value of the flags defined by NewFlagSet. It records
+// whether it was set, as flag sets merged into pflag do not track it.
+type flagValue struct {
+	def      string
+	value    string
+	set      bool
+	isBool   bool
+	typeName string
+}
+
+func (v *flagValue) String() string {
+	if v.set {
+		return v.value
+	}
+	return v.def
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value, v.set = s, true
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be set without a value
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// Type returns the type name displayed by pflag in usage messages
+func (v *flagValue) Type() string {
+	return v.typeName
+}
+
+// flagDefault returns the text representation of a default value, in a form
+// that decodes back into the same value
+func flagDefault(v reflect.Value) string {
+	value, err := encodeValue(v, nil)
+	if err != nil || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
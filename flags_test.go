@@ -0,0 +1,134 @@
+package config_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type flagConfig struct {
+	Name    string        `json:"name" doc:"Name of the service"`
+	Port    int           `json:"port"`
+	Debug   bool          `json:"debug"`
+	Timeout time.Duration `json:"timeout"`
+	Tags    []string      `json:"tags"`
+	Server  struct {
+		Host string `json:"host"`
+	} `json:"server"`
+}
+
+// ---------------------------------------------------------------------------
+// Test command-line flags
+// ---------------------------------------------------------------------------
+
+func TestNewFlagSet(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	fs := config.NewFlagSet("test", flagConfig{
+		Name:    "svc",
+		Timeout: time.Second,
+		Tags:    []string{"a"},
+	})
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	assert.That(names, pred.IsEqualTo([]string{
+		"debug", "name", "port", "server.host", "tags", "timeout",
+	}))
+	assert.That(fs.Lookup("name").DefValue, pred.IsEqualTo("svc"))
+	assert.That(fs.Lookup("name").Usage, pred.IsEqualTo("Name of the service"))
+	assert.That(fs.Lookup("port").DefValue, pred.IsEqualTo("0"))
+	assert.That(fs.Lookup("timeout").DefValue, pred.IsEqualTo("1s"))
+	assert.That(fs.Lookup("tags").DefValue, pred.IsEqualTo(`["a"]`))
+}
+
+func TestFlagsOverrideConfigFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := flagConfig{Name: "svc", Port: 80}
+	fs := config.NewFlagSet("test", defaults)
+	err := fs.Parse([]string{"-port", "8080", "-debug", "-tags", "[a, b]", "-server.host", "example.com"})
+	assert.That(err, pred.IsNil())
+
+	icfg, errs := loadConfig(t, "name: loaded\nport: 443\n", defaults, config.OptFlags(fs))
+	assert.That(errs, pred.IsEmpty())
+	cfg := icfg.(*flagConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("loaded"))
+	assert.That(cfg.Port, pred.IsEqualTo(8080))
+	assert.That(cfg.Debug, pred.IsEqualTo(true))
+	assert.That(cfg.Tags, pred.IsEqualTo([]string{"a", "b"}))
+	assert.That(cfg.Server.Host, pred.IsEqualTo("example.com"))
+}
+
+// pflagValue is the interface of spf13/pflag values. pflag's AddGoFlagSet
+// uses the flag.Value of merged flags as is when it implements it, and sets
+// values directly, without parsing the original flag set.
+type pflagValue interface {
+	String() string
+	Set(string) error
+	Type() string
+}
+
+func TestFlagsMergedIntoPFlag(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := flagConfig{Name: "svc", Port: 80}
+	fs := config.NewFlagSet("test", defaults)
+	values := make(map[string]pflagValue)
+	fs.VisitAll(func(f *flag.Flag) {
+		v, ok := f.Value.(pflagValue)
+		assert.That(ok, pred.IsEqualTo(true), f.Name)
+		values[f.Name] = v
+	})
+	assert.That(values["port"].Type(), pred.IsEqualTo("int"))
+	assert.That(values["timeout"].Type(), pred.IsEqualTo("time.Duration"))
+
+	assert.That(values["port"].Set("8080"), pred.IsNil())
+	assert.That(values["debug"].Set("true"), pred.IsNil())
+	assert.That(fs.Parsed(), pred.IsEqualTo(false))
+
+	icfg, errs := loadConfig(t, "name: loaded\nport: 443\n", defaults, config.OptFlags(fs))
+	assert.That(errs, pred.IsEmpty())
+	cfg := icfg.(*flagConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("loaded"))
+	assert.That(cfg.Port, pred.IsEqualTo(8080))
+	assert.That(cfg.Debug, pred.IsEqualTo(true))
+}
+
+func TestFlagsWithInvalidValue(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	fs := config.NewFlagSet("test", flagConfig{})
+	err := fs.Parse([]string{"-port", "not-a-number"})
+	assert.That(err, pred.IsNil())
+
+	_, errs := loadConfig(t, "name: loaded\n", flagConfig{}, config.OptFlags(fs))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("flag -port"))
+}
+
+func TestOptSet(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	fs := config.NewFlagSet("test", flagConfig{})
+	fs.Parse([]string{"-port", "8080"})
+
+	icfg, errs := loadConfig(t, "name: loaded\n", flagConfig{},
+		config.OptFlags(fs),
+		config.OptSet("port", "9090"),
+		config.OptSet("server.host", "example.com"),
+	)
+	assert.That(errs, pred.IsEmpty())
+	cfg := icfg.(*flagConfig)
+	assert.That(cfg.Port, pred.IsEqualTo(9090))
+	assert.That(cfg.Server.Host, pred.IsEqualTo("example.com"))
+
+	_, errs = loadConfig(t, "name: loaded\n", flagConfig{}, config.OptSet("server.hots", "x"))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("key server.hots: unknown key path"))
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// FreezeMode defines how the configuration returned by Get is protected
+// against mutations by its consumers
+type FreezeMode int
+
+const (
+	// FreezeCopy makes Get return a deep copy of the configuration on every
+	// call, so that mutations by a consumer never affect other consumers
+	FreezeCopy FreezeMode = iota + 1
+
+	// FreezeDetect keeps a fingerprint of the configuration when it is
+	// applied, and reports ErrConfigMutated through the error handlers if
+	// it was modified by the time it is replaced by a reload
+	FreezeDetect
+)
+
+// ErrConfigMutated is reported when a consumer modified the configuration
+// returned by Get, with FreezeDetect enabled
+var ErrConfigMutated = errors.New("configuration was modified after being loaded")
+
+// OptFreeze protects the configuration returned by Get against mutations by
+// its consumers, according to mode
+func OptFreeze(mode FreezeMode) Option {
+	return func(c *Loader) {
+		c.freezeMode = mode
+	}
+}
+
+// fingerprint returns a hash of the content of the configuration, or nil if
+// it cannot be computed
+func (c *Loader) fingerprint(cfg interface{}) []byte {
+	doc, err := encodeValue(reflect.ValueOf(cfg), c.keyNaming)
+	if err != nil {
+		return nil
+	}
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(j)
+	return sum[:]
+}
+
+// checkMutations reports ErrConfigMutated if the active configuration no
+// longer matches its fingerprint
+func (c *Loader) checkMutations(cfg interface{}) {
+	if c.freezeMode != FreezeDetect || cfg == nil || c.configFingerprint == nil {
+		return
+	}
+	if string(c.fingerprint(cfg)) != string(c.configFingerprint) {
+		c.handleError(ErrConfigMutated)
+	}
+}
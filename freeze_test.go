@@ -0,0 +1,46 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestFreezeCopy(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: frozen\n"), testConfigDefaults,
+		config.OptFreeze(config.FreezeCopy),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	c.Get().(*testConfig).Name = "modified"
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("frozen"))
+}
+
+func TestFreezeDetect(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var errs []error
+	c, err := config.NewLoaderFromBytes([]byte("name: frozen\n"), testConfigDefaults,
+		config.OptFreeze(config.FreezeDetect),
+		config.ErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	c.Update([]byte("name: updated\n"))
+	assert.That(errs, pred.IsEmpty())
+
+	c.Get().(*testConfig).Name = "modified"
+	c.Update([]byte("name: again\n"))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errors.Is(errs[0], config.ErrConfigMutated), pred.IsEqualTo(true))
+}
@@ -0,0 +1,21 @@
+package config
+
+import "fmt"
+
+// GetAs returns the current configuration of the loader as a *T. Like a type
+// assertion on Get(), it panics if the configuration is of a different type,
+// with a message naming both types.
+func GetAs[T any](l *Loader) *T {
+	cfg := l.Get()
+	v, ok := cfg.(*T)
+	if !ok {
+		panic(fmt.Sprintf("config is of type %T, not %T", cfg, v))
+	}
+	return v
+}
+
+// MustGetAs is equivalent to GetAs, for call sites that want the panic on
+// type mismatch to be explicit
+func MustGetAs[T any](l *Loader) *T {
+	return GetAs[T](l)
+}
@@ -0,0 +1,36 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestGetAs(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: typed\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(config.GetAs[testConfig](c).Name, pred.IsEqualTo("typed"))
+	assert.That(config.MustGetAs[testConfig](c).Port, pred.IsEqualTo(1234))
+}
+
+func TestGetAsPanicsOnMismatch(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: typed\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	defer func() {
+		r := recover()
+		assert.That(r, pred.IsEqualTo(
+			"config is of type *config_test.testConfig, not *config_test.profileTestConfig"))
+	}()
+	config.GetAs[profileTestConfig](c)
+}
@@ -1,11 +1,20 @@
 module github.com/marcus999/go-config
 
+go 1.18
+
 require (
 	github.com/fsnotify/fsnotify v1.4.7
 	github.com/ghodss/yaml v1.0.1-0.20180820084758-c7ce16629ff4
-	github.com/go-yaml/yaml v2.1.0+incompatible
-	github.com/jinzhu/copier v0.0.0-20180308034124-7e38e58719c3
 	github.com/marcus999/go-testpredicate v0.1.1
-	golang.org/x/sys v0.0.0-20181128092732-4ed8d59d0b35 // indirect
+	google.golang.org/grpc v1.50.1
+)
+
+require (
+	github.com/golang/protobuf v1.5.2 // indirect
+	golang.org/x/net v0.0.0-20201021035429-f5854403a974 // indirect
+	golang.org/x/sys v0.0.0-20210119212857-b64e53b001e4 // indirect
+	golang.org/x/text v0.3.3 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	google.golang.org/protobuf v1.27.1 // indirect
 	gopkg.in/yaml.v2 v2.2.1 // indirect
 )
@@ -0,0 +1,120 @@
+package config
+
+import "sort"
+
+// AddReloadHandler attaches a function to be called when the configuration is
+// reloaded, like the ReloadHandler option but after the loader is created. It
+// returns a function that detaches the handler.
+func (c *Loader) AddReloadHandler(f func(interface{})) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.reloadHandlers.add(f)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.reloadHandlers.remove(id)
+	}
+}
+
+// ReloadHandlerWithPriority attaches a function to be called when the
+// configuration is reloaded, like ReloadHandler, ordered by decreasing
+// priority among reload handlers. Handlers added with ReloadHandler have
+// priority 0, and handlers of the same priority are called in registration
+// order. Ordering is not guaranteed with OptAsyncHandlers.
+func ReloadHandlerWithPriority(priority int, f func(interface{})) Option {
+	return func(c *Loader) {
+		c.reloadHandlers.addWithPriority(f, priority)
+	}
+}
+
+// AddReloadHandlerWithPriority attaches a function to be called when the
+// configuration is reloaded, like ReloadHandlerWithPriority but after the
+// loader is created. It returns a function that detaches the handler.
+func (c *Loader) AddReloadHandlerWithPriority(priority int, f func(interface{})) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.reloadHandlers.addWithPriority(f, priority)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.reloadHandlers.remove(id)
+	}
+}
+
+// AddErrorHandler attaches a function to be called when an error occurs
+// during a background operation, like the ErrorHandler option but after the
+// loader is created. It returns a function that detaches the handler.
+func (c *Loader) AddErrorHandler(f func(err error)) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.errorHandlers.add(f)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.errorHandlers.remove(id)
+	}
+}
+
+// AddValidationHandler attaches a function to be called to validate new
+// configurations, like the ValidationHandler option but after the loader is
+// created. It only applies to subsequent reloads, and returns a function that
+// detaches the handler.
+func (c *Loader) AddValidationHandler(f func(interface{}) (interface{}, error)) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.validationHandlers.add(f)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.validationHandlers.remove(id)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// handlerSet
+// ---------------------------------------------------------------------------
+
+// handlerSet is a list of handlers that can be removed individually, ordered
+// by decreasing priority, then by registration order. It is not synchronized;
+// the list returned by handlers is never modified, so that it can be iterated
+// without holding a lock.
+type handlerSet[F any] struct {
+	nextID  uint64
+	entries []handlerEntry[F]
+}
+
+type handlerEntry[F any] struct {
+	id       uint64
+	priority int
+	f        F
+}
+
+func (s *handlerSet[F]) add(f F) uint64 {
+	return s.addWithPriority(f, 0)
+}
+
+func (s *handlerSet[F]) addWithPriority(f F, priority int) uint64 {
+	s.nextID++
+	i := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].priority < priority
+	})
+	entries := make([]handlerEntry[F], 0, len(s.entries)+1)
+	entries = append(entries, s.entries[:i]...)
+	entries = append(entries, handlerEntry[F]{id: s.nextID, priority: priority, f: f})
+	s.entries = append(entries, s.entries[i:]...)
+	return s.nextID
+}
+
+func (s *handlerSet[F]) remove(id uint64) {
+	entries := make([]handlerEntry[F], 0, len(s.entries))
+	for _, e := range s.entries {
+		if e.id != id {
+			entries = append(entries, e)
+		}
+	}
+	s.entries = entries
+}
+
+func (s *handlerSet[F]) handlers() []handlerEntry[F] {
+	return s.entries
+}
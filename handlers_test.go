@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestAddAndRemoveReloadHandler(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	var first, second int
+	removeFirst := c.AddReloadHandler(func(cfg interface{}) { first++ })
+	c.AddReloadHandler(func(cfg interface{}) { second++ })
+
+	c.Update([]byte("name: first\n"))
+	removeFirst()
+	removeFirst()
+	c.Update([]byte("name: second\n"))
+
+	assert.That(first, pred.IsEqualTo(1))
+	assert.That(second, pred.IsEqualTo(2))
+}
+
+func TestAddAndRemoveValidationHandler(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	remove := c.AddValidationHandler(func(cfg interface{}) (interface{}, error) {
+		return nil, errors.New("rejected")
+	})
+	assert.That(c.Update([]byte("name: first\n")), pred.IsNotNil())
+
+	remove()
+	assert.That(c.Update([]byte("name: second\n")), pred.IsNil())
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("second"))
+}
+
+func TestRemoveChangeHandler(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	var changes int
+	remove := c.OnChange("name", func(old, new interface{}) { changes++ })
+	c.Update([]byte("name: first\n"))
+	remove()
+	c.Update([]byte("name: second\n"))
+
+	assert.That(changes, pred.IsEqualTo(1))
+}
+
+func TestReloadHandlerPriority(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var order []string
+	handler := func(name string) func(interface{}) {
+		return func(cfg interface{}) { order = append(order, name) }
+	}
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.ReloadHandler(handler("routes")),
+		config.ReloadHandlerWithPriority(10, handler("pool")),
+		config.ReloadHandlerWithPriority(-1, handler("metrics")),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	c.AddReloadHandler(handler("cache"))
+	remove := c.AddReloadHandlerWithPriority(10, handler("secrets"))
+
+	c.Update([]byte("name: first\n"))
+	assert.That(order, pred.IsEqualTo([]string{
+		"pool", "secrets", "routes", "cache", "metrics",
+	}))
+
+	order = nil
+	remove()
+	c.Update([]byte("name: second\n"))
+	assert.That(order, pred.IsEqualTo([]string{
+		"pool", "routes", "cache", "metrics",
+	}))
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// Handler returns an http.Handler serving the status of the loader along
+// with the active configuration. Secret values are redacted as with
+// RedactDocument, as well as values that were encrypted in the configuration
+// file, set from the environment or held by fields tagged with
+// `secret:"..."`. The response is JSON, or YAML if requested through the
+// Accept header. The handler is meant to be mounted on an existing admin mux,
+// e.g.:
+//
+//	mux.Handle("/debug/config", loader.Handler())
+func (c *Loader) Handler() http.Handler {
+	return http.HandlerFunc(c.serveStatus)
+}
+
+// statusResponse is the document served by Handler
+type statusResponse struct {
+	Generation    uint64      `json:"generation"`
+	LastReload    time.Time   `json:"last_reload"`
+	LastError     string      `json:"last_error,omitempty"`
+	UsingDefaults bool        `json:"using_defaults"`
+	Checksum      string      `json:"checksum,omitempty"`
+	Paused        bool        `json:"paused"`
+	Config        interface{} `json:"config,omitempty"`
+}
+
+func (c *Loader) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := c.newStatusResponse(true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, resp)
+}
+
+// ReloadEndpoint returns an http.Handler that reloads the configuration on
+// POST requests, as with Reload, and responds with the resulting status of
+// the loader. The response status code is 200 if the configuration was
+// applied, 503 if the loader is closed, and 500 otherwise.
+func (c *Loader) ReloadEndpoint() http.Handler {
+	return http.HandlerFunc(c.serveReload)
+}
+
+func (c *Loader) serveReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	code := http.StatusOK
+	err := c.Reload()
+	if err == ErrClosed {
+		code = http.StatusServiceUnavailable
+	} else if err != nil {
+		code = http.StatusInternalServerError
+	}
+	resp, _ := c.newStatusResponse(false)
+	if err != nil {
+		resp.LastError = err.Error()
+	}
+	writeResponse(w, r, code, resp)
+}
+
+// newStatusResponse returns the current status of the loader, along with the
+// redacted active configuration if withConfig is set
+func (c *Loader) newStatusResponse(withConfig bool) (statusResponse, error) {
+	s := c.Status()
+	resp := statusResponse{
+		Generation:    s.Generation,
+		LastReload:    s.LastReload,
+		UsingDefaults: s.UsingDefaults,
+		Checksum:      s.Checksum,
+		Paused:        s.Paused,
+	}
+	if s.LastError != nil {
+		resp.LastError = s.LastError.Error()
+	}
+	if withConfig {
+		doc, err := c.redactedConfig()
+		if err != nil {
+			return resp, err
+		}
+		resp.Config = doc
+	}
+	return resp, nil
+}
+
+// writeResponse writes resp as JSON, or as YAML if requested through the
+// Accept header
+func writeResponse(w http.ResponseWriter, r *http.Request, code int, resp interface{}) {
+	data, err := json.MarshalIndent(resp, "", "  ")
+	contentType := "application/json"
+	if err == nil && acceptsYAML(r) {
+		data, err = yaml.JSONToYAML(data)
+		contentType = "application/yaml"
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// acceptsYAML returns true if the Accept header of the request lists a YAML
+// media type
+func acceptsYAML(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if i := strings.Index(mediaType, ";"); i != -1 {
+				mediaType = mediaType[:i]
+			}
+			switch strings.TrimSpace(strings.ToLower(mediaType)) {
+			case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package config_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// Test admin HTTP handler
+// ---------------------------------------------------------------------------
+
+func TestHandlerServesStatusAsJSON(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "user: admin\npassword: s3cr3t\n")
+	defer cleanup()
+	c, err := config.NewLoader(filename, secretConfig{})
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusOK))
+	assert.That(rec.Header().Get("Content-Type"), pred.IsEqualTo("application/json"))
+
+	var resp map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.That(err, pred.IsNil())
+	assert.That(resp["generation"], pred.IsEqualTo(1.0))
+	assert.That(resp["using_defaults"], pred.IsEqualTo(false))
+	assert.That(resp["last_error"], pred.IsNil())
+	assert.That(resp["config"], pred.IsEqualTo(map[string]interface{}{
+		"user":     "admin",
+		"password": config.RedactedValue,
+		"tokens":   nil,
+	}))
+}
+
+type redactedConfig struct {
+	DSN    string `json:"dsn"`
+	Region string `json:"region" env:"TEST_HANDLER_REGION"`
+	Key    string `json:"key" secret:"api_key"`
+	Name   string `json:"name"`
+}
+
+func TestHandlerRedactsValuesByOrigin(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	p, err := config.NewAESKeyProvider([]byte("0123456789abcdef"))
+	assert.That(err, pred.IsNil())
+	dsn, err := config.EncryptValue(p, []byte("postgres://admin:s3cr3t@db/app"))
+	assert.That(err, pred.IsNil())
+	defer setTestEnv(t, map[string]string{"TEST_HANDLER_REGION": "eu-west-1"})()
+
+	filename, cleanup := writeConfigFile(t, "dsn: "+dsn+"\nkey: k3y\nname: app\n")
+	defer cleanup()
+	c, err := config.NewLoader(filename, redactedConfig{}, config.OptKeyProvider(p))
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get().(*redactedConfig).DSN, pred.IsEqualTo("postgres://admin:s3cr3t@db/app"))
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusOK))
+	assert.That(strings.Contains(rec.Body.String(), "s3cr3t"), pred.IsEqualTo(false))
+
+	var resp map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.That(err, pred.IsNil())
+	assert.That(resp["config"], pred.IsEqualTo(map[string]interface{}{
+		"dsn":    config.RedactedValue,
+		"region": config.RedactedValue,
+		"key":    config.RedactedValue,
+		"name":   "app",
+	}))
+}
+
+func TestHandlerServesStatusAsYAML(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoader("a/b/c.yaml", testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html, application/yaml;q=0.9")
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusOK))
+	assert.That(rec.Header().Get("Content-Type"), pred.IsEqualTo("application/yaml"))
+	assert.That(rec.Body.String(), pred.Contains("using_defaults: true\n"))
+	assert.That(rec.Body.String(), pred.Contains("last_error: "))
+	assert.That(rec.Body.String(), pred.Contains("  Name: defaultName\n"))
+}
+
+func TestHandlerRejectsOtherMethods(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoader("a/b/c.yaml", testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("DELETE", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusMethodNotAllowed))
+}
+
+// ---------------------------------------------------------------------------
+// Test reload endpoint
+// ---------------------------------------------------------------------------
+
+func TestReloadEndpoint(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: initial\n")
+	defer cleanup()
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptDebounceInterval(time.Hour))
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	ioutil.WriteFile(filename, []byte("name: updated\n"), 0666)
+	rec := httptest.NewRecorder()
+	c.ReloadEndpoint().ServeHTTP(rec, httptest.NewRequest("POST", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusOK))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("updated"))
+
+	var resp map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.That(err, pred.IsNil())
+	assert.That(resp["generation"], pred.IsEqualTo(2.0))
+	assert.That(resp["config"], pred.IsNil())
+
+	ioutil.WriteFile(filename, []byte("port: not-a-number\n"), 0666)
+	rec = httptest.NewRecorder()
+	c.ReloadEndpoint().ServeHTTP(rec, httptest.NewRequest("POST", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusInternalServerError))
+	assert.That(rec.Body.String(), pred.Contains(`"last_error": "Port: cannot decode`))
+
+	rec = httptest.NewRecorder()
+	c.ReloadEndpoint().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusMethodNotAllowed))
+
+	c.Close()
+	rec = httptest.NewRecorder()
+	c.ReloadEndpoint().ServeHTTP(rec, httptest.NewRequest("POST", "/", nil))
+	assert.That(rec.Code, pred.IsEqualTo(http.StatusServiceUnavailable))
+	assert.That(c.Reload(), pred.IsEqualTo(config.ErrClosed))
+}
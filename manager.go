@@ -0,0 +1,292 @@
+package config
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+	"github.com/marcus999/go-config/pkg/watch"
+)
+
+// Manager owns a set of named loaders sharing common options, e.g. a logger
+// or debounce settings, aggregates their errors and status, and closes them
+// all together. The loaders share a single filesystem watcher and a single
+// debouncer, so that changes to several configuration files, e.g. during a
+// deployment, are applied together at the end of the burst.
+type Manager struct {
+	opts      []Option
+	backend   *watch.SharedBackend
+	debouncer *sharedDebouncer
+
+	mu            sync.Mutex
+	closed        bool
+	adding        sync.WaitGroup
+	loaders       map[string]*Loader
+	errorHandlers []func(name string, err error)
+}
+
+// ErrManagerClosed is returned by Manager.Add once the manager is closed
+var ErrManagerClosed = errors.New("manager is closed")
+
+// NewManager creates a new Manager, with options applied to all the loaders
+// it creates. If OptExpvar is specified, the manager publishes the state of
+// all its loaders under that name, by loader name, instead of each loader
+// publishing its own.
+func NewManager(opts ...Option) *Manager {
+	m := &Manager{
+		opts:    opts,
+		loaders: make(map[string]*Loader),
+	}
+
+	// The manager options are applied to a bare loader to find out the
+	// settings of the shared resources
+	shared := newLoader(struct{}{}, opts)
+	if shared.debounceInterval != 0 {
+		m.debouncer = newSharedDebouncer(shared.debounceInterval, shared.debounceMaxDelay)
+	}
+	backend, err := watch.NewSharedBackend(watch.NewFsnotifyBackend)
+	if err != nil {
+		shared.logger.Printf("failed to create shared watcher, loaders will use their own: %v", err)
+	} else {
+		m.backend = backend
+	}
+	if shared.expvarName != "" {
+		expvar.Publish(shared.expvarName, expvar.Func(m.expvarValue))
+	}
+	return m
+}
+
+// ErrorHandler attaches a function to be called when an error occurs in any
+// of the loaders, along with the name of that loader. It only applies to
+// loaders added after the call.
+func (m *Manager) ErrorHandler(f func(name string, err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errorHandlers = append(m.errorHandlers, f)
+}
+
+// Add creates a new named loader, with the manager options followed by the
+// specified options
+func (m *Manager) Add(name, filename string, defaultConfig interface{}, opts ...Option) (*Loader, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, ErrManagerClosed
+	}
+	if _, ok := m.loaders[name]; ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("duplicate loader name '%v'", name)
+	}
+	handlers := m.errorHandlers
+	m.adding.Add(1)
+	defer m.adding.Done()
+	m.mu.Unlock()
+
+	var all []Option
+	all = append(all, m.opts...)
+	all = append(all, m.sharedResources())
+	all = append(all, opts...)
+	all = append(all, ErrorHandler(func(err error) {
+		for _, h := range handlers {
+			h(name, err)
+		}
+	}))
+
+	l, err := NewLoader(filename, defaultConfig, all...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		l.Close()
+		return nil, ErrManagerClosed
+	}
+	if _, ok := m.loaders[name]; ok {
+		l.Close()
+		return nil, fmt.Errorf("duplicate loader name '%v'", name)
+	}
+	m.loaders[name] = l
+	return l, nil
+}
+
+// sharedResources returns the option attaching a new loader to the resources
+// shared by the manager. It also clears the expvar name from the manager
+// options, published once by the manager itself.
+func (m *Manager) sharedResources() Option {
+	return func(c *Loader) {
+		c.expvarName = ""
+		c.sharedDebouncer = m.debouncer
+		if m.backend != nil {
+			c.watchOptions = append(c.watchOptions, watch.WithBackend(m.backend.Factory()))
+		}
+	}
+}
+
+// Loader returns the named loader, or nil if there is no such loader
+func (m *Manager) Loader(name string) *Loader {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loaders[name]
+}
+
+// Names returns the sorted names of all loaders
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.loaders))
+	for name := range m.loaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Status returns the status of all loaders, by name
+func (m *Manager) Status() map[string]Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := make(map[string]Status, len(m.loaders))
+	for name, l := range m.loaders {
+		status[name] = l.Status()
+	}
+	return status
+}
+
+// Errors returns the last load error of each loader that failed to load its
+// configuration file, by name
+func (m *Manager) Errors() map[string]error {
+	errs := make(map[string]error)
+	for name, s := range m.Status() {
+		if s.LastError != nil {
+			errs[name] = s.LastError
+		}
+	}
+	return errs
+}
+
+// Close closes all the loaders, removes them from the manager and releases
+// the shared resources. The manager cannot be used to add loaders afterward.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.mu.Unlock()
+
+	// Loaders being added concurrently are either registered or closed by
+	// Add before it returns
+	m.adding.Wait()
+	m.mu.Lock()
+	loaders := m.loaders
+	m.loaders = make(map[string]*Loader)
+	m.mu.Unlock()
+
+	for _, l := range loaders {
+		l.Close()
+	}
+	if m.debouncer != nil {
+		m.debouncer.close()
+	}
+	if m.backend != nil {
+		m.backend.Close()
+	}
+}
+
+func (m *Manager) expvarValue() interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v := make(map[string]interface{}, len(m.loaders))
+	for name, l := range m.loaders {
+		v[name] = l.expvarValue()
+	}
+	return v
+}
+
+// ---------------------------------------------------------------------------
+// sharedDebouncer
+// ---------------------------------------------------------------------------
+
+// sharedDebouncer feeds the reload pipelines of several loaders through a
+// single debouncer. At the end of a burst, every loader that received a
+// change is reloaded.
+type sharedDebouncer struct {
+	interval time.Duration
+	maxDelay time.Duration
+	in       chan<- interface{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	outputs map[*Loader]chan struct{}
+}
+
+func newSharedDebouncer(interval, maxDelay time.Duration) *sharedDebouncer {
+	in, out := debounce.NewGrouped(interval, maxDelay)
+	d := &sharedDebouncer{
+		interval: interval,
+		maxDelay: maxDelay,
+		in:       in,
+		outputs:  make(map[*Loader]chan struct{}),
+	}
+	go d.dispatch(out)
+	return d
+}
+
+// matches returns true if the debounce settings of c are those of the shared
+// debouncer
+func (d *sharedDebouncer) matches(c *Loader) bool {
+	return c.debounceInterval == d.interval && c.debounceMaxDelay == d.maxDelay
+}
+
+// pipeline returns the input and output channels of the reload pipeline of
+// c. The output channel is closed once the input channel is closed.
+func (d *sharedDebouncer) pipeline(c *Loader) (chan<- struct{}, <-chan struct{}) {
+	in := make(chan struct{})
+	out := make(chan struct{}, 1)
+	d.mu.Lock()
+	d.outputs[c] = out
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for range in {
+			d.in <- c
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.outputs, c)
+		close(out)
+	}()
+	return in, out
+}
+
+// dispatch notifies the loaders of each debounced group, coalescing with any
+// notification they have not processed yet
+func (d *sharedDebouncer) dispatch(groups <-chan []interface{}) {
+	for group := range groups {
+		d.mu.Lock()
+		for _, v := range group {
+			if out, ok := d.outputs[v.(*Loader)]; ok {
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+		d.mu.Unlock()
+	}
+}
+
+// close stops the debouncer once the pipelines of all loaders are closed
+func (d *sharedDebouncer) close() {
+	d.wg.Wait()
+	close(d.in)
+}
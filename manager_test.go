@@ -0,0 +1,112 @@
+package config_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestManager(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	appFile, cleanupApp := writeConfigFile(t, "name: app\n")
+	defer cleanupApp()
+	logFile, cleanupLog := writeConfigFile(t, "port: invalid\n")
+	defer cleanupLog()
+
+	type namedError struct {
+		name string
+		err  error
+	}
+	var errs []namedError
+
+	m := config.NewManager(config.OptStrictParsing())
+	m.ErrorHandler(func(name string, err error) {
+		errs = append(errs, namedError{name, err})
+	})
+	defer m.Close()
+
+	app, err := m.Add("app", appFile, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	assert.That(app.Get().(*testConfig).Name, pred.IsEqualTo("app"))
+
+	_, err = m.Add("logging", logFile, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+
+	_, err = m.Add("app", appFile, testConfigDefaults)
+	assert.That(err, pred.IsNotNil())
+
+	assert.That(m.Names(), pred.IsEqualTo([]string{"app", "logging"}))
+	assert.That(m.Loader("app"), pred.IsEqualTo(app))
+	assert.That(m.Loader("other") == nil, pred.IsEqualTo(true))
+
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].name, pred.IsEqualTo("logging"))
+
+	status := m.Status()
+	assert.That(status["app"].UsingDefaults, pred.IsEqualTo(false))
+	assert.That(status["logging"].UsingDefaults, pred.IsEqualTo(true))
+
+	merrs := m.Errors()
+	assert.That(merrs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(merrs["logging"], pred.IsNotNil())
+
+	m.Close()
+	assert.That(m.Names(), pred.IsEmpty())
+
+	_, err = m.Add("app", appFile, testConfigDefaults)
+	assert.That(err, pred.IsEqualTo(config.ErrManagerClosed))
+}
+
+func TestManagerSharedReloads(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	appFile, cleanupApp := writeConfigFile(t, "name: app\n")
+	defer cleanupApp()
+	logFile, cleanupLog := writeConfigFile(t, "name: logging\n")
+	defer cleanupLog()
+
+	// expvar names cannot be reused, even across repeated test runs
+	name := fmt.Sprintf("manager_test_%d", time.Now().UnixNano())
+	reloaded := make(chan string, 10)
+	m := config.NewManager(
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.OptExpvar(name),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg.(*testConfig).Name
+		}),
+	)
+	defer m.Close()
+
+	_, err := m.Add("app", appFile, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	_, err = m.Add("logging", logFile, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(appFile, []byte("name: app2\n"), 0666)
+	ioutil.WriteFile(logFile, []byte("name: logging2\n"), 0666)
+	names := map[string]bool{}
+	for !names["app2"] || !names["logging2"] {
+		select {
+		case name := <-reloaded:
+			names[name] = true
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for reloads, got %v", names)
+		}
+	}
+
+	var state map[string]map[string]interface{}
+	err = json.Unmarshal([]byte(expvar.Get(name).String()), &state)
+	assert.That(err, pred.IsNil())
+	assert.That(state["app"]["generation"], pred.Gt(1))
+	assert.That(state["logging"]["generation"], pred.Gt(1))
+}
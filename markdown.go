@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WriteMarkdown writes a Markdown table documenting all the fields of the
+// defaults configuration struct, with their key, type, default value and the
+// description found in their `doc:"..."` tag.
+func WriteMarkdown(w io.Writer, defaults interface{}) error {
+	v := reflect.ValueOf(defaults)
+	if !isStructType(v.Type()) {
+		return fmt.Errorf("cannot document non-struct type %v", v.Type())
+	}
+
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("|-----|------|---------|-------------|\n")
+	walkFields(v, "", func(f fieldInfo) {
+		fmt.Fprintf(&b, "| `%v` | `%v` | %v | %v |\n",
+			f.Path, f.Field.Type,
+			markdownValue(f.Value),
+			escapeMarkdown(f.Field.Tag.Get("doc")))
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return ""
+	}
+	if v.IsZero() {
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Interface:
+			return ""
+		}
+	}
+	s := fmt.Sprintf("%v", reflect.Indirect(v).Interface())
+	if v.Kind() == reflect.String {
+		s = fmt.Sprintf("%q", s)
+	}
+	return "`" + escapeMarkdown(s) + "`"
+}
+
+func escapeMarkdown(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type docTLSConfig struct {
+	CertFile string `json:"cert_file" doc:"Path to the certificate file"`
+	KeyFile  string `json:"key_file" doc:"Path to the key file"`
+}
+
+type docConfig struct {
+	Endpoint string        `json:"endpoint" doc:"Upstream endpoint"`
+	Port     int           `json:"port" doc:"Listening port"`
+	Timeout  time.Duration `json:"timeout"`
+	Tags     []string      `json:"tags" doc:"Tags, separated | by pipes"`
+	TLS      *docTLSConfig `json:"tls"`
+	Ignored  string        `json:"-"`
+	internal int
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var b strings.Builder
+	err := config.WriteMarkdown(&b, &docConfig{
+		Endpoint: "localhost",
+		Port:     8080,
+		Timeout:  time.Second,
+	})
+	assert.That(err, pred.IsNil())
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.That(lines, pred.IsEqualTo([]string{
+		"| Key | Type | Default | Description |",
+		"|-----|------|---------|-------------|",
+		"| `endpoint` | `string` | `\"localhost\"` | Upstream endpoint |",
+		"| `port` | `int` | `8080` | Listening port |",
+		"| `timeout` | `time.Duration` | `1s` |  |",
+		"| `tags` | `[]string` |  | Tags, separated \\| by pipes |",
+		"| `tls.cert_file` | `string` |  | Path to the certificate file |",
+		"| `tls.key_file` | `string` |  | Path to the key file |",
+	}))
+}
+
+func TestWriteMarkdownWithNonStruct(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var b strings.Builder
+	err := config.WriteMarkdown(&b, 42)
+	assert.That(err, pred.IsNotNil())
+}
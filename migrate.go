@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// VersionKey is the top-level key holding the schema version of a
+// configuration file
+const VersionKey = "version"
+
+// MigrationFunc converts the raw content of a configuration file from one
+// schema version to the next, modifying it in place
+type MigrationFunc func(doc map[string]interface{}) error
+
+type migration struct {
+	from, to int
+	f        MigrationFunc
+}
+
+// OptMigration registers a function converting configuration files from
+// schema version `from` to version `to`. Before decoding, migrations are
+// chained from the version found under the top-level "version" key, or 0 if
+// the key is missing, until no migration applies. The version key is then
+// updated with the resulting version, and should be declared in the
+// configuration struct when using OptStrictParsing.
+func OptMigration(from, to int, f MigrationFunc) Option {
+	return func(c *Loader) {
+		c.migrations = append(c.migrations, migration{from: from, to: to, f: f})
+	}
+}
+
+// applyMigrations runs the registered migrations applicable to doc
+func applyMigrations(doc interface{}, migrations []migration) (interface{}, error) {
+	m, ok := doc.(map[string]interface{})
+	if len(migrations) == 0 || !ok {
+		return doc, nil
+	}
+
+	version, err := documentVersion(m)
+	if err != nil {
+		return nil, err
+	}
+	initial := version
+	for i := 0; i < len(migrations); i++ {
+		mig := findMigration(migrations, version)
+		if mig == nil {
+			break
+		}
+		if err := mig.f(m); err != nil {
+			return nil, fmt.Errorf("migration from version %v to %v: %v", mig.from, mig.to, err)
+		}
+		version = mig.to
+	}
+	if version != initial {
+		m[VersionKey] = json.Number(fmt.Sprint(version))
+	}
+	return m, nil
+}
+
+func documentVersion(m map[string]interface{}) (int, error) {
+	v, ok := m[VersionKey]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, decodeErrorf(VersionKey, "expected an integer, got %v", v)
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, decodeErrorf(VersionKey, "expected an integer, got %v", n)
+	}
+	return int(i), nil
+}
+
+// findMigration returns the first registered migration from version
+func findMigration(migrations []migration, version int) *migration {
+	for i := range migrations {
+		if migrations[i].from == version && migrations[i].to != version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
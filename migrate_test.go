@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type migratedTestConfig struct {
+	Version int `json:"version"`
+	Server  struct {
+		ListenAddr string `json:"listen_addr"`
+	} `json:"server"`
+}
+
+var testMigrations = []config.Option{
+	config.OptMigration(0, 1, func(doc map[string]interface{}) error {
+		doc["server"] = map[string]interface{}{"host": doc["host"]}
+		delete(doc, "host")
+		return nil
+	}),
+	config.OptMigration(1, 2, func(doc map[string]interface{}) error {
+		server, _ := doc["server"].(map[string]interface{})
+		if server == nil {
+			return fmt.Errorf("missing server section")
+		}
+		server["listen_addr"] = fmt.Sprintf("%v:8080", server["host"])
+		delete(server, "host")
+		return nil
+	}),
+	config.OptStrictParsing(),
+}
+
+func TestMigrationsFromUnversionedFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, "host: localhost\n", migratedTestConfig{}, testMigrations...)
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*migratedTestConfig)
+	assert.That(cfg.Version, pred.IsEqualTo(2))
+	assert.That(cfg.Server.ListenAddr, pred.IsEqualTo("localhost:8080"))
+}
+
+func TestMigrationsFromIntermediateVersion(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+version: 1
+server:
+  host: example.com
+`, migratedTestConfig{}, testMigrations...)
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*migratedTestConfig)
+	assert.That(cfg.Version, pred.IsEqualTo(2))
+	assert.That(cfg.Server.ListenAddr, pred.IsEqualTo("example.com:8080"))
+}
+
+func TestMigrationsFromCurrentVersion(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+version: 2
+server:
+  listen_addr: example.com:80
+`, migratedTestConfig{}, testMigrations...)
+	assert.That(errs, pred.IsEmpty())
+	assert.That(icfg.(*migratedTestConfig).Server.ListenAddr, pred.IsEqualTo("example.com:80"))
+}
+
+func TestMigrationErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, errs := loadConfig(t, "version: 1\n", migratedTestConfig{}, testMigrations...)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("migration from version 1 to 2: missing server section"))
+
+	_, errs = loadConfig(t, "version: latest\n", migratedTestConfig{}, testMigrations...)
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("version: expected an integer"))
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KeyNaming converts the name of a Go struct field into the key expected in
+// the configuration file. It is only applied to fields without an explicit
+// `json:"..."` tag.
+type KeyNaming func(fieldName string) string
+
+// OptKeyNaming sets the naming convention used to map configuration file keys
+// onto the fields of the configuration struct, e.g. config.SnakeCase to map
+// `listen_addr` onto a `ListenAddr` field without a tag.
+func OptKeyNaming(n KeyNaming) Option {
+	return func(c *Loader) {
+		c.keyNaming = n
+	}
+}
+
+// SnakeCase converts a field name into snake_case, e.g. "TLSCertFile" into
+// "tls_cert_file".
+func SnakeCase(fieldName string) string {
+	return strings.Join(lowerWords(fieldName), "_")
+}
+
+// KebabCase converts a field name into kebab-case, e.g. "TLSCertFile" into
+// "tls-cert-file".
+func KebabCase(fieldName string) string {
+	return strings.Join(lowerWords(fieldName), "-")
+}
+
+// CamelCase converts a field name into camelCase, e.g. "TLSCertFile" into
+// "tlsCertFile".
+func CamelCase(fieldName string) string {
+	words := lowerWords(fieldName)
+	for i := 1; i < len(words); i++ {
+		r := []rune(words[i])
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, "")
+}
+
+// lowerWords splits a Go identifier into lowercase words, keeping acronyms
+// together, e.g. "HTTPServerAddr" into ["http", "server", "addr"].
+func lowerWords(name string) []string {
+	var words []string
+	r := []rune(name)
+	start := 0
+	for i := 1; i < len(r); i++ {
+		if r[i] == '_' {
+			if i > start {
+				words = append(words, string(r[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		lowerToUpper := unicode.IsUpper(r[i]) && !unicode.IsUpper(r[i-1]) && r[i-1] != '_'
+		acronymEnd := unicode.IsUpper(r[i]) && unicode.IsUpper(r[i-1]) &&
+			i+1 < len(r) && unicode.IsLower(r[i+1])
+		if (lowerToUpper || acronymEnd) && i > start {
+			words = append(words, string(r[start:i]))
+			start = i
+		}
+	}
+	if start < len(r) {
+		words = append(words, string(r[start:]))
+	}
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+	}
+	return words
+}
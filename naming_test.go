@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestKeyNamingConventions(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var tcs = []struct {
+		name, snake, kebab, camel string
+	}{
+		{"Port", "port", "port", "port"},
+		{"ListenAddr", "listen_addr", "listen-addr", "listenAddr"},
+		{"TLSCertFile", "tls_cert_file", "tls-cert-file", "tlsCertFile"},
+		{"HTTPServer", "http_server", "http-server", "httpServer"},
+		{"MaxConns2", "max_conns2", "max-conns2", "maxConns2"},
+		{"Already_Snake", "already_snake", "already-snake", "alreadySnake"},
+	}
+
+	for _, tc := range tcs {
+		assert.That(config.SnakeCase(tc.name), pred.IsEqualTo(tc.snake))
+		assert.That(config.KebabCase(tc.name), pred.IsEqualTo(tc.kebab))
+		assert.That(config.CamelCase(tc.name), pred.IsEqualTo(tc.camel))
+	}
+}
+
+type namingConfig struct {
+	ListenAddr string
+	TLSConfig  struct {
+		CertFile string
+	}
+	Explicit string `json:"ExplicitKey"`
+}
+
+func TestDecodeWithKeyNaming(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+listen-addr: localhost:8080
+tls-config:
+  cert-file: /etc/cert.pem
+ExplicitKey: value
+`, namingConfig{}, config.OptKeyNaming(config.KebabCase), config.OptStrictParsing())
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*namingConfig)
+	assert.That(cfg.ListenAddr, pred.IsEqualTo("localhost:8080"))
+	assert.That(cfg.TLSConfig.CertFile, pred.IsEqualTo("/etc/cert.pem"))
+	assert.That(cfg.Explicit, pred.IsEqualTo("value"))
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// HostPort is a network address made of a host and a port, expressed in
+// configuration files as "host:port", "[::1]:port" or ":port".
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// ParseHostPort parses a "host:port" network address, validating that the
+// port is a number between 0 and 65535.
+func ParseHostPort(s string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("invalid host:port address %q, %v", s, err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 0 || p > 65535 {
+		return HostPort{}, fmt.Errorf("invalid port %q in address %q", port, s)
+	}
+	return HostPort{Host: host, Port: p}, nil
+}
+
+// String returns the address in "host:port" form
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (hp HostPort) MarshalText() ([]byte, error) {
+	return []byte(hp.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (hp *HostPort) UnmarshalText(text []byte) error {
+	v, err := ParseHostPort(string(text))
+	if err != nil {
+		return err
+	}
+	*hp = v
+	return nil
+}
+
+var (
+	urlType   = reflect.TypeOf(url.URL{})
+	ipNetType = reflect.TypeOf(net.IPNet{})
+)
+
+// urlDecodeHook decodes url.URL and *url.URL values from strings
+func urlDecodeHook(data interface{}, to reflect.Type) (interface{}, error) {
+	s, ok := data.(string)
+	if !ok || to != urlType {
+		return data, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q, %v", s, err)
+	}
+	return *u, nil
+}
+
+// ipNetDecodeHook decodes net.IPNet and *net.IPNet values from CIDR notation
+// strings, e.g. "10.0.0.0/8". net.IP and net/netip types implement
+// encoding.TextUnmarshaler and are decoded without a hook.
+func ipNetDecodeHook(data interface{}, to reflect.Type) (interface{}, error) {
+	s, ok := data.(string)
+	if !ok || to != ipNetType {
+		return data, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q, %v", s, err)
+	}
+	return *ipNet, nil
+}
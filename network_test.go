@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestParseHostPort(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	hp, err := config.ParseHostPort("localhost:8080")
+	assert.That(err, pred.IsNil())
+	assert.That(hp, pred.IsEqualTo(config.HostPort{Host: "localhost", Port: 8080}))
+
+	hp, err = config.ParseHostPort("[::1]:443")
+	assert.That(err, pred.IsNil())
+	assert.That(hp.String(), pred.IsEqualTo("[::1]:443"))
+
+	hp, err = config.ParseHostPort(":9090")
+	assert.That(err, pred.IsNil())
+	assert.That(hp, pred.IsEqualTo(config.HostPort{Port: 9090}))
+
+	for _, input := range []string{"localhost", "localhost:http", "localhost:70000"} {
+		_, err = config.ParseHostPort(input)
+		assert.That(err, pred.IsNotNil(), "input: %v", input)
+	}
+}
+
+type networkConfig struct {
+	Endpoint *url.URL        `json:"endpoint"`
+	Callback url.URL         `json:"callback"`
+	BindIP   net.IP          `json:"bind_ip"`
+	Allowed  []*net.IPNet    `json:"allowed"`
+	Subnet   netip.Prefix    `json:"subnet"`
+	Listen   config.HostPort `json:"listen"`
+}
+
+func TestDecodeNetworkTypes(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+endpoint: https://example.com:8443/api?v=1
+callback: http://localhost/cb
+bind_ip: 192.168.1.10
+allowed:
+  - 10.0.0.0/8
+  - fd00::/8
+subnet: 172.16.0.0/12
+listen: 0.0.0.0:8080
+`, networkConfig{})
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*networkConfig)
+	assert.That(cfg.Endpoint.Host, pred.IsEqualTo("example.com:8443"))
+	assert.That(cfg.Endpoint.Path, pred.IsEqualTo("/api"))
+	assert.That(cfg.Callback.String(), pred.IsEqualTo("http://localhost/cb"))
+	assert.That(cfg.BindIP.String(), pred.IsEqualTo("192.168.1.10"))
+	assert.That(cfg.Allowed, pred.Length(pred.IsEqualTo(2)))
+	assert.That(cfg.Allowed[0].String(), pred.IsEqualTo("10.0.0.0/8"))
+	assert.That(cfg.Allowed[1].String(), pred.IsEqualTo("fd00::/8"))
+	assert.That(cfg.Subnet.String(), pred.IsEqualTo("172.16.0.0/12"))
+	assert.That(cfg.Listen, pred.IsEqualTo(config.HostPort{Host: "0.0.0.0", Port: 8080}))
+}
+
+func TestDecodeInvalidNetworkTypes(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var tcs = []struct {
+		content string
+		err     string
+	}{
+		{"endpoint: \"http://[::1\"", `^endpoint: invalid URL`},
+		{"bind_ip: 300.1.1.1", `^bind_ip: .*IP address`},
+		{"allowed:\n  - 10.0.0.0", `^allowed\[0\]: invalid CIDR`},
+		{"subnet: 10.0.0.1", `^subnet: `},
+		{"listen: localhost", `^listen: invalid host:port`},
+	}
+
+	for _, tc := range tcs {
+		_, errs := loadConfig(t, tc.content, networkConfig{})
+		assert.That(errs, pred.Length(pred.IsEqualTo(1)), "content: %v", tc.content)
+		if len(errs) == 1 {
+			assert.That(errs[0].Error(), pred.Matches(tc.err), "content: %v", tc.content)
+		}
+	}
+}
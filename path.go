@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pathSegment is a single element of a key path, either a key or an index
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// parsePath splits a dotted key path like "servers[0].tls.cert_file" into
+// its segments.
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		if i := strings.Index(part, "["); i != -1 {
+			key = part[:i]
+		}
+		if key == "" && part == key {
+			return nil, fmt.Errorf("invalid key path %q", path)
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key, index: -1})
+		}
+
+		rest := part[len(key):]
+		for rest != "" {
+			end := strings.Index(rest, "]")
+			if rest[0] != '[' || end == -1 {
+				return nil, fmt.Errorf("invalid key path %q", path)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index in key path %q", path)
+			}
+			segments = append(segments, pathSegment{index: n})
+			rest = rest[end+1:]
+		}
+	}
+	return segments, nil
+}
+
+// lookupPath returns the value found at the given key path in a
+// configuration object, matching struct fields the same way the decoder
+// does.
+func lookupPath(cfg interface{}, path string, naming KeyNaming) (reflect.Value, bool) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	v := reflect.ValueOf(cfg)
+	for _, s := range segments {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+
+		switch {
+		case s.index >= 0:
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array || s.index >= v.Len() {
+				return reflect.Value{}, false
+			}
+			v = v.Index(s.index)
+
+		case v.Kind() == reflect.Struct:
+			f := matchField(structFields(v.Type(), naming), s.key)
+			if f == nil {
+				return reflect.Value{}, false
+			}
+			var ok bool
+			if v, ok = fieldByIndexNoAlloc(v, f.index); !ok {
+				return reflect.Value{}, false
+			}
+
+		case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+			v = v.MapIndex(reflect.ValueOf(s.key).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return reflect.Value{}, false
+			}
+
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// valueAtPath returns the value found at the given key path as an
+// interface{}, or nil if there is no such value
+func valueAtPath(cfg interface{}, path string, naming KeyNaming) interface{} {
+	v, ok := lookupPath(cfg, path, naming)
+	if !ok || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// ---------------------------------------------------------------------------
+// dynamic access by key path
+// ---------------------------------------------------------------------------
+
+// Value returns the value found at the given dotted key path in the current
+// configuration, e.g. "server.tls.cert_file" or "servers[0].host". Keys are
+// matched the same way as when decoding the configuration file.
+func (c *Loader) Value(path string) (interface{}, bool) {
+	v, ok := lookupPath(c.Get(), path, c.keyNaming)
+	if !ok || !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// StringValue returns the string value found at the given key path
+func (c *Loader) StringValue(path string) (string, bool) {
+	v, ok := c.valueOfKind(path)
+	if !ok || v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// BoolValue returns the boolean value found at the given key path
+func (c *Loader) BoolValue(path string) (bool, bool) {
+	v, ok := c.valueOfKind(path)
+	if !ok || v.Kind() != reflect.Bool {
+		return false, false
+	}
+	return v.Bool(), true
+}
+
+// IntValue returns the integer value found at the given key path. Unsigned
+// values that do not fit in an int64 are not reported.
+func (c *Loader) IntValue(path string) (int64, bool) {
+	v, ok := c.valueOfKind(path)
+	if !ok {
+		return 0, false
+	}
+	return intValue(v)
+}
+
+// FloatValue returns the numeric value found at the given key path as a
+// float64
+func (c *Loader) FloatValue(path string) (float64, bool) {
+	v, ok := c.valueOfKind(path)
+	if !ok {
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	}
+	if i, ok := intValue(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// DurationValue returns the time.Duration value found at the given key path
+func (c *Loader) DurationValue(path string) (time.Duration, bool) {
+	v, ok := c.valueOfKind(path)
+	if !ok || v.Type() != durationType {
+		return 0, false
+	}
+	return time.Duration(v.Int()), true
+}
+
+// valueOfKind returns the value at path, dereferencing pointers and
+// interfaces
+func (c *Loader) valueOfKind(path string) (reflect.Value, bool) {
+	v, ok := lookupPath(c.Get(), path, c.keyNaming)
+	for ok && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, ok
+}
+
+// intValue returns the value of an integer kind as an int64, unless it is an
+// unsigned value larger than math.MaxInt64
+func intValue(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,179 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type changeConfig struct {
+	Log struct {
+		Level string `json:"level"`
+	} `json:"log"`
+	Port int `json:"port"`
+}
+
+type change struct {
+	path     string
+	old, new interface{}
+}
+
+func TestOnChange(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "log:\n  level: info\nport: 80\n")
+	defer cleanup()
+
+	c, err := config.NewLoader(filename, changeConfig{},
+		config.OptDebounceInterval(20*time.Millisecond))
+	assert.That(err, pred.IsNil())
+
+	changes := make(chan change, 10)
+	for _, path := range []string{"log.level", "port", "missing"} {
+		path := path
+		c.OnChange(path, func(old, new interface{}) {
+			changes <- change{path, old, new}
+		})
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(filename, []byte("log:\n  level: debug\nport: 80\n"), 0666)
+	select {
+	case ch := <-changes:
+		assert.That(ch, pred.IsEqualTo(change{"log.level", "info", "debug"}))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for change notification")
+	}
+
+	select {
+	case ch := <-changes:
+		t.Errorf("unexpected change notification, %v", ch)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+type valueConfig struct {
+	Server struct {
+		TLS *struct {
+			CertFile string `json:"cert_file"`
+		} `json:"tls"`
+		Port    int           `json:"port"`
+		Timeout time.Duration `json:"timeout"`
+		Debug   bool          `json:"debug"`
+		Ratio   float64       `json:"ratio"`
+		MaxSize uint64        `json:"max_size"`
+	} `json:"server"`
+	Backends []struct {
+		Host string `json:"host"`
+	} `json:"backends"`
+	Labels map[string]string `json:"labels"`
+	Extra  interface{}       `json:"extra"`
+}
+
+func TestValue(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, `
+server:
+  tls:
+    cert_file: /etc/cert.pem
+  port: 8080
+  timeout: 5s
+  debug: true
+  ratio: 0.5
+backends:
+  - host: a.local
+  - host: b.local
+labels:
+  team: core
+extra:
+  nested:
+    key: value
+`)
+	defer cleanup()
+
+	c, err := config.NewLoader(filename, valueConfig{})
+	assert.That(err, pred.IsNil())
+
+	v, ok := c.Value("server.tls.cert_file")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(v, pred.IsEqualTo("/etc/cert.pem"))
+
+	v, ok = c.Value("backends[1].host")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(v, pred.IsEqualTo("b.local"))
+
+	v, ok = c.Value("labels.team")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(v, pred.IsEqualTo("core"))
+
+	v, ok = c.Value("extra.nested.key")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(v, pred.IsEqualTo("value"))
+
+	for _, path := range []string{"server.missing", "backends[2].host", "labels.none", "server..port", "backends[x]"} {
+		_, ok = c.Value(path)
+		assert.That(ok, pred.IsEqualTo(false), "path: %v", path)
+	}
+}
+
+func TestTypedValues(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, `
+server:
+  tls:
+    cert_file: /etc/cert.pem
+  port: 8080
+  timeout: 5s
+  debug: true
+  ratio: 0.5
+  max_size: 18446744073709551615
+`)
+	defer cleanup()
+
+	c, err := config.NewLoader(filename, valueConfig{})
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	s, ok := c.StringValue("server.tls.cert_file")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(s, pred.IsEqualTo("/etc/cert.pem"))
+
+	i, ok := c.IntValue("server.port")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(i, pred.IsEqualTo(8080))
+
+	b, ok := c.BoolValue("server.debug")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(b, pred.IsEqualTo(true))
+
+	f, ok := c.FloatValue("server.ratio")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(f, pred.IsEqualTo(0.5))
+
+	f, ok = c.FloatValue("server.port")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(f, pred.IsEqualTo(8080.0))
+
+	d, ok := c.DurationValue("server.timeout")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(d, pred.IsEqualTo(5*time.Second))
+
+	f, ok = c.FloatValue("server.max_size")
+	assert.That(ok, pred.IsEqualTo(true))
+	assert.That(f, pred.IsEqualTo(float64(math.MaxUint64)))
+
+	_, ok = c.IntValue("server.max_size")
+	assert.That(ok, pred.IsEqualTo(false))
+	_, ok = c.IntValue("server.tls.cert_file")
+	assert.That(ok, pred.IsEqualTo(false))
+	_, ok = c.DurationValue("server.port")
+	assert.That(ok, pred.IsEqualTo(false))
+}
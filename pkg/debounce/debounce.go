@@ -5,17 +5,24 @@ The debounce logic in controlled by a debounce interval during which events
 are aggregated together, and an optional max delay that interrupts long
 streaks of events.
 
-The 4 variations provided deal with different event and aggregated event
+The variations provided deal with different event and aggregated event
 formats.
 
 All variations provide an input and an output channel. Events are fed through
 the input channel, and come out of the ouput channel after the debouncing is
 applied. Closing the input channel will close the ouput channel after any
 pending event has been propagated.
+
+By default, aggregated events are emitted at the end of each burst. With the
+WithLeading() option, the first event of a burst is also emitted immediately,
+and the trailing emission only occurs if more events were received.
 */
 package debounce
 
-import "time"
+import (
+	"math/rand"
+	"time"
+)
 
 // Event is a convience value to feed into channels of empty structs
 var Event struct{}
@@ -24,26 +31,29 @@ var Event struct{}
 // the debounce function logic, taking an empty struct{} as input values
 // and emitting a single empty struct{} per grouped input.
 func New(
-	interval, maxDelay time.Duration) (
+	interval, maxDelay time.Duration, opts ...Option) (
 	chan<- struct{}, <-chan struct{}) {
 
+	o := newOptions(opts)
 	in := make(chan struct{})
-	out := make(chan struct{})
+	out := newOutput(o, keepFirst[struct{}])
 
 	go func() {
 		var pending bool
-		var t = debounceTimers{
-			interval: interval,
-			maxDelay: maxDelay,
-		}
+		var t = newDebounceTimers(interval, maxDelay, o)
 
 	loop:
 		for {
 			select {
 			case _, ok := <-in:
+				leading := t.leadingEdge()
 				t.clearInterval()
 				if ok {
-					pending = true
+					if leading {
+						out.send(Event)
+					} else {
+						pending = true
+					}
 					t.resetInterval()
 				} else {
 					t.clearInterval()
@@ -52,13 +62,16 @@ func New(
 				t.setMaxDelay()
 
 			case <-t.intervalChan:
-				out <- Event
-				pending = false
+				if pending {
+					out.send(Event)
+					pending = false
+				}
 				t.clearMaxDelay()
+				t.clearInterval()
 
 			case <-t.maxDelayChan:
 				if pending {
-					out <- Event
+					out.send(Event)
 					pending = false
 				}
 				t.clearMaxDelay()
@@ -67,53 +80,67 @@ func New(
 		}
 
 		if pending {
-			out <- Event
+			out.send(Event)
 		}
-		close(out)
+		out.close()
 
 	}()
 
-	return in, out
+	return in, out.ch
 }
 
 // NewGrouped returns a pair of input / output channels surrounding
 // the debounce function logic, taking a generic interface{} as input values
 // and emitting lists of grouped inputs as []interface{}.
 func NewGrouped(
-	interval, maxDelay time.Duration) (
+	interval, maxDelay time.Duration, opts ...Option) (
 	chan<- interface{}, <-chan []interface{}) {
 
+	o := newOptions(opts)
 	in := make(chan interface{})
-	out := make(chan []interface{})
+	out := newOutput(o, concat[interface{}])
 
 	go func() {
 		var pending []interface{}
-		var t = debounceTimers{
-			interval: interval,
-			maxDelay: maxDelay,
-		}
+		var t = newDebounceTimers(interval, maxDelay, o)
 
 	loop:
 		for {
 			select {
 			case v, ok := <-in:
+				leading := t.leadingEdge()
 				t.clearInterval()
 				if ok {
-					pending = append(pending, v)
+					if leading {
+						out.send([]interface{}{v})
+					} else {
+						pending = append(pending, v)
+					}
 					t.resetInterval()
 				} else {
 					t.clearInterval()
 					break loop
 				}
 				t.setMaxDelay()
+				if o.groupFull(len(pending)) {
+					out.send(pending)
+					pending = nil
+					t.clearMaxDelay()
+					t.clearInterval()
+				}
 
 			case <-t.intervalChan:
-				out <- pending
+				if len(pending) != 0 {
+					out.send(pending)
+				}
 				pending = nil
 				t.clearMaxDelay()
+				t.clearInterval()
 
 			case <-t.maxDelayChan:
-				out <- pending
+				if len(pending) != 0 {
+					out.send(pending)
+				}
 				pending = nil
 				t.clearMaxDelay()
 				t.clearInterval()
@@ -121,39 +148,106 @@ func NewGrouped(
 		}
 
 		if len(pending) != 0 {
-			out <- pending
+			out.send(pending)
 		}
-		close(out)
+		out.close()
 
 	}()
 
-	return in, out
+	return in, out.ch
 }
 
 // NewLast returns a pair of input / output channels surrounding
 // the debounce function logic, taking a generic interface{} as input values
 // and emitting the last value of the grouped inputs as an interface{}.
 func NewLast(
-	interval, maxDelay time.Duration) (
+	interval, maxDelay time.Duration, opts ...Option) (
 	chan<- interface{}, <-chan interface{}) {
 
+	o := newOptions(opts)
 	in := make(chan interface{})
-	out := make(chan interface{})
+	out := newOutput(o, keepLast[interface{}])
 
 	go func() {
 		var last interface{}
-		var t = debounceTimers{
-			interval: interval,
-			maxDelay: maxDelay,
+		var pending bool
+		var t = newDebounceTimers(interval, maxDelay, o)
+
+	loop:
+		for {
+			select {
+			case v, ok := <-in:
+				leading := t.leadingEdge()
+				t.clearInterval()
+				if ok {
+					if leading {
+						out.send(v)
+					} else {
+						last, pending = v, true
+					}
+					t.resetInterval()
+				} else {
+					t.clearInterval()
+					break loop
+				}
+				t.setMaxDelay()
+
+			case <-t.intervalChan:
+				if pending {
+					out.send(last)
+				}
+				last, pending = nil, false
+				t.clearMaxDelay()
+				t.clearInterval()
+
+			case <-t.maxDelayChan:
+				if pending {
+					out.send(last)
+				}
+				last, pending = nil, false
+				t.clearMaxDelay()
+				t.clearInterval()
+			}
+		}
+
+		if pending {
+			out.send(last)
 		}
+		out.close()
+
+	}()
+
+	return in, out.ch
+}
+
+// NewFirst returns a pair of input / output channels surrounding
+// the debounce function logic, taking a generic interface{} as input values
+// and emitting the first value of the grouped inputs as an interface{}.
+func NewFirst(
+	interval, maxDelay time.Duration, opts ...Option) (
+	chan<- interface{}, <-chan interface{}) {
+
+	o := newOptions(opts)
+	in := make(chan interface{})
+	out := newOutput(o, keepFirst[interface{}])
+
+	go func() {
+		var first interface{}
+		var pending bool
+		var t = newDebounceTimers(interval, maxDelay, o)
 
 	loop:
 		for {
 			select {
 			case v, ok := <-in:
+				leading := t.leadingEdge()
 				t.clearInterval()
 				if ok {
-					last = v
+					if leading {
+						out.send(v)
+					} else if !pending {
+						first, pending = v, true
+					}
 					t.resetInterval()
 				} else {
 					t.clearInterval()
@@ -162,68 +256,85 @@ func NewLast(
 				t.setMaxDelay()
 
 			case <-t.intervalChan:
-				out <- last
-				last = nil
+				if pending {
+					out.send(first)
+				}
+				first, pending = nil, false
 				t.clearMaxDelay()
+				t.clearInterval()
 
 			case <-t.maxDelayChan:
-				if last != nil {
-					out <- last
+				if pending {
+					out.send(first)
 				}
-				last = nil
+				first, pending = nil, false
 				t.clearMaxDelay()
 				t.clearInterval()
 			}
 		}
 
-		if last != nil {
-			out <- last
+		if pending {
+			out.send(first)
 		}
-		close(out)
+		out.close()
 
 	}()
 
-	return in, out
+	return in, out.ch
 }
 
 // NewCounted returns a pair of input / output channels surrounding
 // the debounce function logic, taking an empty struct{} as input values
 // and emitting the number of grouped inputs as an int
 func NewCounted(
-	interval, maxDelay time.Duration) (
+	interval, maxDelay time.Duration, opts ...Option) (
 	chan<- struct{}, <-chan int) {
 
+	o := newOptions(opts)
 	in := make(chan struct{})
-	out := make(chan int)
+	out := newOutput(o, sum)
 
 	go func() {
 		var count int
-		var t = debounceTimers{
-			interval: interval,
-			maxDelay: maxDelay,
-		}
+		var t = newDebounceTimers(interval, maxDelay, o)
 
 	loop:
 		for {
 			select {
 			case _, ok := <-in:
+				leading := t.leadingEdge()
 				t.clearInterval()
 				if ok {
-					count++
+					if leading {
+						out.send(1)
+					} else {
+						count++
+					}
 					t.resetInterval()
 				} else {
 					t.clearInterval()
 					break loop
 				}
 				t.setMaxDelay()
+				if o.groupFull(count) {
+					out.send(count)
+					count = 0
+					t.clearMaxDelay()
+					t.clearInterval()
+				}
 
 			case <-t.intervalChan:
-				out <- count
+				if count != 0 {
+					out.send(count)
+				}
 				count = 0
 				t.clearMaxDelay()
+				t.clearInterval()
 
 			case <-t.maxDelayChan:
-				out <- count
+				if count != 0 {
+					out.send(count)
+				}
 				count = 0
 				t.clearMaxDelay()
 				t.clearInterval()
@@ -231,13 +342,79 @@ func NewCounted(
 		}
 
 		if count != 0 {
-			out <- count
+			out.send(count)
 		}
-		close(out)
+		out.close()
 
 	}()
 
-	return in, out
+	return in, out.ch
+}
+
+// NewReduce returns a pair of input / output channels surrounding
+// the debounce function logic, taking a generic interface{} as input values
+// and emitting the result of folding the grouped inputs with reduce. The
+// accumulator passed to reduce is nil for the first input of each group.
+func NewReduce(
+	interval, maxDelay time.Duration,
+	reduce func(acc, v interface{}) interface{}, opts ...Option) (
+	chan<- interface{}, <-chan interface{}) {
+
+	o := newOptions(opts)
+	in := make(chan interface{})
+	out := newOutput(o, keepLast[interface{}])
+
+	go func() {
+		var acc interface{}
+		var pending bool
+		var t = newDebounceTimers(interval, maxDelay, o)
+
+	loop:
+		for {
+			select {
+			case v, ok := <-in:
+				leading := t.leadingEdge()
+				t.clearInterval()
+				if ok {
+					if leading {
+						out.send(reduce(nil, v))
+					} else {
+						acc = reduce(acc, v)
+						pending = true
+					}
+					t.resetInterval()
+				} else {
+					t.clearInterval()
+					break loop
+				}
+				t.setMaxDelay()
+
+			case <-t.intervalChan:
+				if pending {
+					out.send(acc)
+				}
+				acc, pending = nil, false
+				t.clearMaxDelay()
+				t.clearInterval()
+
+			case <-t.maxDelayChan:
+				if pending {
+					out.send(acc)
+				}
+				acc, pending = nil, false
+				t.clearMaxDelay()
+				t.clearInterval()
+			}
+		}
+
+		if pending {
+			out.send(acc)
+		}
+		out.close()
+
+	}()
+
+	return in, out.ch
 }
 
 // ---------------------------------------------------------------------------
@@ -247,14 +424,47 @@ func NewCounted(
 type debounceTimers struct {
 	interval      time.Duration
 	maxDelay      time.Duration
+	leading       bool
+	jitter        float64
 	intervalTimer *time.Timer
 	intervalChan  <-chan time.Time
 	maxDelayTimer *time.Timer
 	maxDelayChan  <-chan time.Time
 }
 
+func newDebounceTimers(
+	interval, maxDelay time.Duration, o options) debounceTimers {
+
+	return debounceTimers{
+		interval: interval,
+		maxDelay: maxDelay,
+		leading:  o.leading,
+		jitter:   o.jitter,
+	}
+}
+
+// idle returns true if no burst of events is currently in progress.
+func (t *debounceTimers) idle() bool {
+	return t.intervalTimer == nil && t.maxDelayTimer == nil
+}
+
+// leadingEdge returns true if leading emission is enabled and no burst is
+// currently in progress, i.e. if the next input should be emitted right away.
+func (t *debounceTimers) leadingEdge() bool {
+	return t.leading && t.idle()
+}
+
+// jittered returns d increased by a random fraction of itself, up to the
+// configured jitter.
+func (t *debounceTimers) jittered(d time.Duration) time.Duration {
+	if t.jitter <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Float64()*t.jitter*float64(d))
+}
+
 func (t *debounceTimers) resetInterval() {
-	t.intervalTimer = time.NewTimer(t.interval)
+	t.intervalTimer = time.NewTimer(t.jittered(t.interval))
 	t.intervalChan = t.intervalTimer.C
 }
 
@@ -270,7 +480,7 @@ func (t *debounceTimers) clearInterval() {
 
 func (t *debounceTimers) setMaxDelay() {
 	if t.maxDelayTimer == nil && t.maxDelay != 0 {
-		t.maxDelayTimer = time.NewTimer(t.maxDelay)
+		t.maxDelayTimer = time.NewTimer(t.jittered(t.maxDelay))
 		t.maxDelayChan = t.maxDelayTimer.C
 	}
 }
@@ -218,6 +218,66 @@ func TestLastWithNoMax(t *testing.T) {
 	assert.That(r, pred.IsEqualTo([]int{9, 19}))
 }
 
+func TestLastWithNilValues(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewLast(5*time.Millisecond, 0)
+
+	go func() {
+		in <- 1
+		in <- nil
+		time.Sleep(20 * time.Millisecond)
+		in <- nil
+		close(in)
+	}()
+
+	r := drainLast(out)
+	assert.That(r, pred.Length(pred.IsEqualTo(2)))
+	for i, v := range r {
+		if v != nil {
+			t.Errorf("expected nil value at index %v, got %v", i, v)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// debounce.NewFirst()
+// ---------------------------------------------------------------------------
+
+func TestFirstEmpty(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewFirst(2*time.Millisecond, 20*time.Millisecond)
+	close(in)
+
+	r := drainLast(out)
+	assert.That(r, pred.IsEmpty())
+}
+
+func TestFirstWithNoMax(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewFirst(5*time.Millisecond, 0)
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 5; i < 10; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+		close(in)
+	}()
+
+	r := drainLast(out)
+	assert.That(r, pred.IsEqualTo([]interface{}{0, 5}))
+}
+
 // ---------------------------------------------------------------------------
 // debounce.NewCounted()
 // ---------------------------------------------------------------------------
@@ -288,3 +348,170 @@ func TestCountedWithNoMax(t *testing.T) {
 	r := drainCounted(out)
 	assert.That(r, pred.IsEqualTo([]int{10, 10}))
 }
+
+// ---------------------------------------------------------------------------
+// debounce.WithLeading()
+// ---------------------------------------------------------------------------
+
+func TestLeadingWithSingleEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(5*time.Millisecond, 0, debounce.WithLeading())
+
+	go func() {
+		in <- 1
+		time.Sleep(20 * time.Millisecond)
+		in <- 2
+		close(in)
+	}()
+
+	r := drainGrouped(out)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{1}, {2}}))
+}
+
+func TestLeadingWithBursts(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(5*time.Millisecond, 0, debounce.WithLeading())
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 3; i < 6; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+		close(in)
+	}()
+
+	r := drainGrouped(out)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{0}, {1, 2}, {3}, {4, 5}}))
+}
+
+func TestLeadingCounted(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewCounted(5*time.Millisecond, 0, debounce.WithLeading())
+
+	go func() {
+		for i := 0; i < 10; i++ {
+			in <- debounce.Event
+			time.Sleep(1 * time.Millisecond)
+		}
+		close(in)
+	}()
+
+	r := drainCounted(out)
+	assert.That(r, pred.IsEqualTo([]int{1, 9}))
+}
+
+// ---------------------------------------------------------------------------
+// debounce.NewReduce()
+// ---------------------------------------------------------------------------
+
+func maxReduce(acc, v interface{}) interface{} {
+	if acc == nil || v.(int) > acc.(int) {
+		return v
+	}
+	return acc
+}
+
+func TestReduceEmpty(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewReduce(2*time.Millisecond, 20*time.Millisecond, maxReduce)
+	close(in)
+
+	r := drainLast(out)
+	assert.That(r, pred.IsEmpty())
+}
+
+func TestReduceWithNoMax(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewReduce(5*time.Millisecond, 0, maxReduce)
+
+	go func() {
+		for _, v := range []int{3, 7, 2} {
+			in <- v
+			time.Sleep(1 * time.Millisecond)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		for _, v := range []int{1, 4} {
+			in <- v
+			time.Sleep(1 * time.Millisecond)
+		}
+		close(in)
+	}()
+
+	r := drainLast(out)
+	assert.That(r, pred.IsEqualTo([]interface{}{7, 4}))
+}
+
+// ---------------------------------------------------------------------------
+// debounce.WithMaxGroupSize()
+// ---------------------------------------------------------------------------
+
+func TestGroupedWithMaxGroupSize(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(time.Hour, 0, debounce.WithMaxGroupSize(3))
+
+	go func() {
+		for i := 0; i < 7; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+
+	r := drainGrouped(out)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{0, 1, 2}, {3, 4, 5}, {6}}))
+}
+
+func TestCountedWithMaxGroupSize(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewCounted(time.Hour, 0, debounce.WithMaxGroupSize(4))
+
+	go func() {
+		for i := 0; i < 10; i++ {
+			in <- debounce.Event
+		}
+		close(in)
+	}()
+
+	r := drainCounted(out)
+	assert.That(r, pred.IsEqualTo([]int{4, 4, 2}))
+}
+
+// ---------------------------------------------------------------------------
+// debounce.WithJitter()
+// ---------------------------------------------------------------------------
+
+func TestWithJitter(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.New(20*time.Millisecond, 0, debounce.WithJitter(1))
+
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		start := time.Now()
+		in <- debounce.Event
+		<-out
+		delays = append(delays, time.Since(start))
+	}
+	close(in)
+	drain(out)
+
+	for _, d := range delays {
+		assert.That(int64(d), pred.Ge(int64(20*time.Millisecond)))
+		assert.That(int64(d), pred.Lt(int64(time.Second)))
+	}
+}
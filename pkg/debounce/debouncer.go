@@ -0,0 +1,175 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer applies the same debounce logic as New(), but its interval and
+// max delay can be adjusted while it is running. New settings take effect at
+// the start of the next burst of events.
+type Debouncer struct {
+	in       chan struct{}
+	priority chan struct{}
+	out      *output[struct{}]
+	flush    chan struct{}
+	done     chan struct{}
+	stats    statsCounter
+
+	mu       sync.Mutex
+	interval time.Duration
+	maxDelay time.Duration
+}
+
+// NewDebouncer creates and starts a new Debouncer. Events are fed through
+// the Input() channel and come out of the Output() channel; closing the input
+// channel terminates the debouncer.
+func NewDebouncer(
+	interval, maxDelay time.Duration, opts ...Option) *Debouncer {
+
+	o := newOptions(opts)
+	d := &Debouncer{
+		in:       make(chan struct{}),
+		priority: make(chan struct{}),
+		out:      newOutput(o, keepFirst[struct{}]),
+		flush:    make(chan struct{}),
+		done:     make(chan struct{}),
+		interval: interval,
+		maxDelay: maxDelay,
+	}
+	go d.run(newDebounceTimers(interval, maxDelay, o))
+	return d
+}
+
+// Input returns the input channel of the debouncer.
+func (d *Debouncer) Input() chan<- struct{} {
+	return d.in
+}
+
+// Priority returns a high-priority input channel. Events fed through it skip
+// the quiet period and are emitted immediately, together with any pending
+// event. Like the input channel, it must not be used once the input channel
+// is closed.
+func (d *Debouncer) Priority() chan<- struct{} {
+	return d.priority
+}
+
+// Output returns the output channel of the debouncer.
+func (d *Debouncer) Output() <-chan struct{} {
+	return d.out.ch
+}
+
+// Flush forces the immediate emission of any pending event, ending the
+// current burst without waiting for the interval to expire. It has no effect
+// if nothing is pending or once the debouncer has terminated.
+func (d *Debouncer) Flush() {
+	select {
+	case d.flush <- Event:
+	case <-d.done:
+	}
+}
+
+// Stats returns a snapshot of the debouncer counters.
+func (d *Debouncer) Stats() Stats {
+	return d.stats.get()
+}
+
+// Interval returns the current debounce interval.
+func (d *Debouncer) Interval() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.interval
+}
+
+// MaxDelay returns the current max delay.
+func (d *Debouncer) MaxDelay() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.maxDelay
+}
+
+// SetInterval changes the debounce interval, starting with the next burst.
+func (d *Debouncer) SetInterval(interval time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.interval = interval
+}
+
+// SetMaxDelay changes the max delay, starting with the next burst. A value
+// of 0 disables the max delay.
+func (d *Debouncer) SetMaxDelay(maxDelay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.maxDelay = maxDelay
+}
+
+func (d *Debouncer) run(t debounceTimers) {
+	defer close(d.done)
+	var pending bool
+
+loop:
+	for {
+		select {
+		case _, ok := <-d.in:
+			if t.idle() {
+				t.interval, t.maxDelay = d.Interval(), d.MaxDelay()
+			}
+			leading := t.leadingEdge()
+			t.clearInterval()
+			if ok {
+				d.stats.received()
+				if leading {
+					d.emit()
+				} else {
+					pending = true
+				}
+				t.resetInterval()
+			} else {
+				t.clearInterval()
+				break loop
+			}
+			t.setMaxDelay()
+
+		case <-t.intervalChan:
+			if pending {
+				d.emit()
+				pending = false
+			}
+			t.clearMaxDelay()
+			t.clearInterval()
+
+		case <-t.maxDelayChan:
+			if pending {
+				d.emit()
+				pending = false
+			}
+			t.clearMaxDelay()
+			t.clearInterval()
+
+		case <-d.flush:
+			if pending {
+				d.emit()
+				pending = false
+			}
+			t.clearMaxDelay()
+			t.clearInterval()
+
+		case <-d.priority:
+			d.stats.received()
+			d.emit()
+			pending = false
+			t.clearMaxDelay()
+			t.clearInterval()
+		}
+	}
+
+	if pending {
+		d.emit()
+	}
+	d.out.close()
+}
+
+func (d *Debouncer) emit() {
+	d.out.send(Event)
+	d.stats.emitted()
+}
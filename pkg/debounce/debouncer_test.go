@@ -0,0 +1,146 @@
+package debounce_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// debounce.NewDebouncer()
+// ---------------------------------------------------------------------------
+
+func TestDebouncerEmpty(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(2*time.Millisecond, 20*time.Millisecond)
+	close(d.Input())
+
+	r := drain(d.Output())
+	assert.That(r, pred.IsEqualTo(0))
+}
+
+func TestDebouncerSetInterval(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(time.Hour, 0)
+	assert.That(d.Interval(), pred.IsEqualTo(time.Hour))
+
+	d.SetInterval(5 * time.Millisecond)
+	assert.That(d.Interval(), pred.IsEqualTo(5*time.Millisecond))
+
+	start := time.Now()
+	d.Input() <- debounce.Event
+	<-d.Output()
+	assert.That(int64(time.Since(start)), pred.Lt(int64(time.Second)))
+
+	close(d.Input())
+	assert.That(drain(d.Output()), pred.IsEqualTo(0))
+}
+
+func TestDebouncerSetIntervalDuringBurst(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(20*time.Millisecond, 0)
+
+	start := time.Now()
+	d.Input() <- debounce.Event
+	d.SetInterval(time.Hour)
+	<-d.Output()
+	assert.That(int64(time.Since(start)), pred.Lt(int64(time.Second)))
+	close(d.Input())
+}
+
+func TestDebouncerSetMaxDelay(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(time.Hour, 0)
+	d.SetMaxDelay(20 * time.Millisecond)
+	assert.That(d.MaxDelay(), pred.IsEqualTo(20*time.Millisecond))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 60; i++ {
+			d.Input() <- debounce.Event
+			time.Sleep(1 * time.Millisecond)
+		}
+		close(d.Input())
+	}()
+
+	count := drain(d.Output())
+	<-done
+	assert.That(count, pred.Gt(1))
+}
+
+func TestDebouncerFlush(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(time.Hour, 0)
+
+	d.Flush()
+	d.Input() <- debounce.Event
+	d.Input() <- debounce.Event
+
+	start := time.Now()
+	go d.Flush()
+	<-d.Output()
+	assert.That(int64(time.Since(start)), pred.Lt(int64(time.Second)))
+
+	close(d.Input())
+	assert.That(drain(d.Output()), pred.IsEqualTo(0))
+	d.Flush()
+}
+
+func TestDebouncerStats(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(time.Hour, 0)
+	assert.That(d.Stats(), pred.IsEqualTo(debounce.Stats{}))
+
+	d.Input() <- debounce.Event
+	d.Input() <- debounce.Event
+	d.Input() <- debounce.Event
+	time.Sleep(10 * time.Millisecond)
+	assert.That(d.Stats(), pred.IsEqualTo(debounce.Stats{
+		EventsIn: 3,
+		Pending:  3,
+	}))
+
+	go d.Flush()
+	<-d.Output()
+	d.Input() <- debounce.Event
+	close(d.Input())
+	drain(d.Output())
+
+	assert.That(d.Stats(), pred.IsEqualTo(debounce.Stats{
+		EventsIn:     4,
+		EmissionsOut: 2,
+		MaxBurstSize: 3,
+	}))
+}
+
+func TestDebouncerPriority(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	d := debounce.NewDebouncer(time.Hour, 0)
+
+	d.Input() <- debounce.Event
+	d.Input() <- debounce.Event
+
+	start := time.Now()
+	go func() { d.Priority() <- debounce.Event }()
+	<-d.Output()
+	assert.That(int64(time.Since(start)), pred.Lt(int64(time.Second)))
+
+	go func() { d.Priority() <- debounce.Event }()
+	<-d.Output()
+
+	close(d.Input())
+	assert.That(drain(d.Output()), pred.IsEqualTo(0))
+	assert.That(d.Stats().EmissionsOut, pred.IsEqualTo(uint64(2)))
+}
@@ -0,0 +1,141 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// DebouncedFunc is the handle returned by Func(), used to trigger and stop
+// the debounced function.
+type DebouncedFunc struct {
+	trigger  chan struct{}
+	priority chan struct{}
+	flush    chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	fn       func()
+	stats    statsCounter
+}
+
+// Func returns a handle that debounces calls to fn: each call to Trigger()
+// feeds an event into the debounce logic, and fn is invoked once per grouped
+// burst of triggers, from a goroutine managed by the handle.
+func Func(
+	interval, maxDelay time.Duration, fn func(), opts ...Option) *DebouncedFunc {
+
+	f := &DebouncedFunc{
+		trigger:  make(chan struct{}),
+		priority: make(chan struct{}),
+		flush:    make(chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		fn:       fn,
+	}
+	go f.run(newDebounceTimers(interval, maxDelay, newOptions(opts)))
+	return f
+}
+
+// Trigger requests a call to the debounced function. It has no effect once
+// the handle is stopped.
+func (f *DebouncedFunc) Trigger() {
+	select {
+	case f.trigger <- Event:
+	case <-f.stop:
+	}
+}
+
+// TriggerNow requests an immediate call to the debounced function, skipping
+// the quiet period and absorbing any pending call. It has no effect once the
+// handle is stopped.
+func (f *DebouncedFunc) TriggerNow() {
+	select {
+	case f.priority <- Event:
+	case <-f.stop:
+	}
+}
+
+// Flush forces any pending call to be made immediately, without waiting for
+// the interval to expire. Calling Flush() followed by Stop() ensures that the
+// last trigger is not lost on shutdown.
+func (f *DebouncedFunc) Flush() {
+	select {
+	case f.flush <- Event:
+	case <-f.stop:
+	}
+}
+
+// Stop stops the debouncer, dropping any pending call, and waits for a call
+// in progress to complete. It must not be called from the debounced function
+// itself.
+func (f *DebouncedFunc) Stop() {
+	f.once.Do(func() { close(f.stop) })
+	<-f.done
+}
+
+// Stats returns a snapshot of the debouncer counters.
+func (f *DebouncedFunc) Stats() Stats {
+	return f.stats.get()
+}
+
+func (f *DebouncedFunc) run(t debounceTimers) {
+	defer close(f.done)
+	defer t.clearMaxDelay()
+	defer t.clearInterval()
+
+	var pending bool
+	for {
+		select {
+		case <-f.stop:
+			return
+
+		case <-f.trigger:
+			f.stats.received()
+			leading := t.leadingEdge()
+			t.clearInterval()
+			if leading {
+				f.call()
+			} else {
+				pending = true
+			}
+			t.resetInterval()
+			t.setMaxDelay()
+
+		case <-t.intervalChan:
+			if pending {
+				f.call()
+				pending = false
+			}
+			t.clearMaxDelay()
+			t.clearInterval()
+
+		case <-t.maxDelayChan:
+			if pending {
+				f.call()
+				pending = false
+			}
+			t.clearMaxDelay()
+			t.clearInterval()
+
+		case <-f.flush:
+			if pending {
+				f.call()
+				pending = false
+			}
+			t.clearMaxDelay()
+			t.clearInterval()
+
+		case <-f.priority:
+			f.stats.received()
+			f.call()
+			pending = false
+			t.clearMaxDelay()
+			t.clearInterval()
+		}
+	}
+}
+
+func (f *DebouncedFunc) call() {
+	f.fn()
+	f.stats.emitted()
+}
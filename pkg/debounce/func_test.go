@@ -0,0 +1,123 @@
+package debounce_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// debounce.Func()
+// ---------------------------------------------------------------------------
+
+func TestFunc(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var calls int32
+	f := debounce.Func(5*time.Millisecond, 0, func() {
+		atomic.AddInt32(&calls, 1)
+	})
+	defer f.Stop()
+
+	for i := 0; i < 10; i++ {
+		f.Trigger()
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.That(atomic.LoadInt32(&calls), pred.IsEqualTo(int32(1)))
+
+	f.Trigger()
+	time.Sleep(50 * time.Millisecond)
+	assert.That(atomic.LoadInt32(&calls), pred.IsEqualTo(int32(2)))
+}
+
+func TestFuncWithLeading(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	called := make(chan struct{}, 10)
+	f := debounce.Func(time.Hour, 0, func() {
+		called <- debounce.Event
+	}, debounce.WithLeading())
+	defer f.Stop()
+
+	f.Trigger()
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for leading call")
+	}
+	f.Trigger()
+	time.Sleep(20 * time.Millisecond)
+	assert.That(called, pred.Length(pred.IsEqualTo(0)))
+}
+
+func TestFuncStopDropsPendingCall(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var calls int32
+	f := debounce.Func(20*time.Millisecond, 0, func() {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	f.Trigger()
+	f.Stop()
+	f.Stop()
+	f.Trigger()
+	time.Sleep(50 * time.Millisecond)
+	assert.That(atomic.LoadInt32(&calls), pred.IsEqualTo(int32(0)))
+}
+
+func TestFuncFlush(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var calls int32
+	f := debounce.Func(time.Hour, 0, func() {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	f.Flush()
+	f.Trigger()
+	f.Trigger()
+	f.Flush()
+	f.Stop()
+	f.Flush()
+	assert.That(atomic.LoadInt32(&calls), pred.IsEqualTo(int32(1)))
+}
+
+func TestFuncStats(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	f := debounce.Func(time.Hour, 0, func() {}, debounce.WithLeading())
+
+	f.Trigger()
+	f.Trigger()
+	f.Trigger()
+	f.Flush()
+	f.Stop()
+
+	assert.That(f.Stats(), pred.IsEqualTo(debounce.Stats{
+		EventsIn:     3,
+		EmissionsOut: 2,
+		MaxBurstSize: 2,
+	}))
+}
+
+func TestFuncTriggerNow(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var calls int32
+	f := debounce.Func(time.Hour, 0, func() {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	f.Trigger()
+	f.TriggerNow()
+	f.TriggerNow()
+	f.Stop()
+	f.TriggerNow()
+	assert.That(atomic.LoadInt32(&calls), pred.IsEqualTo(int32(2)))
+}
@@ -0,0 +1,135 @@
+package debounce
+
+import "time"
+
+// NewOf returns a pair of typed input / output channels surrounding the
+// debounce function logic, emitting the last value of the grouped inputs. It
+// is the typed equivalent of NewLast, without boxing values into interface{}.
+func NewOf[T any](
+	interval, maxDelay time.Duration, opts ...Option) (
+	chan<- T, <-chan T) {
+
+	o := newOptions(opts)
+	in := make(chan T)
+	out := newOutput(o, keepLast[T])
+
+	go func() {
+		var last, zero T
+		var pending bool
+		var t = newDebounceTimers(interval, maxDelay, o)
+
+	loop:
+		for {
+			select {
+			case v, ok := <-in:
+				leading := t.leadingEdge()
+				t.clearInterval()
+				if ok {
+					if leading {
+						out.send(v)
+					} else {
+						last = v
+						pending = true
+					}
+					t.resetInterval()
+				} else {
+					t.clearInterval()
+					break loop
+				}
+				t.setMaxDelay()
+
+			case <-t.intervalChan:
+				if pending {
+					out.send(last)
+				}
+				last, pending = zero, false
+				t.clearMaxDelay()
+				t.clearInterval()
+
+			case <-t.maxDelayChan:
+				if pending {
+					out.send(last)
+				}
+				last, pending = zero, false
+				t.clearMaxDelay()
+				t.clearInterval()
+			}
+		}
+
+		if pending {
+			out.send(last)
+		}
+		out.close()
+
+	}()
+
+	return in, out.ch
+}
+
+// NewGroupedOf returns a pair of typed input / output channels surrounding
+// the debounce function logic, emitting lists of grouped inputs. It is the
+// typed equivalent of NewGrouped.
+func NewGroupedOf[T any](
+	interval, maxDelay time.Duration, opts ...Option) (
+	chan<- T, <-chan []T) {
+
+	o := newOptions(opts)
+	in := make(chan T)
+	out := newOutput(o, concat[T])
+
+	go func() {
+		var pending []T
+		var t = newDebounceTimers(interval, maxDelay, o)
+
+	loop:
+		for {
+			select {
+			case v, ok := <-in:
+				leading := t.leadingEdge()
+				t.clearInterval()
+				if ok {
+					if leading {
+						out.send([]T{v})
+					} else {
+						pending = append(pending, v)
+					}
+					t.resetInterval()
+				} else {
+					t.clearInterval()
+					break loop
+				}
+				t.setMaxDelay()
+				if o.groupFull(len(pending)) {
+					out.send(pending)
+					pending = nil
+					t.clearMaxDelay()
+					t.clearInterval()
+				}
+
+			case <-t.intervalChan:
+				if len(pending) != 0 {
+					out.send(pending)
+				}
+				pending = nil
+				t.clearMaxDelay()
+				t.clearInterval()
+
+			case <-t.maxDelayChan:
+				if len(pending) != 0 {
+					out.send(pending)
+				}
+				pending = nil
+				t.clearMaxDelay()
+				t.clearInterval()
+			}
+		}
+
+		if len(pending) != 0 {
+			out.send(pending)
+		}
+		out.close()
+
+	}()
+
+	return in, out.ch
+}
@@ -0,0 +1,110 @@
+package debounce_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// debounce.NewOf()
+// ---------------------------------------------------------------------------
+
+func drainOf[T any](c <-chan T) (r []T) {
+	for v := range c {
+		r = append(r, v)
+	}
+	return
+}
+
+func TestOfEmpty(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewOf[string](2*time.Millisecond, 20*time.Millisecond)
+	close(in)
+
+	r := drainOf(out)
+	assert.That(r, pred.Length(pred.IsEqualTo(0)))
+}
+
+func TestOfWithNoMax(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewOf[int](5*time.Millisecond, 0)
+
+	go func() {
+		for i := 0; i < 10; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 10; i < 20; i++ {
+			time.Sleep(1 * time.Millisecond)
+			in <- i
+		}
+		close(in)
+	}()
+
+	r := drainOf(out)
+	assert.That(r, pred.IsEqualTo([]int{9, 19}))
+}
+
+// ---------------------------------------------------------------------------
+// debounce.NewGroupedOf()
+// ---------------------------------------------------------------------------
+
+func TestGroupedOfEmpty(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGroupedOf[string](2*time.Millisecond, 20*time.Millisecond)
+	close(in)
+
+	r := drainOf(out)
+	assert.That(r, pred.Length(pred.IsEqualTo(0)))
+}
+
+func TestGroupedOfWithNoMax(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGroupedOf[int](5*time.Millisecond, 0)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 3; i < 5; i++ {
+			time.Sleep(1 * time.Millisecond)
+			in <- i
+		}
+		close(in)
+	}()
+
+	r := drainOf(out)
+	assert.That(r, pred.IsEqualTo([][]int{{0, 1, 2}, {3, 4}}))
+}
+
+func TestGroupedOfWithMaxGroupSize(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGroupedOf[int](time.Hour, 0, debounce.WithMaxGroupSize(2))
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+
+	r := drainOf(out)
+	assert.That(r, pred.IsEqualTo([][]int{{0, 1}, {2, 3}, {4}}))
+}
@@ -0,0 +1,55 @@
+package debounce
+
+// Option is a functional option that can be passed to the debounce
+// constructors.
+type Option func(*options)
+
+type options struct {
+	leading      bool
+	outputBuffer int
+	outputPolicy OutputPolicy
+	maxGroupSize int
+	jitter       float64
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithLeading causes the first input of a burst to be emitted immediately,
+// on its own. The trailing emission at the end of the burst still takes
+// place, but only if additional inputs were received after the first one.
+func WithLeading() Option {
+	return func(o *options) {
+		o.leading = true
+	}
+}
+
+// WithMaxGroupSize causes grouped and counted debouncers to emit immediately
+// once n events are pending, regardless of timers, which bounds the memory
+// used by high-rate event sources. It has no effect on other debouncers.
+func WithMaxGroupSize(n int) Option {
+	return func(o *options) {
+		o.maxGroupSize = n
+	}
+}
+
+// WithJitter adds a random extra delay, of up to the given fraction of the
+// interval and max delay, each time one of the timers is started. This
+// spreads out the emissions of many instances debouncing the same external
+// events, e.g. a distributed config, so they don't all react at the same
+// instant.
+func WithJitter(fraction float64) Option {
+	return func(o *options) {
+		o.jitter = fraction
+	}
+}
+
+// groupFull returns true if a group of n events reached the max group size.
+func (o *options) groupFull(n int) bool {
+	return o.maxGroupSize > 0 && n >= o.maxGroupSize
+}
@@ -0,0 +1,116 @@
+package debounce
+
+// OutputPolicy defines how a debouncer delivers values on its output channel
+// when the consumer is not keeping up.
+type OutputPolicy int
+
+const (
+	// Block waits for the consumer to receive each value, stalling the
+	// debounce logic in the meantime. This is the default.
+	Block OutputPolicy = iota
+
+	// DropOldest discards the oldest buffered value to make room for the
+	// new one.
+	DropOldest
+
+	// DropNewest discards the new value when the buffer is full.
+	DropNewest
+
+	// Coalesce merges all buffered values with the new one into a single
+	// value, the same way events are aggregated within a burst. NewReduce()
+	// does not know how to merge two accumulated values and keeps the newest
+	// one instead.
+	Coalesce
+)
+
+// WithOutputBuffer sets the size of the output channel buffer and the policy
+// applied when it is full. With any policy other than Block, the debounce
+// logic never waits on the consumer, and the buffer size is at least 1.
+func WithOutputBuffer(size int, policy OutputPolicy) Option {
+	return func(o *options) {
+		o.outputBuffer = size
+		o.outputPolicy = policy
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Output channel delivery
+// ---------------------------------------------------------------------------
+
+type output[T any] struct {
+	ch     chan T
+	policy OutputPolicy
+	merge  func(a, b T) T
+}
+
+func newOutput[T any](o options, merge func(a, b T) T) *output[T] {
+	size := o.outputBuffer
+	if o.outputPolicy != Block && size < 1 {
+		size = 1
+	}
+	return &output[T]{
+		ch:     make(chan T, size),
+		policy: o.outputPolicy,
+		merge:  merge,
+	}
+}
+
+func (o *output[T]) send(v T) {
+	if o.policy == Block {
+		o.ch <- v
+		return
+	}
+
+	for {
+		select {
+		case o.ch <- v:
+			return
+		default:
+		}
+
+		switch o.policy {
+		case DropNewest:
+			return
+		case DropOldest:
+			select {
+			case <-o.ch:
+			default:
+			}
+		case Coalesce:
+			v = o.coalesce(v)
+		}
+	}
+}
+
+// coalesce drains the buffered values and merges them, in order, with v.
+func (o *output[T]) coalesce(v T) T {
+	var buffered []T
+	for {
+		select {
+		case prev := <-o.ch:
+			buffered = append(buffered, prev)
+		default:
+			if len(buffered) == 0 {
+				return v
+			}
+			acc := buffered[0]
+			for _, prev := range buffered[1:] {
+				acc = o.merge(acc, prev)
+			}
+			return o.merge(acc, v)
+		}
+	}
+}
+
+func (o *output[T]) close() {
+	close(o.ch)
+}
+
+// Merge functions used to coalesce buffered values
+
+func keepFirst[T any](a, b T) T { return a }
+func keepLast[T any](a, b T) T  { return b }
+func concat[T any](a, b []T) []T {
+	return append(a[:len(a):len(a)], b...)
+}
+func sum(a, b int) int { return a + b }
@@ -0,0 +1,86 @@
+package debounce_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// debounce.WithOutputBuffer()
+// ---------------------------------------------------------------------------
+
+// feedSeparateBursts sends each value as a separate burst without reading the
+// output, then drains it.
+func feedSeparateBursts(
+	in chan<- interface{}, out <-chan []interface{},
+	values ...interface{}) [][]interface{} {
+
+	for _, v := range values {
+		in <- v
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(in)
+	return drainGrouped(out)
+}
+
+func TestOutputBufferWithBlock(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(2*time.Millisecond, 0,
+		debounce.WithOutputBuffer(2, debounce.Block))
+
+	r := feedSeparateBursts(in, out, 1, 2)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{1}, {2}}))
+}
+
+func TestOutputBufferWithDropOldest(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(2*time.Millisecond, 0,
+		debounce.WithOutputBuffer(1, debounce.DropOldest))
+
+	r := feedSeparateBursts(in, out, 1, 2, 3)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{3}}))
+}
+
+func TestOutputBufferWithDropNewest(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(2*time.Millisecond, 0,
+		debounce.WithOutputBuffer(1, debounce.DropNewest))
+
+	r := feedSeparateBursts(in, out, 1, 2, 3)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{1}}))
+}
+
+func TestOutputBufferWithCoalesce(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewGrouped(2*time.Millisecond, 0,
+		debounce.WithOutputBuffer(2, debounce.Coalesce))
+
+	r := feedSeparateBursts(in, out, 1, 2, 3, 4)
+	assert.That(r, pred.IsEqualTo([][]interface{}{{1, 2, 3}, {4}}))
+}
+
+func TestOutputBufferWithCoalesceCounted(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewCounted(2*time.Millisecond, 0,
+		debounce.WithOutputBuffer(0, debounce.Coalesce))
+
+	for i := 0; i < 3; i++ {
+		in <- debounce.Event
+		in <- debounce.Event
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(in)
+
+	r := drainCounted(out)
+	assert.That(r, pred.IsEqualTo([]int{6}))
+}
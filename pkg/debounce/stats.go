@@ -0,0 +1,39 @@
+package debounce
+
+import "sync"
+
+// Stats holds diagnostic counters about a debouncer.
+type Stats struct {
+	EventsIn     uint64 // Total number of events received
+	EmissionsOut uint64 // Total number of aggregated events emitted
+	MaxBurstSize int    // Largest number of events aggregated into one emission
+	Pending      int    // Number of events received but not yet emitted
+}
+
+type statsCounter struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func (c *statsCounter) received() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.EventsIn++
+	c.stats.Pending++
+}
+
+func (c *statsCounter) emitted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.EmissionsOut++
+	if c.stats.Pending > c.stats.MaxBurstSize {
+		c.stats.MaxBurstSize = c.stats.Pending
+	}
+	c.stats.Pending = 0
+}
+
+func (c *statsCounter) get() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,62 @@
+package debounce
+
+import "time"
+
+// NewThrottle returns a pair of input / output channels surrounding the
+// throttle logic, emitting at most one value per minInterval. The first value
+// is emitted immediately; values received during the following interval are
+// collapsed into the most recent one, emitted when the interval expires.
+// Unlike the debounce functions, a continuous stream of inputs results in a
+// steady stream of outputs instead of waiting for a quiet period.
+func NewThrottle(
+	minInterval time.Duration) (
+	chan<- interface{}, <-chan interface{}) {
+
+	in := make(chan interface{})
+	out := make(chan interface{})
+
+	go func() {
+		var last interface{}
+		var pending bool
+		var timer *time.Timer
+		var timerChan <-chan time.Time
+
+	loop:
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					break loop
+				}
+				if timer == nil {
+					out <- v
+					timer = time.NewTimer(minInterval)
+					timerChan = timer.C
+				} else {
+					last, pending = v, true
+				}
+
+			case <-timerChan:
+				if pending {
+					out <- last
+					last, pending = nil, false
+					timer.Reset(minInterval)
+				} else {
+					timer = nil
+					timerChan = nil
+				}
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if pending {
+			out <- last
+		}
+		close(out)
+
+	}()
+
+	return in, out
+}
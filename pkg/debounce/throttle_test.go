@@ -0,0 +1,64 @@
+package debounce_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// debounce.NewThrottle()
+// ---------------------------------------------------------------------------
+
+func TestThrottleEmpty(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewThrottle(5 * time.Millisecond)
+	close(in)
+
+	r := drainLast(out)
+	assert.That(r, pred.IsEmpty())
+}
+
+func TestThrottleEmitsFirstValueImmediately(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewThrottle(time.Hour)
+
+	start := time.Now()
+	in <- 1
+	v := <-out
+	assert.That(v, pred.IsEqualTo(1))
+	assert.That(int64(time.Since(start)), pred.Lt(int64(time.Second)))
+
+	in <- 2
+	in <- 3
+	close(in)
+
+	r := drainLast(out)
+	assert.That(r, pred.IsEqualTo([]interface{}{3}))
+}
+
+func TestThrottleWithContinuousInput(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	in, out := debounce.NewThrottle(20 * time.Millisecond)
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			in <- i
+			time.Sleep(1 * time.Millisecond)
+		}
+		close(in)
+	}()
+
+	r := drainLast(out)
+	assert.That(len(r), pred.Gt(2))
+	assert.That(len(r), pred.Lt(10))
+	assert.That(r[0], pred.IsEqualTo(0))
+	assert.That(r[len(r)-1], pred.IsEqualTo(99))
+}
@@ -0,0 +1,234 @@
+/*
+Package featureflags provides typed feature flags read from a section of a
+configuration file managed by a config.Loader.
+
+Flags are declared on a Set with a default value, which is used whenever the
+flag is absent from the configuration or has an unexpected type. Flag values
+are always evaluated against the latest version of the configuration, and
+subscriptions notify interested parties when the value of a specific flag
+changes after a reload.
+
+	flags := featureflags.New(func(cfg interface{}) map[string]interface{} {
+		return cfg.(*Config).Features
+	})
+	newUI := flags.Bool("new_ui", false)
+	rollout := flags.Percentage("new_checkout", 0)
+
+	loader, err := config.NewLoader("config.yaml", defaultConfig, flags.Option())
+
+	if newUI.Enabled() && rollout.EnabledFor(userID) {
+		...
+	}
+*/
+package featureflags
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/marcus999/go-config"
+)
+
+// Set is a collection of feature flags read from a section of a
+// configuration
+type Set struct {
+	section func(cfg interface{}) map[string]interface{}
+
+	mu       sync.Mutex
+	loader   *config.Loader
+	snapshot map[string]interface{}
+	subs     map[string][]func()
+}
+
+// New creates a new Set of feature flags. The section function extracts the
+// flags from the configuration object, typically a map[string]interface{}
+// field of the configuration struct.
+func New(section func(cfg interface{}) map[string]interface{}) *Set {
+	return &Set{
+		section: section,
+		subs:    make(map[string][]func()),
+	}
+}
+
+// Option returns the config.Option binding the Set to the Loader it is passed
+// to.
+func (s *Set) Option() config.Option {
+	return func(l *config.Loader) {
+		s.mu.Lock()
+		s.loader = l
+		s.mu.Unlock()
+		config.ReloadHandler(s.reload)(l)
+	}
+}
+
+// Subscribe attaches a function to be called when the value of the named
+// flag changes after a configuration reload
+func (s *Set) Subscribe(name string, f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snapshot == nil {
+		s.snapshot = s.current()
+	}
+	s.subs[name] = append(s.subs[name], f)
+}
+
+// Bool declares a boolean flag
+func (s *Set) Bool(name string, defaultValue bool) *BoolFlag {
+	return &BoolFlag{set: s, name: name, defaultValue: defaultValue}
+}
+
+// Int declares an integer flag
+func (s *Set) Int(name string, defaultValue int) *IntFlag {
+	return &IntFlag{set: s, name: name, defaultValue: defaultValue}
+}
+
+// String declares a string flag
+func (s *Set) String(name string, defaultValue string) *StringFlag {
+	return &StringFlag{set: s, name: name, defaultValue: defaultValue}
+}
+
+// Percentage declares a percentage flag, used for progressive rollouts. The
+// value is expressed in the configuration as a number between 0 and 100,
+// optionally followed by a '%' sign.
+func (s *Set) Percentage(name string, defaultValue float64) *PercentageFlag {
+	return &PercentageFlag{set: s, name: name, defaultValue: defaultValue}
+}
+
+// ---------------------------------------------------------------------------
+// Typed flags
+// ---------------------------------------------------------------------------
+
+// BoolFlag is a feature flag holding a boolean value
+type BoolFlag struct {
+	set          *Set
+	name         string
+	defaultValue bool
+}
+
+// Enabled returns the current value of the flag
+func (f *BoolFlag) Enabled() bool {
+	switch v := f.set.value(f.name).(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return f.defaultValue
+}
+
+// IntFlag is a feature flag holding an integer value
+type IntFlag struct {
+	set          *Set
+	name         string
+	defaultValue int
+}
+
+// Value returns the current value of the flag
+func (f *IntFlag) Value() int {
+	if v, ok := toFloat(f.set.value(f.name)); ok && v == float64(int(v)) {
+		return int(v)
+	}
+	return f.defaultValue
+}
+
+// StringFlag is a feature flag holding a string value
+type StringFlag struct {
+	set          *Set
+	name         string
+	defaultValue string
+}
+
+// Value returns the current value of the flag
+func (f *StringFlag) Value() string {
+	if v, ok := f.set.value(f.name).(string); ok {
+		return v
+	}
+	return f.defaultValue
+}
+
+// PercentageFlag is a feature flag holding a rollout percentage
+type PercentageFlag struct {
+	set          *Set
+	name         string
+	defaultValue float64
+}
+
+// Value returns the current value of the flag, between 0 and 100
+func (f *PercentageFlag) Value() float64 {
+	raw := f.set.value(f.name)
+	if s, isString := raw.(string); isString {
+		raw = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	}
+	v, ok := toFloat(raw)
+	if !ok || v < 0 || v > 100 {
+		return f.defaultValue
+	}
+	return v
+}
+
+// EnabledFor returns true if the flag is enabled for the given key, e.g. a
+// user ID. The result is stable for a given key and flag name, so that
+// increasing the percentage only ever enables the flag for more keys.
+func (f *PercentageFlag) EnabledFor(key string) bool {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%v:%v", f.name, key)
+	return float64(h.Sum32()%10000) < f.Value()*100
+}
+
+// ---------------------------------------------------------------------------
+// Set implementation
+// ---------------------------------------------------------------------------
+
+func (s *Set) value(name string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current()[name]
+}
+
+// current returns the flags of the current configuration; s.mu must be held
+func (s *Set) current() map[string]interface{} {
+	if s.loader == nil {
+		return nil
+	}
+	cfg := s.loader.Get()
+	if cfg == nil {
+		return nil
+	}
+	return s.section(cfg)
+}
+
+func (s *Set) reload(cfg interface{}) {
+	s.mu.Lock()
+	flags := s.section(cfg)
+	var notify []func()
+	for name, subs := range s.subs {
+		if !reflect.DeepEqual(s.snapshot[name], flags[name]) {
+			notify = append(notify, subs...)
+		}
+	}
+	s.snapshot = flags
+	s.mu.Unlock()
+
+	for _, f := range notify {
+		f()
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,148 @@
+package featureflags_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/featureflags"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type testConfig struct {
+	Features map[string]interface{} `json:"features"`
+}
+
+func newTestLoader(t *testing.T, content string, opts ...config.Option) (*config.Loader, string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go-config-test-")
+	if err != nil {
+		t.Fatalf("failed to create temp directory, %v", err)
+	}
+	filename := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(filename, []byte(content), 0666); err != nil {
+		t.Fatalf("failed to write config file, %v", err)
+	}
+
+	opts = append(opts, config.OptDebounceInterval(20*time.Millisecond))
+	l, err := config.NewLoader(filename, testConfig{}, opts...)
+	if err != nil {
+		t.Fatalf("failed to create loader, %v", err)
+	}
+	return l, filename, func() {
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func newTestSet() *featureflags.Set {
+	return featureflags.New(func(cfg interface{}) map[string]interface{} {
+		return cfg.(*testConfig).Features
+	})
+}
+
+func TestFlagValues(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	flags := newTestSet()
+	_, _, cleanup := newTestLoader(t, `
+features:
+  new_ui: true
+  max_items: 25
+  theme: dark
+  rollout: 50%
+`, flags.Option())
+	defer cleanup()
+
+	assert.That(flags.Bool("new_ui", false).Enabled(), pred.IsEqualTo(true))
+	assert.That(flags.Int("max_items", 10).Value(), pred.IsEqualTo(25))
+	assert.That(flags.String("theme", "light").Value(), pred.IsEqualTo("dark"))
+	assert.That(flags.Percentage("rollout", 0).Value(), pred.IsEqualTo(50.0))
+}
+
+func TestFlagDefaults(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	flags := newTestSet()
+	_, _, cleanup := newTestLoader(t, `
+features:
+  max_items: many
+  rollout: 150
+`, flags.Option())
+	defer cleanup()
+
+	assert.That(flags.Bool("new_ui", true).Enabled(), pred.IsEqualTo(true))
+	assert.That(flags.Int("max_items", 10).Value(), pred.IsEqualTo(10))
+	assert.That(flags.String("theme", "light").Value(), pred.IsEqualTo("light"))
+	assert.That(flags.Percentage("rollout", 5).Value(), pred.IsEqualTo(5.0))
+}
+
+func TestPercentageFlagEnabledFor(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	flags := newTestSet()
+	_, _, cleanup := newTestLoader(t, `
+features:
+  none: 0
+  half: 50
+  all: 100
+`, flags.Option())
+	defer cleanup()
+
+	none, half, all := flags.Percentage("none", 0), flags.Percentage("half", 0), flags.Percentage("all", 0)
+	var halfCount int
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		assert.That(none.EnabledFor(key), pred.IsEqualTo(false))
+		assert.That(all.EnabledFor(key), pred.IsEqualTo(true))
+		assert.That(half.EnabledFor(key), pred.IsEqualTo(half.EnabledFor(key)))
+		if half.EnabledFor(key) {
+			halfCount++
+		}
+	}
+	assert.That(halfCount, pred.CloseTo(500, 75))
+}
+
+func TestFlagSubscriptions(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	flags := newTestSet()
+	_, filename, cleanup := newTestLoader(t, `
+features:
+  new_ui: false
+  theme: dark
+`, flags.Option())
+	defer cleanup()
+
+	changed := make(chan string, 10)
+	flags.Subscribe("new_ui", func() { changed <- "new_ui" })
+	flags.Subscribe("theme", func() { changed <- "theme" })
+	time.Sleep(100 * time.Millisecond)
+
+	err := ioutil.WriteFile(filename, []byte(`
+features:
+  new_ui: true
+  theme: dark
+`), 0666)
+	assert.That(err, pred.IsNil())
+
+	select {
+	case name := <-changed:
+		assert.That(name, pred.IsEqualTo("new_ui"))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for flag change notification")
+	}
+	assert.That(flags.Bool("new_ui", false).Enabled(), pred.IsEqualTo(true))
+
+	select {
+	case name := <-changed:
+		t.Errorf("unexpected change notification for %v", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Package gitsource provides a configuration source reading a file from a git
+repository, for lightweight GitOps-style configuration distribution.
+
+The source periodically fetches the repository, resolves the configured ref
+and notifies the loader when it points to a new commit. The content of the
+configuration file is always read from the last resolved commit, so that
+network failures only delay updates and never affect the active
+configuration. Those failures are passed to the error handlers of the
+loader.
+
+	src, err := gitsource.New("https://github.com/acme/configs.git", "main", "app/config.yaml")
+	loader, err := config.NewLoaderFromSource(src, defaultConfig)
+
+It relies on the git command line tool being available in the PATH.
+*/
+package gitsource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is the default interval between two fetches
+const DefaultInterval = time.Minute
+
+// errorBufferSize is the number of background errors buffered for a slow
+// consumer of Errors. Errors beyond that are only passed to the error handler.
+const errorBufferSize = 8
+
+// Source is a config.Source reading a file at a given ref of a git
+// repository
+type Source struct {
+	dir      string
+	ref      string
+	path     string
+	fetch    bool
+	tempDir  bool
+	interval time.Duration
+	onError  func(error)
+
+	mu     sync.Mutex
+	commit string
+
+	changes chan struct{}
+	errors  chan error
+	ctx     context.Context
+	cancel  func()
+	done    chan struct{}
+}
+
+// Option is the base type for Source options
+type Option func(*Source)
+
+// WithInterval sets the interval between two fetches of the repository. It
+// must be positive.
+func WithInterval(d time.Duration) Option {
+	return func(s *Source) {
+		s.interval = d
+	}
+}
+
+// WithCloneDir sets the directory in which the remote repository is cloned.
+// By default, a temporary directory is used and removed on Close.
+func WithCloneDir(dir string) Option {
+	return func(s *Source) {
+		s.dir = dir
+	}
+}
+
+// WithErrorHandler attaches a function to be called when fetching the
+// repository or resolving the ref fails in the background. The same errors
+// are also reported on Errors, and passed by the loader to its own error
+// handlers.
+func WithErrorHandler(f func(error)) Option {
+	return func(s *Source) {
+		s.onError = f
+	}
+}
+
+// New creates a new Source cloning the remote repository, and reading the
+// file at path from the given ref, typically a branch or tag name
+func New(repo, ref, path string, opts ...Option) (*Source, error) {
+	s, err := newSource(ref, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.fetch = true
+
+	if s.dir == "" {
+		dir, err := ioutil.TempDir("", "gitsource-")
+		if err != nil {
+			return nil, err
+		}
+		s.dir = dir
+		s.tempDir = true
+	}
+
+	if _, err := os.Stat(s.dir + "/HEAD"); os.IsNotExist(err) {
+		_, err = s.git("clone", "--quiet", "--mirror", "--", repo, s.dir)
+		if err != nil {
+			s.cleanup()
+			return nil, err
+		}
+	}
+	return s.start()
+}
+
+// NewLocal creates a new Source reading the file at path from the given ref
+// of a local repository, without fetching. Changes are detected when the ref
+// is updated, e.g. after a commit or a pull.
+func NewLocal(dir, ref, path string, opts ...Option) (*Source, error) {
+	s, err := newSource(ref, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.dir = dir
+	return s.start()
+}
+
+func newSource(ref, path string, opts []Option) (*Source, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	s := &Source{
+		ref:      ref,
+		path:     path,
+		interval: DefaultInterval,
+		changes:  make(chan struct{}),
+		errors:   make(chan error, errorBufferSize),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %v", s.interval)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s, nil
+}
+
+// checkRef rejects refs that git would interpret as command line options
+func checkRef(ref string) error {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref '%v'", ref)
+	}
+	return nil
+}
+
+func (s *Source) start() (*Source, error) {
+	commit, err := s.resolve()
+	if err != nil {
+		s.cleanup()
+		return nil, err
+	}
+	s.commit = commit
+
+	go s.run()
+	return s, nil
+}
+
+// Commit returns the commit from which the configuration is currently read
+func (s *Source) Commit() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit
+}
+
+// Read returns the content of the configuration file at the last resolved
+// commit
+func (s *Source) Read() ([]byte, error) {
+	return s.git("show", s.Commit()+":"+s.path)
+}
+
+// Changes returns the channel notified when the ref points to a new commit
+func (s *Source) Changes() <-chan struct{} {
+	return s.changes
+}
+
+// Errors returns the channel receiving the errors that occur while fetching
+// the repository or resolving the ref in the background. It is closed when
+// the source is closed.
+func (s *Source) Errors() <-chan error {
+	return s.errors
+}
+
+// Close stops polling the repository, and removes the clone directory if it
+// was created as a temporary directory
+func (s *Source) Close() error {
+	s.cancel()
+	<-s.done
+	return s.cleanup()
+}
+
+// ---------------------------------------------------------------------------
+// Source implementation
+// ---------------------------------------------------------------------------
+
+func (s *Source) run() {
+	defer close(s.done)
+	defer close(s.changes)
+	defer close(s.errors)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if s.fetch {
+			if _, err := s.git("fetch", "--quiet", "--prune", "origin"); err != nil {
+				s.handleError(err)
+				continue
+			}
+		}
+
+		commit, err := s.resolve()
+		if err != nil {
+			s.handleError(err)
+			continue
+		}
+
+		s.mu.Lock()
+		changed := commit != s.commit
+		s.commit = commit
+		s.mu.Unlock()
+
+		if changed {
+			select {
+			case s.changes <- struct{}{}:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (s *Source) resolve() (string, error) {
+	out, err := s.git("rev-parse", "--verify", "--quiet", s.ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%v', %v", s.ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s *Source) git(args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(s.ctx, "git", args...)
+	if args[0] != "clone" {
+		cmd.Dir = s.dir
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("git %v: %v", args[0], err)
+		}
+		return nil, fmt.Errorf("git %v: %v", args[0], msg)
+	}
+	return out, nil
+}
+
+// handleError reports a background error, unless it was caused by the source
+// being closed
+func (s *Source) handleError(err error) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	if s.onError != nil {
+		s.onError(err)
+	}
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+func (s *Source) cleanup() error {
+	if s.tempDir {
+		return os.RemoveAll(s.dir)
+	}
+	return nil
+}
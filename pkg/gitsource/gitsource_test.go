@@ -0,0 +1,186 @@
+package gitsource_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/gitsource"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type gitTestRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newGitTestRepo(t *testing.T) *gitTestRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir, err := ioutil.TempDir("", "go-test-")
+	if err != nil {
+		t.Fatalf("failed to create repository directory, %v", err)
+	}
+	r := &gitTestRepo{t: t, dir: dir}
+	r.git("init", "--quiet")
+	r.git("checkout", "--quiet", "-b", "main")
+	return r
+}
+
+func (r *gitTestRepo) git(args ...string) {
+	r.t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		r.t.Fatalf("git %v failed, %v, %s", args, err, out)
+	}
+}
+
+func (r *gitTestRepo) commit(filename, content string) {
+	r.t.Helper()
+	err := ioutil.WriteFile(filepath.Join(r.dir, filename), []byte(content), 0666)
+	if err != nil {
+		r.t.Fatalf("failed to write file, %v", err)
+	}
+	r.git("add", filename)
+	r.git("commit", "--quiet", "-m", "update "+filename)
+}
+
+func (r *gitTestRepo) teardown() {
+	os.RemoveAll(r.dir)
+}
+
+type testConfig struct {
+	Name string `json:"name"`
+}
+
+func TestRemoteSource(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	repo := newGitTestRepo(t)
+	defer repo.teardown()
+	repo.commit("config.yaml", "name: first\n")
+
+	src, err := gitsource.New(repo.dir, "main", "config.yaml", gitsource.WithInterval(10*time.Millisecond))
+	assert.That(err, pred.IsNil())
+
+	reloaded := make(chan interface{}, 10)
+	l, err := config.NewLoaderFromSource(src, testConfig{},
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) { reloaded <- cfg }),
+	)
+	assert.That(err, pred.IsNil())
+	defer l.Close()
+	assert.That(l.Get().(*testConfig).Name, pred.IsEqualTo("first"))
+
+	commit := src.Commit()
+	repo.commit("config.yaml", "name: second\n")
+
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("second"))
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for reload")
+	}
+	assert.That(src.Commit(), pred.IsNotEqualTo(commit))
+}
+
+func TestLocalSource(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	repo := newGitTestRepo(t)
+	defer repo.teardown()
+	repo.commit("config.yaml", "name: first\n")
+
+	src, err := gitsource.NewLocal(repo.dir, "main", "config.yaml", gitsource.WithInterval(10*time.Millisecond))
+	assert.That(err, pred.IsNil())
+	defer src.Close()
+
+	content, err := src.Read()
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.IsEqualTo("name: first\n"))
+
+	repo.commit("config.yaml", "name: second\n")
+	select {
+	case <-src.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for change")
+	}
+
+	content, err = src.Read()
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.IsEqualTo("name: second\n"))
+}
+
+func TestSourceReportsErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	repo := newGitTestRepo(t)
+	defer repo.teardown()
+	repo.commit("config.yaml", "name: first\n")
+	repo.git("branch", "feature")
+
+	src, err := gitsource.NewLocal(repo.dir, "feature", "config.yaml", gitsource.WithInterval(10*time.Millisecond))
+	assert.That(err, pred.IsNil())
+
+	errs := make(chan error, 10)
+	l, err := config.NewLoaderFromSource(src, testConfig{},
+		config.ErrorHandler(func(err error) { errs <- err }),
+	)
+	assert.That(err, pred.IsNil())
+	defer l.Close()
+
+	repo.git("branch", "-D", "feature")
+	select {
+	case err := <-errs:
+		var werr *config.WatchError
+		assert.That(errors.As(err, &werr), pred.IsEqualTo(true))
+		assert.That(err.Error(), pred.Contains("failed to resolve 'feature'"))
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for error")
+	}
+}
+
+func TestSourceWithInvalidInterval(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	repo := newGitTestRepo(t)
+	defer repo.teardown()
+	repo.commit("config.yaml", "name: first\n")
+
+	_, err := gitsource.NewLocal(repo.dir, "main", "config.yaml", gitsource.WithInterval(0))
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("invalid interval"))
+}
+
+func TestSourceWithInvalidRef(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	repo := newGitTestRepo(t)
+	defer repo.teardown()
+	repo.commit("config.yaml", "name: first\n")
+
+	_, err := gitsource.NewLocal(repo.dir, "missing", "config.yaml")
+	assert.That(err, pred.IsNotNil())
+}
+
+func TestSourceWithOptionLikeArguments(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	repo := newGitTestRepo(t)
+	defer repo.teardown()
+	repo.commit("config.yaml", "name: first\n")
+
+	_, err := gitsource.NewLocal(repo.dir, "--all", "config.yaml")
+	assert.That(err, pred.IsNotNil())
+
+	marker := filepath.Join(repo.dir, "marker")
+	_, err = gitsource.New("--upload-pack=touch "+marker, "main", "config.yaml")
+	assert.That(err, pred.IsNotNil())
+	_, err = os.Stat(marker)
+	assert.That(os.IsNotExist(err), pred.IsEqualTo(true))
+}
@@ -0,0 +1,71 @@
+package grpcsource
+
+import (
+	"fmt"
+
+	"github.com/marcus999/go-config"
+)
+
+// LoaderService is a ConfigServiceServer serving the effective configuration
+// of a Loader, so that other processes can load the same configuration
+// through a Source. The configuration is republished every time the loader
+// applies a new configuration.
+//
+// Secret values are redacted as with Loader.MarshalRedacted; processes that
+// need them should load them from their own source.
+type LoaderService struct {
+	*Publisher
+	name    string
+	loader  *config.Loader
+	onError func(error)
+	remove  func()
+}
+
+// LoaderServiceOption is the base type for LoaderService options
+type LoaderServiceOption func(*LoaderService)
+
+// WithPublishErrorHandler attaches a function to be called when the
+// configuration cannot be republished after a reload. The last published
+// configuration remains available.
+func WithPublishErrorHandler(f func(error)) LoaderServiceOption {
+	return func(s *LoaderService) {
+		s.onError = f
+	}
+}
+
+// NewLoaderService returns a new LoaderService serving the effective
+// configuration of loader under the given name
+func NewLoaderService(name string, loader *config.Loader, opts ...LoaderServiceOption) (*LoaderService, error) {
+	s := &LoaderService{
+		Publisher: NewPublisher(),
+		name:      name,
+		loader:    loader,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.publish(); err != nil {
+		return nil, err
+	}
+	s.remove = loader.AddReloadHandler(func(interface{}) {
+		if err := s.publish(); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	})
+	return s, nil
+}
+
+// Close stops tracking the changes of the loader. The last published
+// configuration remains available.
+func (s *LoaderService) Close() {
+	s.remove()
+}
+
+func (s *LoaderService) publish() error {
+	content, err := s.loader.MarshalRedacted()
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration, %w", err)
+	}
+	s.Publish(s.name, content)
+	return nil
+}
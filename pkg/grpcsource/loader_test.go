@@ -0,0 +1,90 @@
+package grpcsource_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/grpcsource"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestLoaderService(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, err := ioutil.TempDir("", "grpcsource-test-")
+	assert.That(err, pred.IsNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(filename, []byte("name: first\n"), 0666)
+
+	parent, err := config.NewLoader(filename, testConfig{})
+	assert.That(err, pred.IsNil())
+	defer parent.Close()
+
+	svc, err := grpcsource.NewLoaderService("app", parent)
+	assert.That(err, pred.IsNil())
+	defer svc.Close()
+	conn, teardown := startTestServer(t, svc.Publisher)
+	defer teardown()
+
+	src, err := grpcsource.New(conn, "app")
+	assert.That(err, pred.IsNil())
+	reloaded := make(chan interface{}, 10)
+	child, err := config.NewLoaderFromSource(src, testConfig{},
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) { reloaded <- cfg }),
+	)
+	assert.That(err, pred.IsNil())
+	defer child.Close()
+	assert.That(child.Get().(*testConfig).Name, pred.IsEqualTo("first"))
+
+	time.Sleep(100 * time.Millisecond)
+	err = parent.Update([]byte("name: second\n"))
+	assert.That(err, pred.IsNil())
+
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("second"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload")
+	}
+}
+
+type secretConfig struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func TestLoaderServiceRedactsSecrets(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, err := ioutil.TempDir("", "grpcsource-test-")
+	assert.That(err, pred.IsNil())
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(filename, []byte("name: app\npassword: s3cr3t\n"), 0666)
+
+	parent, err := config.NewLoader(filename, secretConfig{})
+	assert.That(err, pred.IsNil())
+	defer parent.Close()
+
+	svc, err := grpcsource.NewLoaderService("app", parent)
+	assert.That(err, pred.IsNil())
+	defer svc.Close()
+	conn, teardown := startTestServer(t, svc.Publisher)
+	defer teardown()
+
+	src, err := grpcsource.New(conn, "app")
+	assert.That(err, pred.IsNil())
+	child, err := config.NewLoaderFromSource(src, secretConfig{})
+	assert.That(err, pred.IsNil())
+	defer child.Close()
+	assert.That(child.Get().(*secretConfig).Name, pred.IsEqualTo("app"))
+	assert.That(child.Get().(*secretConfig).Password, pred.IsEqualTo(config.RedactedValue))
+}
@@ -0,0 +1,101 @@
+package grpcsource
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Publisher is a ConfigServiceServer serving named configurations published
+// by a central config service, and pushing every update to all watching
+// clients
+type Publisher struct {
+	mu          sync.Mutex
+	snapshots   map[string]*Snapshot
+	subscribers map[string]map[chan *Snapshot]struct{}
+}
+
+var _ ConfigServiceServer = (*Publisher)(nil)
+
+// NewPublisher returns a new Publisher with no configuration
+func NewPublisher() *Publisher {
+	return &Publisher{
+		snapshots:   make(map[string]*Snapshot),
+		subscribers: make(map[string]map[chan *Snapshot]struct{}),
+	}
+}
+
+// Publish sets the content of a named configuration and pushes it to all
+// watching clients. Publishing identical content is a no-op.
+func (p *Publisher) Publish(name string, content []byte) {
+	snapshot := &Snapshot{
+		Name:    name,
+		Version: fmt.Sprintf("%x", sha256.Sum256(content)),
+		Content: content,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if current, ok := p.snapshots[name]; ok && current.Version == snapshot.Version {
+		return
+	}
+	p.snapshots[name] = snapshot
+	for ch := range p.subscribers[name] {
+		// Only the latest snapshot matters to slow clients
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snapshot
+	}
+}
+
+// GetSnapshot returns the current snapshot of a named configuration
+func (p *Publisher) GetSnapshot(ctx context.Context, in *SnapshotRequest) (*Snapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot, ok := p.snapshots[in.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "config '%v' not found", in.Name)
+	}
+	return snapshot, nil
+}
+
+// WatchChanges streams a new snapshot of a named configuration every time it
+// is published, until the client cancels the stream
+func (p *Publisher) WatchChanges(in *WatchRequest, stream WatchChangesServer) error {
+	ch := make(chan *Snapshot, 1)
+
+	p.mu.Lock()
+	if current, ok := p.snapshots[in.Name]; ok && current.Version != in.Version {
+		ch <- current
+	}
+	if p.subscribers[in.Name] == nil {
+		p.subscribers[in.Name] = make(map[chan *Snapshot]struct{})
+	}
+	p.subscribers[in.Name][ch] = struct{}{}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.subscribers[in.Name], ch)
+		p.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case snapshot := <-ch:
+			if err := stream.Send(snapshot); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package grpcsource
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+)
+
+// ServiceName is the fully qualified name of the config gRPC service
+const ServiceName = "goconfig.ConfigService"
+
+const (
+	getSnapshotMethod  = "/" + ServiceName + "/GetSnapshot"
+	watchChangesMethod = "/" + ServiceName + "/WatchChanges"
+)
+
+// SnapshotRequest is the request message of GetSnapshot
+type SnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+// WatchRequest is the request message of WatchChanges. If Version is set,
+// the server skips the current snapshot if it matches that version.
+type WatchRequest struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Snapshot is the content of a named configuration at a given version
+type Snapshot struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Content []byte `json:"content"`
+}
+
+// ConfigServiceServer is the server API of the config gRPC service
+type ConfigServiceServer interface {
+	// GetSnapshot returns the current snapshot of a named configuration
+	GetSnapshot(context.Context, *SnapshotRequest) (*Snapshot, error)
+
+	// WatchChanges streams a new snapshot of a named configuration every
+	// time it changes
+	WatchChanges(*WatchRequest, WatchChangesServer) error
+}
+
+// WatchChangesServer is the server side stream of WatchChanges
+type WatchChangesServer interface {
+	Send(*Snapshot) error
+	grpc.ServerStream
+}
+
+// RegisterConfigServiceServer registers a ConfigServiceServer implementation
+// with a gRPC server
+func RegisterConfigServiceServer(s grpc.ServiceRegistrar, srv ConfigServiceServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// ---------------------------------------------------------------------------
+// Client
+// ---------------------------------------------------------------------------
+
+// Client is the client API of the config gRPC service
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// WatchChangesClient is the client side stream of WatchChanges
+type WatchChangesClient interface {
+	Recv() (*Snapshot, error)
+	grpc.ClientStream
+}
+
+// NewClient returns a client of the config gRPC service using an existing
+// connection
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// GetSnapshot returns the current snapshot of a named configuration
+func (c *Client) GetSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, getSnapshotMethod, in, out, callOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WatchChanges opens a stream receiving a new snapshot of a named
+// configuration every time it changes
+func (c *Client) WatchChanges(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], watchChangesMethod, callOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	x := &watchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func callOptions(opts []grpc.CallOption) []grpc.CallOption {
+	return append([]grpc.CallOption{grpc.ForceCodec(jsonCodec{})}, opts...)
+}
+
+type watchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *watchChangesClient) Recv() (*Snapshot, error) {
+	m := new(Snapshot)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ---------------------------------------------------------------------------
+// Service descriptor
+// ---------------------------------------------------------------------------
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ConfigServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSnapshot",
+			Handler:    getSnapshotHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       watchChangesHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+func getSnapshotHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfigServiceServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: getSnapshotMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfigServiceServer).GetSnapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func watchChangesHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(WatchRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(ConfigServiceServer).WatchChanges(in, &watchChangesServer{stream})
+}
+
+type watchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *watchChangesServer) Send(m *Snapshot) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ---------------------------------------------------------------------------
+// JSON codec, avoiding the need for generated protobuf code
+// ---------------------------------------------------------------------------
+
+// codecName is the content subtype of the service messages. It is specific to
+// this package, so that it does not clash with codecs registered by other
+// packages.
+const codecName = "goconfig-json"
+
+// ServerCodec returns the server option to pass to grpc.NewServer for the
+// server to decode the JSON messages of the service. Messages of other
+// services are still encoded with the default protobuf codec, so that the
+// server can be shared:
+//
+//	server := grpc.NewServer(grpcsource.ServerCodec())
+//	grpcsource.RegisterConfigServiceServer(server, pub)
+func ServerCodec() grpc.ServerOption {
+	return grpc.ForceServerCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	if !isServiceMessage(v) {
+		return encoding.GetCodec(proto.Name).Marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	if !isServiceMessage(v) {
+		return encoding.GetCodec(proto.Name).Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func isServiceMessage(v interface{}) bool {
+	switch v.(type) {
+	case *SnapshotRequest, *WatchRequest, *Snapshot:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+/*
+Package grpcsource provides a small gRPC config service and a configuration
+source receiving updates pushed by that service.
+
+A central config service publishes named configurations through a Publisher,
+and every client process loads its configuration through a Source, keeping
+the same validation and handler pipeline as file based configurations:
+
+	// server
+	server := grpc.NewServer(grpcsource.ServerCodec())
+	pub := grpcsource.NewPublisher()
+	grpcsource.RegisterConfigServiceServer(server, pub)
+	pub.Publish("frontend", content)
+
+	// client
+	src, err := grpcsource.New(conn, "frontend")
+	loader, err := config.NewLoaderFromSource(src, defaultConfig)
+
+A process can also share its own effective configuration with sidecars or
+workers through a LoaderService, republishing it on every reload:
+
+	svc, err := grpcsource.NewLoaderService("frontend", loader)
+	grpcsource.RegisterConfigServiceServer(server, svc)
+
+Messages are encoded as JSON, so that the service can be used without
+generated protobuf code. The server decodes them with the codec returned by
+ServerCodec, which must be passed to grpc.NewServer.
+*/
+package grpcsource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultRetryInterval is the default delay before re-opening a broken
+// watch stream
+const DefaultRetryInterval = 5 * time.Second
+
+// Source is a config.Source receiving a named configuration from a config
+// gRPC service
+type Source struct {
+	client        *Client
+	name          string
+	retryInterval time.Duration
+	onError       func(error)
+
+	mu       sync.Mutex
+	snapshot *Snapshot
+
+	changes chan struct{}
+	ctx     context.Context
+	cancel  func()
+	done    chan struct{}
+}
+
+// Option is the base type for Source options
+type Option func(*Source)
+
+// WithRetryInterval sets the delay before re-opening a broken watch stream
+func WithRetryInterval(d time.Duration) Option {
+	return func(s *Source) {
+		s.retryInterval = d
+	}
+}
+
+// WithErrorHandler attaches a function to be called when the watch stream
+// fails in the background
+func WithErrorHandler(f func(error)) Option {
+	return func(s *Source) {
+		s.onError = f
+	}
+}
+
+// New creates a new Source fetching the named configuration over an existing
+// gRPC connection, and watching for updates pushed by the service
+func New(cc grpc.ClientConnInterface, name string, opts ...Option) (*Source, error) {
+	s := &Source{
+		client:        NewClient(cc),
+		name:          name,
+		retryInterval: DefaultRetryInterval,
+		changes:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	snapshot, err := s.client.GetSnapshot(s.ctx, &SnapshotRequest{Name: name})
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	s.snapshot = snapshot
+
+	go s.run()
+	return s, nil
+}
+
+// Version returns the version of the current configuration snapshot
+func (s *Source) Version() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot.Version
+}
+
+// Read returns the content of the last received configuration snapshot
+func (s *Source) Read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot.Content, nil
+}
+
+// Changes returns the channel notified when a new snapshot is received
+func (s *Source) Changes() <-chan struct{} {
+	return s.changes
+}
+
+// Close stops watching for updates
+func (s *Source) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Source implementation
+// ---------------------------------------------------------------------------
+
+func (s *Source) run() {
+	defer close(s.done)
+	defer close(s.changes)
+
+	for {
+		err := s.watch()
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err != nil && s.onError != nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+func (s *Source) watch() error {
+	stream, err := s.client.WatchChanges(s.ctx, &WatchRequest{
+		Name:    s.name,
+		Version: s.Version(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		snapshot, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		changed := snapshot.Version != s.snapshot.Version
+		s.snapshot = snapshot
+		s.mu.Unlock()
+
+		if changed {
+			select {
+			case s.changes <- struct{}{}:
+			case <-s.ctx.Done():
+				return nil
+			}
+		}
+	}
+}
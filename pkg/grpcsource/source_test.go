@@ -0,0 +1,134 @@
+package grpcsource_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/grpcsource"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type testConfig struct {
+	Name string `json:"name"`
+}
+
+func startTestServer(t *testing.T, pub *grpcsource.Publisher) (*grpc.ClientConn, func()) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpcsource.ServerCodec())
+	grpcsource.RegisterConfigServiceServer(s, pub)
+	go s.Serve(lis)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial test server, %v", err)
+	}
+	return conn, func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func TestSourcePushUpdates(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	pub := grpcsource.NewPublisher()
+	pub.Publish("app", []byte("name: first\n"))
+	conn, teardown := startTestServer(t, pub)
+	defer teardown()
+
+	src, err := grpcsource.New(conn, "app")
+	assert.That(err, pred.IsNil())
+
+	reloaded := make(chan interface{}, 10)
+	l, err := config.NewLoaderFromSource(src, testConfig{},
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) { reloaded <- cfg }),
+	)
+	assert.That(err, pred.IsNil())
+	defer l.Close()
+	assert.That(l.Get().(*testConfig).Name, pred.IsEqualTo("first"))
+
+	time.Sleep(100 * time.Millisecond)
+	pub.Publish("app", []byte("name: second\n"))
+
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("second"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload")
+	}
+}
+
+func TestSourceWithUnknownConfig(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	pub := grpcsource.NewPublisher()
+	conn, teardown := startTestServer(t, pub)
+	defer teardown()
+
+	_, err := grpcsource.New(conn, "missing")
+	assert.That(status.Code(err), pred.IsEqualTo(codes.NotFound))
+}
+
+func TestPublisherSkipsIdenticalContent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	pub := grpcsource.NewPublisher()
+	pub.Publish("app", []byte("name: first\n"))
+	conn, teardown := startTestServer(t, pub)
+	defer teardown()
+
+	src, err := grpcsource.New(conn, "app")
+	assert.That(err, pred.IsNil())
+	defer src.Close()
+	version := src.Version()
+
+	time.Sleep(100 * time.Millisecond)
+	pub.Publish("app", []byte("name: first\n"))
+
+	select {
+	case <-src.Changes():
+		t.Errorf("unexpected change notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.That(src.Version(), pred.IsEqualTo(version))
+}
+
+func TestServerCodecKeepsProtobufServices(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpcsource.ServerCodec())
+	grpcsource.RegisterConfigServiceServer(s, grpcsource.NewPublisher())
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.That(err, pred.IsNil())
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.That(err, pred.IsNil())
+	assert.That(resp.Status, pred.IsEqualTo(healthpb.HealthCheckResponse_SERVING))
+}
@@ -0,0 +1,210 @@
+package k8ssource
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// serviceAccountDir is the directory where Kubernetes mounts the credentials
+// of the service account of a pod
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Client is a minimal client of the Kubernetes API, limited to reading and
+// watching Secrets
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   func() (string, error)
+}
+
+// NewClient returns a new Client for the API server at baseURL, e.g.
+// "https://10.0.0.1:443", authenticating with a bearer token if not empty
+func NewClient(baseURL string, httpClient *http.Client, token string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    httpClient,
+		token:   func() (string, error) { return token, nil },
+	}
+}
+
+// NewInClusterClient returns a new Client for the API server of the cluster
+// the process is running in, authenticating with the service account of the
+// pod. The token is read again for every request, as projected service
+// account tokens are rotated by the kubelet.
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster")
+	}
+	ca, err := ioutil.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid service account CA certificate")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &Client{
+		baseURL: "https://" + net.JoinHostPort(host, port),
+		http:    &http.Client{Transport: transport},
+		token: func() (string, error) {
+			token, err := ioutil.ReadFile(serviceAccountDir + "/token")
+			return strings.TrimSpace(string(token)), err
+		},
+	}, nil
+}
+
+// APIError is an error status returned by the API server
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("kubernetes API error %v: %v", e.Code, e.Message)
+}
+
+// isGone returns true if err reports a resource version too old to be
+// watched, requiring a new read of the resource
+func isGone(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusGone
+}
+
+// ---------------------------------------------------------------------------
+// Secrets
+// ---------------------------------------------------------------------------
+
+// secret is the subset of a Secret object used by the source. The values of
+// the data map are base64 encoded by the API, and decoded by encoding/json
+// into byte slices.
+type secret struct {
+	Metadata struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string][]byte `json:"data"`
+}
+
+// status is the object of error responses and ERROR watch events
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// getSecret reads a Secret
+func (c *Client) getSecret(ctx context.Context, namespace, name string) (*secret, error) {
+	resp, err := c.get(ctx, secretsPath(namespace)+"/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var s secret
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// watchSecret watches a Secret from the given resource version, calling f
+// for every ADDED, MODIFIED or DELETED event, until the stream ends, f
+// returns an error or ctx is canceled
+func (c *Client) watchSecret(ctx context.Context, namespace, name, resourceVersion string, f func(eventType string, s *secret) error) error {
+	query := url.Values{
+		"watch":           {"true"},
+		"fieldSelector":   {"metadata.name=" + name},
+		"resourceVersion": {resourceVersion},
+	}
+	resp, err := c.get(ctx, secretsPath(namespace), query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		var e watchEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return err
+		}
+		if e.Type == "ERROR" {
+			var st status
+			json.Unmarshal(e.Object, &st)
+			return &APIError{Code: st.Code, Message: st.Message}
+		}
+		if e.Type == "BOOKMARK" {
+			continue
+		}
+		var s secret
+		if err := json.Unmarshal(e.Object, &s); err != nil {
+			return err
+		}
+		if err := f(e.Type, &s); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		st := status{Code: resp.StatusCode, Message: resp.Status}
+		json.NewDecoder(resp.Body).Decode(&st)
+		return nil, &APIError{Code: resp.StatusCode, Message: st.Message}
+	}
+	return resp, nil
+}
+
+func secretsPath(namespace string) string {
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/secrets"
+}
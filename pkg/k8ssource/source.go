@@ -0,0 +1,248 @@
+/*
+Package k8ssource provides a configuration source reading selected keys of a
+Kubernetes Secret through the Kubernetes API, so that TLS material and tokens
+are reloaded as soon as the Secret is rotated, without projected volumes.
+
+Each key of the Secret is mapped to a key path of the configuration
+document; the values are decoded from base64 and merged with the rest of the
+configuration like any other string value:
+
+	client, err := k8ssource.NewInClusterClient()
+	src, err := k8ssource.NewSecretSource(client, "default", "frontend-tls", map[string]string{
+		"tls.crt": "tls.cert",
+		"tls.key": "tls.key",
+	})
+	loader, err := config.NewLoaderFromSource(src, defaultConfig)
+
+The source relies on a minimal client of the Kubernetes REST API instead of
+client-go, and requires the get, list and watch permissions on the Secret.
+*/
+package k8ssource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRetryInterval is the default delay before re-opening a broken
+// watch stream
+const DefaultRetryInterval = 5 * time.Second
+
+// Source is a config.Source reading selected keys of a Kubernetes Secret
+type Source struct {
+	client        *Client
+	namespace     string
+	name          string
+	keys          map[string]string
+	retryInterval time.Duration
+	onError       func(error)
+
+	mu     sync.Mutex
+	secret *secret
+
+	changes chan struct{}
+	ctx     context.Context
+	cancel  func()
+	done    chan struct{}
+}
+
+// Option is the base type for Source options
+type Option func(*Source)
+
+// WithRetryInterval sets the delay before re-opening a broken watch stream
+func WithRetryInterval(d time.Duration) Option {
+	return func(s *Source) {
+		s.retryInterval = d
+	}
+}
+
+// WithErrorHandler attaches a function to be called when watching the Secret
+// fails in the background, or when the Secret is deleted
+func WithErrorHandler(f func(error)) Option {
+	return func(s *Source) {
+		s.onError = f
+	}
+}
+
+// NewSecretSource creates a new Source reading the Secret namespace/name, and
+// watching it for changes. keys maps the keys of the Secret to the key paths
+// of the configuration where their values are set, e.g. "tls.crt" to
+// "server.tls.cert". If keys is empty, every key of the Secret is set at the
+// top level of the configuration, under its own name.
+func NewSecretSource(client *Client, namespace, name string, keys map[string]string, opts ...Option) (*Source, error) {
+	s := &Source{
+		client:        client,
+		namespace:     namespace,
+		name:          name,
+		keys:          keys,
+		retryInterval: DefaultRetryInterval,
+		changes:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	secret, err := client.getSecret(s.ctx, namespace, name)
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	s.secret = secret
+
+	go s.run()
+	return s, nil
+}
+
+// ResourceVersion returns the resource version of the Secret the
+// configuration is currently read from
+func (s *Source) ResourceVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secret.Metadata.ResourceVersion
+}
+
+// Read returns the selected keys of the last version of the Secret, as a JSON
+// configuration document
+func (s *Source) Read() ([]byte, error) {
+	s.mu.Lock()
+	data := s.secret.Data
+	s.mu.Unlock()
+
+	doc := make(map[string]interface{})
+	if len(s.keys) == 0 {
+		// Keys are set under their own name, even if they contain dots, e.g.
+		// "tls.crt"
+		for key, value := range data {
+			doc[key] = string(value)
+		}
+		return json.Marshal(doc)
+	}
+
+	for _, key := range sortedKeys(s.keys) {
+		value, ok := data[key]
+		if !ok {
+			return nil, fmt.Errorf("secret %v/%v has no key %q", s.namespace, s.name, key)
+		}
+		if err := setPath(doc, s.keys[key], string(value)); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// Changes returns the channel notified when the Secret changes
+func (s *Source) Changes() <-chan struct{} {
+	return s.changes
+}
+
+// Close stops watching the Secret
+func (s *Source) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Source implementation
+// ---------------------------------------------------------------------------
+
+func (s *Source) run() {
+	defer close(s.done)
+	defer close(s.changes)
+
+	for {
+		err := s.client.watchSecret(s.ctx, s.namespace, s.name, s.ResourceVersion(), s.update)
+		if isGone(err) {
+			// The resource version is too old to resume watching from
+			var secret *secret
+			if secret, err = s.client.getSecret(s.ctx, s.namespace, s.name); err == nil {
+				s.set(secret)
+				continue
+			}
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			// The server ended the watch, e.g. on timeout
+			continue
+		}
+		s.handleError(err)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+func (s *Source) update(eventType string, secret *secret) error {
+	if eventType == "DELETED" {
+		s.handleError(fmt.Errorf("secret %v/%v was deleted", s.namespace, s.name))
+		return nil
+	}
+	s.set(secret)
+	return nil
+}
+
+// set records a new version of the Secret, and notifies the loader if it
+// changed
+func (s *Source) set(secret *secret) {
+	s.mu.Lock()
+	changed := secret.Metadata.ResourceVersion != s.secret.Metadata.ResourceVersion
+	s.secret = secret
+	s.mu.Unlock()
+
+	if changed {
+		select {
+		case s.changes <- struct{}{}:
+		case <-s.ctx.Done():
+		}
+	}
+}
+
+func (s *Source) handleError(err error) {
+	if err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// setPath sets value at a dotted key path of doc, creating intermediate maps
+// as needed
+func setPath(doc map[string]interface{}, path, value string) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := doc[key].(map[string]interface{})
+		if !ok {
+			if _, exists := doc[key]; exists {
+				return fmt.Errorf("conflicting key path %q", path)
+			}
+			child = make(map[string]interface{})
+			doc[key] = child
+		}
+		doc = child
+	}
+	last := keys[len(keys)-1]
+	if _, exists := doc[last]; exists {
+		return fmt.Errorf("conflicting key path %q", path)
+	}
+	doc[last] = value
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,185 @@
+package k8ssource_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/k8ssource"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type tlsConfig struct {
+	Name string `json:"name"`
+	TLS  struct {
+		Cert string `json:"cert"`
+		Key  string `json:"key"`
+	} `json:"tls"`
+}
+
+// fakeAPIServer serves a single Secret, and pushes its updates to watchers
+type fakeAPIServer struct {
+	mu       sync.Mutex
+	version  int
+	data     map[string]string
+	watchers []chan string
+}
+
+func (s *fakeAPIServer) object() string {
+	var data string
+	for k, v := range s.data {
+		if data != "" {
+			data += ","
+		}
+		data += fmt.Sprintf("%q:%q", k, base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	return fmt.Sprintf(`{"metadata":{"name":"app","namespace":"default","resourceVersion":"%d"},"data":{%v}}`,
+		s.version, data)
+}
+
+func (s *fakeAPIServer) update(data map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version++
+	s.data = data
+	for _, w := range s.watchers {
+		w <- fmt.Sprintf(`{"type":"MODIFIED","object":%v}`, s.object())
+	}
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer t0k3n" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"kind":"Status","code":401,"message":"Unauthorized"}`)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/v1/namespaces/default/secrets/app":
+		s.mu.Lock()
+		fmt.Fprint(w, s.object())
+		s.mu.Unlock()
+
+	case "/api/v1/namespaces/default/secrets":
+		if r.URL.Query().Get("fieldSelector") != "metadata.name=app" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events := make(chan string, 10)
+		s.mu.Lock()
+		s.watchers = append(s.watchers, events)
+		s.mu.Unlock()
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case e := <-events:
+				fmt.Fprintln(w, e)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"kind":"Status","code":404,"message":"secrets \"missing\" not found"}`)
+	}
+}
+
+func TestSecretSource(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	api := &fakeAPIServer{version: 1, data: map[string]string{"tls.crt": "cert1", "tls.key": "key1"}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := k8ssource.NewClient(server.URL, nil, "t0k3n")
+	src, err := k8ssource.NewSecretSource(client, "default", "app", map[string]string{
+		"tls.crt": "tls.cert",
+		"tls.key": "tls.key",
+	})
+	assert.That(err, pred.IsNil())
+
+	reloaded := make(chan interface{}, 10)
+	l, err := config.NewLoaderFromSource(src, tlsConfig{Name: "default"},
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) { reloaded <- cfg }),
+	)
+	assert.That(err, pred.IsNil())
+	defer l.Close()
+
+	cfg := l.Get().(*tlsConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("default"))
+	assert.That(cfg.TLS.Cert, pred.IsEqualTo("cert1"))
+	assert.That(cfg.TLS.Key, pred.IsEqualTo("key1"))
+
+	time.Sleep(100 * time.Millisecond)
+	api.update(map[string]string{"tls.crt": "cert2", "tls.key": "key2"})
+
+	select {
+	case icfg := <-reloaded:
+		cfg := icfg.(*tlsConfig)
+		assert.That(cfg.TLS.Cert, pred.IsEqualTo("cert2"))
+		assert.That(cfg.TLS.Key, pred.IsEqualTo("key2"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload")
+	}
+	assert.That(src.ResourceVersion(), pred.IsEqualTo("2"))
+}
+
+func TestSecretSourceWithMissingKey(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	api := &fakeAPIServer{version: 1, data: map[string]string{"tls.crt": "cert1"}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := k8ssource.NewClient(server.URL, nil, "t0k3n")
+	src, err := k8ssource.NewSecretSource(client, "default", "app", map[string]string{
+		"tls.key": "tls.key",
+	})
+	assert.That(err, pred.IsNil())
+	defer src.Close()
+
+	_, err = src.Read()
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains(`secret default/app has no key "tls.key"`))
+}
+
+func TestSecretSourceWithAllKeys(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	api := &fakeAPIServer{version: 1, data: map[string]string{"tls": "bundle", "tls.crt": "cert1"}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := k8ssource.NewClient(server.URL, nil, "t0k3n")
+	src, err := k8ssource.NewSecretSource(client, "default", "app", nil)
+	assert.That(err, pred.IsNil())
+	defer src.Close()
+
+	content, err := src.Read()
+	assert.That(err, pred.IsNil())
+	assert.That(string(content), pred.IsEqualTo(`{"tls":"bundle","tls.crt":"cert1"}`))
+}
+
+func TestSecretSourceErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	server := httptest.NewServer(&fakeAPIServer{})
+	defer server.Close()
+
+	_, err := k8ssource.NewSecretSource(k8ssource.NewClient(server.URL, nil, "t0k3n"), "default", "missing", nil)
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("kubernetes API error 404"))
+
+	_, err = k8ssource.NewSecretSource(k8ssource.NewClient(server.URL, nil, "wrong"), "default", "app", nil)
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("Unauthorized"))
+}
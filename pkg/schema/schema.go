@@ -0,0 +1,403 @@
+/*
+Package schema implements the subset of JSON Schema commonly used to describe
+configuration files, and checks raw configuration documents against it.
+
+Documents are the generic values produced by decoding YAML or JSON content:
+map[string]interface{}, []interface{}, string, bool, nil, and json.Number or
+any Go numeric type for numbers.
+
+	s, err := schema.Parse(data)
+	if err != nil {
+		...
+	}
+	for _, err := range s.Validate(doc) {
+		fmt.Println(err)
+	}
+
+The supported keywords are type, properties, required,
+additionalProperties, items, enum, minimum, maximum, minLength, maxLength,
+minItems, maxItems and pattern. Other keywords are ignored.
+*/
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// Schema is a JSON Schema, or one of its nested sub-schemas
+type Schema struct {
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+
+	Type                 Types              `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+
+	// reject is set for the boolean schema `false`, which no value matches
+	reject  bool
+	pattern *regexp.Regexp
+}
+
+// False is the boolean schema that no value matches, typically used as
+// `additionalProperties: false`.
+var False = &Schema{reject: true}
+
+// Parse parses a JSON Schema document
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema, %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, fmt.Errorf("invalid schema, %w", err)
+	}
+	return &s, nil
+}
+
+type schemaFields Schema
+
+// UnmarshalJSON decodes a schema, accepting the boolean schemas `true` and
+// `false`
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{reject: true}
+		return nil
+	}
+	return json.Unmarshal(data, (*schemaFields)(s))
+}
+
+// MarshalJSON encodes a schema, using the boolean schema `false` where
+// appropriate
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.reject {
+		return []byte("false"), nil
+	}
+	return json.Marshal((*schemaFields)(s))
+}
+
+// compile prepares the patterns of the schema and its sub-schemas
+func (s *Schema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	for _, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if err := s.AdditionalProperties.compile(); err != nil {
+		return err
+	}
+	return s.Items.compile()
+}
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+// Types is the list of types allowed by a schema. It is encoded as a single
+// string when it contains only one type.
+type Types []string
+
+// UnmarshalJSON decodes either a single type name or a list of type names
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Types{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// MarshalJSON encodes a single type as a string, and multiple types as a
+// list
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// ---------------------------------------------------------------------------
+// Validation
+// ---------------------------------------------------------------------------
+
+// Error reports a value that does not match the schema. Path is the path of
+// the offending value, e.g. "server.port" or "servers[0].host", or empty for
+// the document itself.
+type Error struct {
+	Path    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks doc against the schema and returns all the mismatches
+// found, or nil if the document is valid.
+func (s *Schema) Validate(doc interface{}) []error {
+	var errs []error
+	s.validate(doc, "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v interface{}, path string, errs *[]error) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, &Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.reject {
+		fail("not allowed")
+		return
+	}
+	if len(s.Type) != 0 && !s.Type.match(v) {
+		fail("expected %v, got %v", s.Type.String(), typeName(v))
+		return
+	}
+	if len(s.Enum) != 0 && !inEnum(v, s.Enum) {
+		fail("value %v is not one of %v", display(v), displayList(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, &Error{Path: keyPath(path, name), Message: "required field is missing"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				p.validate(v[k], keyPath(path, k), errs)
+			} else if s.AdditionalProperties != nil {
+				if s.AdditionalProperties.reject {
+					*errs = append(*errs, &Error{Path: keyPath(path, k), Message: "unknown field"})
+				} else {
+					s.AdditionalProperties.validate(v[k], keyPath(path, k), errs)
+				}
+			}
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("expected at least %d items, got %d", *s.MinItems, len(v))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("expected at most %d items, got %d", *s.MaxItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%v[%d]", path, i), errs)
+			}
+		}
+
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("expected at least %d characters, got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("expected at most %d characters, got %d", *s.MaxLength, n)
+		}
+		if s.Pattern != "" {
+			re := s.pattern
+			if re == nil {
+				var err error
+				if re, err = regexp.Compile(s.Pattern); err != nil {
+					fail("invalid pattern %q", s.Pattern)
+					break
+				}
+			}
+			if !re.MatchString(v) {
+				fail("value %q does not match pattern %q", v, s.Pattern)
+			}
+		}
+
+	default:
+		if f, ok := toFloat(v); ok {
+			if s.Minimum != nil && f < *s.Minimum {
+				fail("value %v is less than minimum %v", display(v), *s.Minimum)
+			}
+			if s.Maximum != nil && f > *s.Maximum {
+				fail("value %v is greater than maximum %v", display(v), *s.Maximum)
+			}
+		}
+	}
+}
+
+func (t Types) match(v interface{}) bool {
+	for _, name := range t {
+		switch name {
+		case "object":
+			if _, ok := v.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := v.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case "null":
+			if v == nil {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat(v); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := toFloat(v); ok && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t Types) String() string {
+	if len(t) == 1 {
+		return t[0]
+	}
+	return fmt.Sprintf("one of %v", []string(t))
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func keyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+// inEnum returns true if v is equal to one of the enum values, comparing
+// numbers by value regardless of their representation
+func inEnum(v interface{}, enum []interface{}) bool {
+	f, isNumber := toFloat(v)
+	for _, e := range enum {
+		if isNumber {
+			if ef, ok := toFloat(e); ok && ef == f {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(v, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func display(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprint(v)
+}
+
+func displayList(values []interface{}) string {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(display(v))
+	}
+	b.WriteString("]")
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/marcus999/go-config/pkg/schema"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["name", "port"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+		"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+		"level": {"enum": ["debug", "info"]},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+		"ratio": {"type": ["number", "null"]}
+	}
+}`
+
+func parseDoc(t *testing.T, s string) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		t.Fatalf("invalid test document, %v", err)
+	}
+	return doc
+}
+
+func errorStrings(errs []error) (r []string) {
+	for _, err := range errs {
+		r = append(r, err.Error())
+	}
+	return
+}
+
+// ---------------------------------------------------------------------------
+// schema.Parse()
+// ---------------------------------------------------------------------------
+
+func TestParse(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s, err := schema.Parse([]byte(testSchema))
+	assert.That(err, pred.IsNil())
+	assert.That(s.Type, pred.IsEqualTo(schema.Types{"object"}))
+	assert.That(s.Properties["ratio"].Type, pred.IsEqualTo(schema.Types{"number", "null"}))
+	assert.That(s.Required, pred.IsEqualTo([]string{"name", "port"}))
+}
+
+func TestParseInvalid(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := schema.Parse([]byte(`{"type": 3}`))
+	assert.That(err, pred.IsNotNil())
+
+	_, err = schema.Parse([]byte(`{"pattern": "["}`))
+	assert.That(err, pred.IsNotNil())
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s, err := schema.Parse([]byte(testSchema))
+	assert.That(err, pred.IsNil())
+
+	data, err := json.Marshal(s)
+	assert.That(err, pred.IsNil())
+	assert.That(string(data), pred.Contains(`"additionalProperties":false`))
+	assert.That(string(data), pred.Contains(`"type":"object"`))
+
+	s2, err := schema.Parse(data)
+	assert.That(err, pred.IsNil())
+	assert.That(s2.Properties["port"].Type, pred.IsEqualTo(schema.Types{"integer"}))
+}
+
+// ---------------------------------------------------------------------------
+// Schema.Validate()
+// ---------------------------------------------------------------------------
+
+func TestValidateValidDocument(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s, _ := schema.Parse([]byte(testSchema))
+	errs := s.Validate(parseDoc(t, `{
+		"name": "server", "port": 8080, "level": "info",
+		"tags": ["a", "b"], "ratio": null
+	}`))
+	assert.That(errs, pred.IsEmpty())
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s, _ := schema.Parse([]byte(testSchema))
+	errs := s.Validate(parseDoc(t, `{
+		"name": "Server", "port": 70000, "level": "trace",
+		"tags": ["a", 2, "c"], "ratio": "high", "extra": true
+	}`))
+	assert.That(errorStrings(errs), pred.IsEqualTo([]string{
+		`extra: unknown field`,
+		`level: value "trace" is not one of ["debug", "info"]`,
+		`name: value "Server" does not match pattern "^[a-z]+$"`,
+		`port: value 70000 is greater than maximum 65535`,
+		`ratio: expected one of [number null], got string`,
+		`tags: expected at most 2 items, got 3`,
+		`tags[1]: expected string, got number`,
+	}))
+}
+
+func TestValidateRequired(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s, _ := schema.Parse([]byte(testSchema))
+	errs := s.Validate(parseDoc(t, `{"name": "server"}`))
+	assert.That(errorStrings(errs), pred.IsEqualTo([]string{
+		`port: required field is missing`,
+	}))
+
+	errs = s.Validate(parseDoc(t, `[]`))
+	assert.That(errorStrings(errs), pred.IsEqualTo([]string{
+		`expected object, got array`,
+	}))
+}
+
+func TestValidateInteger(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s := &schema.Schema{Type: schema.Types{"integer"}}
+	assert.That(s.Validate(3), pred.IsEmpty())
+	assert.That(s.Validate(json.Number("3")), pred.IsEmpty())
+	assert.That(s.Validate(3.5), pred.Length(pred.IsEqualTo(1)))
+}
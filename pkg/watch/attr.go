@@ -0,0 +1,28 @@
+package watch
+
+import "os"
+
+// WithAttrChanges enables AttrChanged events, sent instead of Updated when only
+// the permissions or ownership of a watched file change. By default, such
+// changes are reported as Updated.
+func WithAttrChanges() Option {
+	return func(o *options) {
+		o.attrChanges = true
+	}
+}
+
+// attrEventType returns AttrChanged if attribute change events are enabled
+// and only the permissions or ownership of the file changed from prev to
+// info, and e otherwise
+func (o *options) attrEventType(e EventType, prev, info os.FileInfo) EventType {
+	if !o.attrChanges || e != Updated || prev == nil || info == nil {
+		return e
+	}
+	if prev.Size() != info.Size() || !prev.ModTime().Equal(info.ModTime()) {
+		return e
+	}
+	if prev.Mode() != info.Mode() || fileOwner(prev) != fileOwner(info) {
+		return AttrChanged
+	}
+	return e
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+package watch_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func chmod(t *testing.T, filename string, mode os.FileMode) {
+	if err := os.Chmod(filename, mode); err != nil {
+		t.Fatalf("failed to chmod '%v', %v", filename, err)
+	}
+}
+
+func TestChmodIsReportedAsUpdatedByDefault(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/secrets.yaml")
+	fs.createFile(target)
+	chmod(t, target, 0644)
+	w, err := watch.NewMultiFileWatcher([]string{target})
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	chmod(t, target, 0600)
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+}
+
+func TestAttrChangedEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/secrets.yaml")
+	fs.createFile(target)
+	chmod(t, target, 0600)
+	w, err := watch.NewMultiFileWatcher([]string{target}, watch.WithAttrChanges())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	chmod(t, target, 0644)
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.AttrChanged))
+	assert.That(e.FileInfo.Mode().Perm(), pred.IsEqualTo(os.FileMode(0644)))
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	e, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+}
+
+func TestPollingWatcherAttrChangedEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/secrets.yaml")
+	fs.createFile(target)
+	chmod(t, target, 0600)
+	w, err := watch.NewPollingWatcher(target, 5*time.Millisecond, watch.WithAttrChanges())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	chmod(t, target, 0644)
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.AttrChanged))
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package watch
+
+import (
+	"os"
+	"syscall"
+)
+
+// owner identifies the owner of a file
+type owner struct {
+	uid, gid uint32
+}
+
+func fileOwner(info os.FileInfo) owner {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return owner{st.Uid, st.Gid}
+	}
+	return owner{}
+}
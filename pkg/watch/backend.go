@@ -0,0 +1,251 @@
+package watch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Backend is the source of raw filesystem notifications of a watcher.
+// Backends follow the semantics of fsnotify: watching a directory reports
+// changes to the directory itself and to its direct entries, and watching a
+// file reports changes to that file.
+type Backend interface {
+	// Add starts watching the named file or directory
+	Add(name string) error
+
+	// Remove stops watching the named file or directory
+	Remove(name string) error
+
+	// Events returns the channel on which raw notifications are sent
+	Events() <-chan fsnotify.Event
+
+	// Errors returns the channel on which errors are sent
+	Errors() <-chan error
+
+	// Close stops watching and releases associated resources
+	Close() error
+}
+
+// BackendFactory creates the backend of a new watcher
+type BackendFactory func() (Backend, error)
+
+// WithBackend sets the backend used by watchers to receive raw filesystem
+// notifications, e.g. to work around platform specific quirks or to support
+// exotic filesystems. The default is NewFsnotifyBackend.
+func WithBackend(f BackendFactory) Option {
+	return func(o *options) {
+		o.backend = f
+	}
+}
+
+// ---------------------------------------------------------------------------
+// fsnotify backend
+// ---------------------------------------------------------------------------
+
+type fsnotifyBackend struct {
+	w *fsnotify.Watcher
+}
+
+// NewFsnotifyBackend creates a backend based on fsnotify, using inotify on
+// Linux, kqueue on BSD and macOS, and ReadDirectoryChangesW on Windows
+func NewFsnotifyBackend() (Backend, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifyBackend{w: w}, nil
+}
+
+func (b *fsnotifyBackend) Add(name string) error         { return b.w.Add(name) }
+func (b *fsnotifyBackend) Remove(name string) error      { return b.w.Remove(name) }
+func (b *fsnotifyBackend) Events() <-chan fsnotify.Event { return b.w.Events }
+func (b *fsnotifyBackend) Errors() <-chan error          { return b.w.Errors }
+func (b *fsnotifyBackend) Close() error                  { return b.w.Close() }
+
+// ---------------------------------------------------------------------------
+// polling backend
+// ---------------------------------------------------------------------------
+
+// PollingBackend returns a factory of backends that detect changes by
+// comparing the state of watched files and directories at regular intervals,
+// for filesystems that do not support notifications, e.g. NFS or FUSE
+func PollingBackend(interval time.Duration) BackendFactory {
+	return func() (Backend, error) {
+		return newPollingBackend(interval)
+	}
+}
+
+func newPollingBackend(interval time.Duration) (*pollingBackend, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid polling interval %v", interval)
+	}
+	b := &pollingBackend{
+		interval: interval,
+		events:   make(chan fsnotify.Event),
+		errors:   make(chan error),
+		watches:  make(map[string]*polledPath),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b, nil
+}
+
+type pollingBackend struct {
+	interval  time.Duration
+	events    chan fsnotify.Event
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	watches map[string]*polledPath
+}
+
+// polledPath is the last known state of a watched path and, for directories,
+// of its entries
+type polledPath struct {
+	info    os.FileInfo
+	entries map[string]os.FileInfo
+}
+
+func readPolledPath(name string) (*polledPath, error) {
+	info, err := os.Lstat(name)
+	if err != nil {
+		return nil, err
+	}
+	p := &polledPath{info: info}
+	if info.IsDir() {
+		p.entries = make(map[string]os.FileInfo)
+		entries, _ := ioutil.ReadDir(name)
+		for _, entry := range entries {
+			p.entries[entry.Name()] = entry
+		}
+	}
+	return p, nil
+}
+
+func (b *pollingBackend) Add(name string) error {
+	name = filepath.Clean(name)
+	p, err := readPolledPath(name)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.watches[name]; !ok {
+		b.watches[name] = p
+	}
+	return nil
+}
+
+func (b *pollingBackend) Remove(name string) error {
+	name = filepath.Clean(name)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.watches[name]; !ok {
+		return fmt.Errorf("can't remove non-existent watch for '%v'", name)
+	}
+	delete(b.watches, name)
+	return nil
+}
+
+func (b *pollingBackend) Events() <-chan fsnotify.Event { return b.events }
+func (b *pollingBackend) Errors() <-chan error          { return b.errors }
+
+func (b *pollingBackend) Close() error {
+	b.closeOnce.Do(func() { close(b.done) })
+	return nil
+}
+
+func (b *pollingBackend) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.done:
+			return
+		}
+
+		for _, ev := range b.poll() {
+			select {
+			case b.events <- ev:
+			case <-b.done:
+				return
+			}
+		}
+	}
+}
+
+// poll compares the current state of all watched paths with their last known
+// state, and returns the corresponding events
+func (b *pollingBackend) poll() []fsnotify.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var events []fsnotify.Event
+	for name, prev := range b.watches {
+		cur, err := readPolledPath(name)
+		if err != nil || !os.SameFile(prev.info, cur.info) {
+			events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Remove})
+			delete(b.watches, name)
+			continue
+		}
+		if op := attrOp(prev.info, cur.info); op != 0 && !cur.info.IsDir() {
+			events = append(events, fsnotify.Event{Name: name, Op: op})
+		}
+		for entry, info := range cur.entries {
+			path := filepath.Join(name, entry)
+			prevInfo, ok := prev.entries[entry]
+			if !ok || !os.SameFile(prevInfo, info) {
+				events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Create})
+			} else if op := attrOp(prevInfo, info); op != 0 && !info.IsDir() {
+				events = append(events, fsnotify.Event{Name: path, Op: op})
+			}
+		}
+		for entry := range prev.entries {
+			if _, ok := cur.entries[entry]; !ok {
+				path := filepath.Join(name, entry)
+				events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Remove})
+			}
+		}
+		b.watches[name] = cur
+	}
+	return events
+}
+
+// refresh updates the last known state of the watched paths affected by a
+// change of name, so that the change is not reported again
+func (b *pollingBackend) refresh(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, path := range []string{name, filepath.Dir(name)} {
+		if _, ok := b.watches[path]; !ok {
+			continue
+		}
+		if p, err := readPolledPath(path); err == nil {
+			b.watches[path] = p
+		} else {
+			delete(b.watches, path)
+		}
+	}
+}
+
+// attrOp returns the fsnotify operation corresponding to the change of a file
+// from prev to cur, or 0 if it did not change
+func attrOp(prev, cur os.FileInfo) fsnotify.Op {
+	switch {
+	case prev.Size() != cur.Size() || !prev.ModTime().Equal(cur.ModTime()):
+		return fsnotify.Write
+	case prev.Mode() != cur.Mode():
+		return fsnotify.Chmod
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestMultiFileWatcherWithPollingBackend(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	w, err := watch.NewMultiFileWatcher([]string{target},
+		watch.WithBackend(watch.PollingBackend(5*time.Millisecond)),
+	)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.createFile(target)
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Created, Path: target}))
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	e, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: target}))
+
+	fs.delete("path/to")
+	e, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Deleted, Path: target}))
+}
+
+func TestGlobWatcherWithPollingBackend(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	fs.mkDir("conf.d")
+	w, err := watch.NewGlobWatcher(fs.expandFilename("conf.d/*.yaml"),
+		watch.WithBackend(watch.PollingBackend(5*time.Millisecond)),
+	)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.createFile("conf.d/a.yaml")
+	fs.createFile("conf.d/a.txt")
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{
+		Type: watch.Created,
+		Path: fs.expandFilename("conf.d/a.yaml"),
+	}))
+	assert.That(w.Files(), pred.IsEqualTo([]string{fs.expandFilename("conf.d/a.yaml")}))
+}
+
+func TestInvalidPollingBackendInterval(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := watch.NewMultiFileWatcher([]string{"file.yaml"},
+		watch.WithBackend(watch.PollingBackend(0)),
+	)
+	assert.That(err, pred.IsNotNil())
+}
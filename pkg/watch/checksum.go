@@ -0,0 +1,23 @@
+package watch
+
+import "crypto/sha256"
+
+// WithChecksum enables content based filtering of events: watched files are
+// hashed on every change, and Updated events are suppressed when the content
+// of the file did not change, e.g. on chmod or touch.
+func WithChecksum() Option {
+	return func(o *options) {
+		o.checksum = true
+	}
+}
+
+// fileChecksum returns the SHA-256 checksum of the content of filename, or nil
+// if it cannot be read
+func fileChecksum(filename string) []byte {
+	content, err := readFile(filename)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
@@ -0,0 +1,99 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/debounce"
+)
+
+// DebouncedFileWatcher is a FileWatcher whose events are debounced: events
+// occurring less than the debounce interval apart are grouped together, and
+// a single event reflecting the overall change is sent for each group.
+type DebouncedFileWatcher struct {
+	watcher *FileWatcher
+	out     chan Event
+	done    chan struct{}
+}
+
+// NewDebouncedFileWatcher creates a new FileWatcher whose events are
+// debounced over interval, without delaying any event by more than maxDelay
+// if it is non-zero
+func NewDebouncedFileWatcher(filename string, interval, maxDelay time.Duration, opts ...Option) (*DebouncedFileWatcher, error) {
+	return NewDebouncedFileWatcherWithContext(context.Background(), filename, interval, maxDelay, opts...)
+}
+
+// NewDebouncedFileWatcherWithContext creates a new debounced FileWatcher with
+// an explicit cancelation context
+func NewDebouncedFileWatcherWithContext(ctx context.Context, filename string, interval, maxDelay time.Duration, opts ...Option) (*DebouncedFileWatcher, error) {
+	fw, err := NewFileWatcherWithContext(ctx, filename, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &DebouncedFileWatcher{
+		watcher: fw,
+		out:     make(chan Event),
+		done:    make(chan struct{}),
+	}
+	in, groups := debounce.NewGroupedOf[Event](interval, maxDelay)
+	go func() {
+		for e := range fw.Events() {
+			in <- e
+		}
+		close(in)
+	}()
+	go w.run(groups)
+	return w, nil
+}
+
+// Info returns the FileInfo of the watched file, or nil if there is no file
+// at the watched location
+func (w *DebouncedFileWatcher) Info() os.FileInfo {
+	return w.watcher.Info()
+}
+
+// Events returns the readable channel on which debounced events are sent
+func (w *DebouncedFileWatcher) Events() <-chan Event {
+	return w.out
+}
+
+// Errors returns the readable channel on which errors reported by the
+// underlying notification mechanism are sent
+func (w *DebouncedFileWatcher) Errors() <-chan error {
+	return w.watcher.Errors()
+}
+
+// Done returns a channel that is closed once the watcher has stopped, after a
+// call to Close or when its context is canceled
+func (w *DebouncedFileWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close stops the watcher and releases associated resources, returning once
+// the watcher has stopped. It can safely be called multiple times.
+func (w *DebouncedFileWatcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+func (w *DebouncedFileWatcher) run(groups <-chan []Event) {
+	defer close(w.done)
+	defer close(w.out)
+
+	for group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		e := group[0]
+		for _, next := range group[1:] {
+			e = coalesce(e, next)
+		}
+		select {
+		case w.out <- e:
+		case <-w.watcher.ctx.Done():
+		}
+	}
+}
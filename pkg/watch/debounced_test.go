@@ -0,0 +1,41 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestDebouncedFileWatcher(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	w, err := watch.NewDebouncedFileWatcher(target, 50*time.Millisecond, 0)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	fs.createFile(target)
+	time.Sleep(10 * time.Millisecond)
+	fs.appendToFile(target, []byte("aaa\n"))
+	time.Sleep(10 * time.Millisecond)
+	fs.appendToFile(target, []byte("bbb\n"))
+
+	e, _, timeout := readEventChannel(w.Events(), 2*defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Created, Path: target}))
+	assert.That(e.FileInfo.Size(), pred.IsEqualTo(int64(8)))
+
+	_, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+
+	assert.That(w.Close(), pred.IsNil())
+	_, ok := <-w.Events()
+	assert.That(ok, pred.IsEqualTo(false))
+}
@@ -0,0 +1,113 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DirWatcher watches the regular files of a directory as a whole, e.g. a
+// conf.d drop-in directory, and sends a single Updated event for the directory
+// whenever any of its files is created, updated or deleted. Combined with
+// WithCoalescing, a burst of changes results in a single event.
+type DirWatcher struct {
+	options
+	dir   string
+	glob  *GlobWatcher
+	queue *eventQueue
+	done  chan struct{}
+}
+
+// NewDirWatcher creates a new DirWatcher
+func NewDirWatcher(dir string, opts ...Option) (*DirWatcher, error) {
+	return NewDirWatcherWithContext(context.Background(), dir, opts...)
+}
+
+// NewDirWatcherWithContext creates a new DirWatcher with an explicit
+// cancelation context
+func NewDirWatcherWithContext(ctx context.Context, dir string, opts ...Option) (*DirWatcher, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Coalescing and initial events apply to the directory as a whole, not
+	// to individual files
+	o := newOptions(opts)
+	fileOpts := append(append([]Option{}, opts...), WithCoalescing(0), func(o *options) {
+		o.initialEvent = false
+	})
+	glob, err := NewGlobWatcherWithContext(ctx, filepath.Join(dir, "*"), fileOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &DirWatcher{
+		options: o,
+		dir:     dir,
+		glob:    glob,
+		done:    make(chan struct{}),
+	}
+	w.queue = newEventQueue(glob.ctx, o, 1)
+	go w.run()
+	return w, nil
+}
+
+// Files returns the sorted list of regular files currently in the directory
+func (w *DirWatcher) Files() []string {
+	return w.glob.Files()
+}
+
+// Events returns the readable channel on which events are sent
+func (w *DirWatcher) Events() <-chan Event {
+	return w.queue.out
+}
+
+// Errors returns the readable channel on which errors reported by the
+// underlying notification mechanism are sent. The channel is closed when the
+// watcher stops.
+func (w *DirWatcher) Errors() <-chan error {
+	return w.glob.Errors()
+}
+
+// Dropped returns the number of events dropped or coalesced according to the
+// overflow policy
+func (w *DirWatcher) Dropped() uint64 {
+	return w.queue.Dropped()
+}
+
+// Done returns a channel that is closed once the watcher has stopped, after a
+// call to Close or when its context is canceled
+func (w *DirWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close stops the watcher and releases associated resources, returning once
+// the watcher has stopped. It can safely be called multiple times.
+func (w *DirWatcher) Close() error {
+	err := w.glob.Close()
+	<-w.done
+	return err
+}
+
+func (w *DirWatcher) run() {
+	defer close(w.done)
+	defer w.queue.wait()
+
+	if w.initialEvent {
+		if info, err := os.Stat(w.dir); err == nil && info.IsDir() {
+			w.queue.push(Event{Type: Created, Path: w.dir, FileInfo: info, Time: time.Now()})
+		}
+	}
+	for e := range w.glob.Events() {
+		info, _ := os.Stat(w.dir)
+		w.queue.push(Event{
+			Type:       Updated,
+			Path:       w.dir,
+			FileInfo:   info,
+			Time:       e.Time,
+			Underlying: e.Underlying,
+		})
+	}
+}
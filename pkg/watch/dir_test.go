@@ -0,0 +1,64 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestDirWatcher(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	dir := fs.expandFilename("conf.d")
+	w, err := watch.NewDirWatcher(dir)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.createFile("conf.d/a.yaml")
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: dir}))
+	assert.That(w.Files(), pred.IsEqualTo([]string{fs.expandFilename("conf.d/a.yaml")}))
+
+	fs.delete("conf.d/a.yaml")
+	e, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: dir}))
+}
+
+func TestDirWatcherWithCoalescing(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	dir := fs.expandFilename("conf.d")
+	fs.mkDir("conf.d")
+	w, err := watch.NewDirWatcher(dir,
+		watch.WithCoalescing(50*time.Millisecond),
+		watch.WithInitialEvent(),
+	)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Created, Path: dir}))
+
+	fs.createFile("conf.d/a.yaml")
+	fs.createFile("conf.d/b.yaml")
+	time.Sleep(10 * time.Millisecond)
+	fs.appendToFile("conf.d/a.yaml", []byte("aaa\n"))
+
+	e, _, timeout = readEventChannel(w.Events(), 2*defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: dir}))
+
+	_, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
@@ -0,0 +1,26 @@
+package watch
+
+import "fmt"
+
+// errorBufferSize is the number of errors buffered for a slow consumer. Errors
+// beyond that are logged and dropped, so that a watcher never blocks on errors
+// that nobody reads.
+const errorBufferSize = 8
+
+func newErrorChannel() chan error {
+	return make(chan error, errorBufferSize)
+}
+
+// reportError logs err and sends it on ch unless ch is full
+func reportError(ch chan error, logger Logger, err error) {
+	logger.Printf("watch: %v", err)
+	select {
+	case ch <- err:
+	default:
+	}
+}
+
+// watchError wraps an error returned by fsnotify when adding a watch on path
+func watchError(path string, err error) error {
+	return fmt.Errorf("failed to watch '%v': %w", path, err)
+}
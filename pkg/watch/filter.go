@@ -0,0 +1,119 @@
+package watch
+
+import (
+	"bytes"
+	"os"
+	"time"
+)
+
+// WithIgnoreEmptyFiles suppresses Created and Updated events while the file
+// is empty, e.g. between the truncate and the write of a writer rewriting the
+// file in place.
+func WithIgnoreEmptyFiles() Option {
+	return func(o *options) {
+		o.ignoreEmpty = true
+	}
+}
+
+// WithRequireMtimeAdvance suppresses Updated events when the modification
+// time of the file did not advance since the last event sent for it.
+func WithRequireMtimeAdvance() Option {
+	return func(o *options) {
+		o.requireMtimeAdvance = true
+	}
+}
+
+// eventFilter suppresses the events that do not reflect a meaningful change of
+// a watched file according to the watcher options, based on the last known
+// checksum and modification time of each file. A nil filter lets all events
+// through.
+type eventFilter struct {
+	checksum            bool
+	ignoreEmpty         bool
+	requireMtimeAdvance bool
+
+	sums   map[string][]byte
+	mtimes map[string]time.Time
+}
+
+func newEventFilter(o options, filenames ...string) *eventFilter {
+	if !o.checksum && !o.ignoreEmpty && !o.requireMtimeAdvance {
+		return nil
+	}
+	f := &eventFilter{
+		checksum:            o.checksum,
+		ignoreEmpty:         o.ignoreEmpty,
+		requireMtimeAdvance: o.requireMtimeAdvance,
+		sums:                make(map[string][]byte),
+		mtimes:              make(map[string]time.Time),
+	}
+	for _, filename := range filenames {
+		f.add(filename)
+	}
+	return f
+}
+
+// add starts tracking the state of filename
+func (f *eventFilter) add(filename string) {
+	if f == nil {
+		return
+	}
+	info, err := os.Stat(filename)
+	if err != nil || info.IsDir() {
+		return
+	}
+	f.record(filename, info)
+}
+
+// remove stops tracking the state of filename
+func (f *eventFilter) remove(filename string) {
+	if f != nil {
+		delete(f.sums, filename)
+		delete(f.mtimes, filename)
+	}
+}
+
+func (f *eventFilter) record(filename string, info os.FileInfo) {
+	if f.checksum {
+		if sum := fileChecksum(filename); sum != nil {
+			f.sums[filename] = sum
+		}
+	}
+	f.mtimes[filename] = info.ModTime()
+}
+
+// accept updates the known state of the file after event e, and reports
+// whether the event should be sent
+func (f *eventFilter) accept(e Event) bool {
+	if f == nil || e.Type == AttrChanged {
+		return true
+	}
+	if e.Type == Deleted || e.Type == Renamed {
+		f.remove(e.Path)
+		return true
+	}
+
+	info := e.FileInfo
+	if info == nil {
+		if info, _ = os.Stat(e.Path); info == nil {
+			return true
+		}
+	}
+	if f.ignoreEmpty && info.Size() == 0 {
+		return false
+	}
+	if f.requireMtimeAdvance && e.Type == Updated {
+		if last, ok := f.mtimes[e.Path]; ok && !info.ModTime().After(last) {
+			return false
+		}
+	}
+	if f.checksum && e.Type == Updated {
+		prev, known := f.sums[e.Path]
+		if known && bytes.Equal(prev, fileChecksum(e.Path)) {
+			f.mtimes[e.Path] = info.ModTime()
+			return false
+		}
+	}
+	f.record(e.Path, info)
+	return true
+}
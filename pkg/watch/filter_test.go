@@ -0,0 +1,121 @@
+package watch_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func touch(t *testing.T, filename string) {
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatalf("failed to touch '%v', %v", filename, err)
+	}
+}
+
+func TestTouchIsReportedWithoutChecksum(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target})
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	touch(t, target)
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+}
+
+func TestChecksumSuppressesUnchangedContent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target}, watch.WithChecksum())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	touch(t, target)
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+}
+
+func TestPollingWatcherWithChecksum(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewPollingWatcher(target, 5*time.Millisecond, watch.WithChecksum())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	touch(t, target)
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
+
+func TestIgnoreEmptyFiles(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.appendToFile(target, []byte("aaa\n"))
+	w, err := watch.NewMultiFileWatcher([]string{target}, watch.WithIgnoreEmptyFiles())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	if err := os.Truncate(target, 0); err != nil {
+		t.Fatalf("failed to truncate '%v', %v", target, err)
+	}
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+
+	fs.appendToFile(target, []byte("bbb\n"))
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+	assert.That(e.FileInfo.Size(), pred.IsEqualTo(int64(4)))
+}
+
+func TestRequireMtimeAdvance(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target}, watch.WithRequireMtimeAdvance())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	earlier := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(target, earlier, earlier); err != nil {
+		t.Fatalf("failed to touch '%v', %v", target, err)
+	}
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+
+	touch(t, target)
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+}
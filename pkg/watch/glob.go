@@ -0,0 +1,291 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// GlobWatcher watches all files matching a glob pattern, e.g.
+// "/etc/myapp/conf.d/*.yaml", including files created after the watcher
+// starts. Wildcards are only supported in the last element of the pattern.
+type GlobWatcher struct {
+	options
+	pattern string
+	dir     string
+	watcher Backend
+	paths   []string
+	filter  *eventFilter
+
+	mu    sync.Mutex
+	files map[string]os.FileInfo
+
+	queue    *eventQueue
+	errs     chan error
+	done     chan struct{}
+	closeErr error
+	ctx      context.Context
+	cancel   func()
+}
+
+// NewGlobWatcher creates a new GlobWatcher
+func NewGlobWatcher(pattern string, opts ...Option) (*GlobWatcher, error) {
+	return NewGlobWatcherWithContext(context.Background(), pattern, opts...)
+}
+
+// NewGlobWatcherWithContext creates a new GlobWatcher with an explicit
+// cancelation context
+func NewGlobWatcherWithContext(ctx context.Context, pattern string, opts ...Option) (*GlobWatcher, error) {
+	pattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(pattern)
+	if strings.ContainsAny(dir, "*?[") {
+		return nil, fmt.Errorf("invalid pattern '%v', wildcards are only supported in the file name", pattern)
+	}
+
+	o := newOptions(opts)
+	n, err := o.backend()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &GlobWatcher{
+		options: o,
+		pattern: pattern,
+		dir:     dir,
+		watcher: n,
+		files:   make(map[string]os.FileInfo),
+		errs:    newErrorChannel(),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	w.queue = newEventQueue(ctx, w.options, 1)
+	w.locate()
+	for _, filename := range w.matches() {
+		w.files[filename], _ = os.Stat(filename)
+	}
+	w.filter = newEventFilter(w.options, sortedFilenames(w.files)...)
+	go w.run()
+
+	return w, nil
+}
+
+// Files returns the sorted list of files currently matching the pattern
+func (w *GlobWatcher) Files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return sortedFilenames(w.files)
+}
+
+// Events returns the readable channel on which events are sent
+func (w *GlobWatcher) Events() <-chan Event {
+	return w.queue.out
+}
+
+// Dropped returns the number of events dropped or coalesced according to the
+// overflow policy
+func (w *GlobWatcher) Dropped() uint64 {
+	return w.queue.Dropped()
+}
+
+// Errors returns the readable channel on which errors reported by the
+// underlying notification mechanism are sent. The channel is closed when the
+// watcher stops. Errors are dropped if they are not consumed.
+func (w *GlobWatcher) Errors() <-chan error {
+	return w.errs
+}
+
+// Done returns a channel that is closed once the watcher has stopped, after a
+// call to Close or when its context is canceled
+func (w *GlobWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close stops the watcher and releases associated resources, returning once
+// the watcher has stopped. It can safely be called multiple times.
+func (w *GlobWatcher) Close() error {
+	w.cancel()
+	<-w.done
+	return w.closeErr
+}
+
+func (w *GlobWatcher) run() {
+	defer close(w.done)
+	defer w.queue.wait()
+	defer close(w.errs)
+	if w.initialEvent {
+		w.mu.Lock()
+		files := make(map[string]os.FileInfo, len(w.files))
+		for filename, info := range w.files {
+			files[filename] = info
+		}
+		w.mu.Unlock()
+		for _, filename := range sortedFilenames(files) {
+			w.send(Created, filename, files[filename], 0)
+		}
+	}
+	for {
+		select {
+		case ev := <-w.watcher.Events():
+			ev.Name = filepath.Clean(ev.Name)
+			w.logger.Printf("watch: %v", ev)
+			if matched, _ := filepath.Match(w.pattern, ev.Name); matched {
+				w.handleFileEvent(&ev)
+			} else if isPathPrefix(ev.Name, w.dir) {
+				w.locate()
+				w.rescan()
+			}
+
+		case err := <-w.watcher.Errors():
+			if err != nil {
+				reportError(w.errs, w.logger, err)
+			}
+			w.locate()
+			w.rescan()
+
+		case <-w.ctx.Done():
+			w.closeErr = w.watcher.Close()
+			return
+		}
+	}
+}
+
+func (w *GlobWatcher) handleFileEvent(ev *fsnotify.Event) {
+	info, _ := os.Stat(ev.Name)
+	if info != nil && info.IsDir() {
+		info = nil
+	}
+
+	w.mu.Lock()
+	prev, known := w.files[ev.Name]
+	if info == nil {
+		delete(w.files, ev.Name)
+	} else {
+		w.files[ev.Name] = info
+	}
+	w.mu.Unlock()
+
+	switch {
+	case info == nil && known:
+		e, newPath := removal(ev, ev.Name, prev)
+		w.emit(Event{
+			Type:       e,
+			Path:       ev.Name,
+			NewPath:    newPath,
+			Time:       time.Now(),
+			Underlying: ev.Op,
+		})
+	case info != nil && !known:
+		w.send(Created, ev.Name, info, ev.Op)
+	case info != nil:
+		w.send(w.attrEventType(Updated, prev, info), ev.Name, info, ev.Op)
+	}
+}
+
+// rescan compares the files matching the pattern with the known files, and
+// sends the corresponding events, e.g. after the directory is moved into or
+// out of place
+func (w *GlobWatcher) rescan() {
+	current := make(map[string]os.FileInfo)
+	for _, filename := range w.matches() {
+		current[filename], _ = os.Stat(filename)
+	}
+
+	w.mu.Lock()
+	previous := w.files
+	w.files = current
+	w.mu.Unlock()
+
+	for _, filename := range sortedFilenames(previous) {
+		if _, ok := current[filename]; !ok {
+			w.send(Deleted, filename, nil, 0)
+		}
+	}
+	for _, filename := range sortedFilenames(current) {
+		info := current[filename]
+		if previousInfo, ok := previous[filename]; !ok {
+			w.send(Created, filename, info, 0)
+		} else if !os.SameFile(previousInfo, info) {
+			w.send(Updated, filename, info, 0)
+		}
+	}
+}
+
+// matches returns the regular files matching the pattern
+func (w *GlobWatcher) matches() []string {
+	matches, _ := filepath.Glob(w.pattern)
+	var files []string
+	for _, filename := range matches {
+		if info, err := os.Stat(filename); err == nil && !info.IsDir() {
+			files = append(files, filename)
+		}
+	}
+	return files
+}
+
+// locate updates the fsnotify watches to match the closest existing location
+// of the directory and all its parents
+func (w *GlobWatcher) locate() {
+	for _, p := range w.paths {
+		w.watcher.Remove(p)
+	}
+	w.paths = w.paths[:0]
+
+	path, _ := watchLocation(w.dir)
+	for {
+		if err := w.watcher.Add(path); err != nil {
+			if os.IsNotExist(err) {
+				w.logger.Printf("watch: failed to watch '%v', %v", path, err)
+			} else {
+				reportError(w.errs, w.logger, watchError(path, err))
+			}
+		} else {
+			w.paths = append(w.paths, path)
+		}
+		next, ok := parentDir(path)
+		if !ok {
+			break
+		}
+		path = next
+	}
+}
+
+func (w *GlobWatcher) send(e EventType, filename string, info os.FileInfo, op fsnotify.Op) {
+	w.emit(Event{
+		Type:       e,
+		Path:       filename,
+		FileInfo:   info,
+		Time:       time.Now(),
+		Underlying: op,
+	})
+}
+
+func (w *GlobWatcher) emit(e Event) {
+	if !w.filter.accept(e) {
+		return
+	}
+	w.queue.push(e)
+}
+
+func sortedFilenames(files map[string]os.FileInfo) []string {
+	var filenames []string
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
@@ -0,0 +1,106 @@
+package watch_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestGlobWatchMatchingFiles(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	existing := fs.expandFilename("conf.d/10-existing.yaml")
+	created := fs.expandFilename("conf.d/20-created.yaml")
+	fs.createFile(existing)
+	fs.createFile("conf.d/README.md")
+
+	w, err := watch.NewGlobWatcher(fs.expandFilename("conf.d/*.yaml"))
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	assert.That(w.Files(), pred.IsEqualTo([]string{existing}))
+
+	e, ok, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+
+	fs.createFile(created)
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Created, Path: created}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(w.Files(), pred.IsEqualTo([]string{existing, created}))
+
+	fs.appendToFile(existing, []byte("aaa\n"))
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: existing}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+
+	fs.appendToFile("conf.d/README.md", []byte("aaa\n"))
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+
+	fs.delete(existing)
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Deleted, Path: existing}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(w.Files(), pred.IsEqualTo([]string{created}))
+
+	w.Close()
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(ok, pred.IsEqualTo(false), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(timeout, pred.IsEqualTo(false), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+}
+
+func TestGlobWatchMovingFolderIntoPlace(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	fs.createFile("staging/app.yaml")
+	w, err := watch.NewGlobWatcher(fs.expandFilename("conf.d/*.yaml"))
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	defer w.Close()
+	assert.That(w.Files(), pred.IsEmpty())
+
+	fs.move("staging", "conf.d")
+	e, ok, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{
+		Type: watch.Created,
+		Path: fs.expandFilename("conf.d/app.yaml"),
+	}), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+}
+
+func TestGlobWatchInvalidPattern(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := watch.NewGlobWatcher("conf.d/[.yaml")
+	assert.That(err, pred.IsNotNil())
+
+	_, err = watch.NewGlobWatcher("conf.*/app.yaml")
+	assert.That(err, pred.IsNotNil())
+}
+
+func TestGlobWatcherInitialEvents(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	fs.createFile("conf.d/b.yaml")
+	fs.createFile("conf.d/a.yaml")
+	w, err := watch.NewGlobWatcher(fs.expandFilename("conf.d/*.yaml"), watch.WithInitialEvent())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	var events []watch.Event
+	for i := 0; i < 2; i++ {
+		e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+		assert.That(timeout, pred.IsEqualTo(false))
+		events = append(events, brief(e))
+	}
+	assert.That(events, pred.IsEqualTo([]watch.Event{
+		{Type: watch.Created, Path: fs.expandFilename("conf.d/a.yaml")},
+		{Type: watch.Created, Path: fs.expandFilename("conf.d/b.yaml")},
+	}))
+}
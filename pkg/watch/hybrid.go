@@ -0,0 +1,129 @@
+package watch
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// HybridBackend returns a factory of backends combining the notifications of
+// the notify backend with low frequency polling, for network filesystems like
+// NFS or SMB that often deliver no notifications at all. Polling only reports
+// changes that were not notified, with a delay of up to twice the interval.
+// If the notify backend cannot be created, the backend falls back to polling
+// alone.
+func HybridBackend(notify BackendFactory, interval time.Duration) BackendFactory {
+	return func() (Backend, error) {
+		poll, err := newPollingBackend(interval)
+		if err != nil {
+			return nil, err
+		}
+		n, err := notify()
+		if err != nil {
+			return poll, nil
+		}
+
+		b := &hybridBackend{
+			notify: n,
+			poll:   poll,
+			events: make(chan fsnotify.Event),
+			errors: make(chan error),
+			done:   make(chan struct{}),
+		}
+		go b.run()
+		return b, nil
+	}
+}
+
+type hybridBackend struct {
+	notify    Backend
+	poll      *pollingBackend
+	events    chan fsnotify.Event
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (b *hybridBackend) Add(name string) error {
+	if err := b.notify.Add(name); err != nil {
+		return err
+	}
+	return b.poll.Add(name)
+}
+
+func (b *hybridBackend) Remove(name string) error {
+	b.poll.Remove(name)
+	return b.notify.Remove(name)
+}
+
+func (b *hybridBackend) Events() <-chan fsnotify.Event { return b.events }
+func (b *hybridBackend) Errors() <-chan error          { return b.errors }
+
+func (b *hybridBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		b.poll.Close()
+		err = b.notify.Close()
+	})
+	return err
+}
+
+// run forwards the events of both backends, refreshing the polled state on
+// every notification so that the poller only reports missed changes. Polled
+// events are held back for one polling interval and dropped if the same
+// change is notified in the meantime.
+func (b *hybridBackend) run() {
+	var held []heldPollEvent
+	for {
+		var expired <-chan time.Time
+		if len(held) > 0 {
+			expired = time.After(time.Until(held[0].deadline))
+		}
+
+		var ev fsnotify.Event
+		select {
+		case ev = <-b.notify.Events():
+			b.poll.refresh(ev.Name)
+			held = dropHeldEvents(held, ev.Name)
+		case ev := <-b.poll.events:
+			held = append(held, heldPollEvent{ev, time.Now().Add(b.poll.interval)})
+			continue
+		case <-expired:
+			ev = held[0].ev
+			held = held[1:]
+		case err := <-b.notify.Errors():
+			select {
+			case b.errors <- err:
+			case <-b.done:
+				return
+			}
+			continue
+		case <-b.done:
+			return
+		}
+
+		select {
+		case b.events <- ev:
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// heldPollEvent is a polled event waiting for the end of its grace period
+type heldPollEvent struct {
+	ev       fsnotify.Event
+	deadline time.Time
+}
+
+func dropHeldEvents(held []heldPollEvent, name string) []heldPollEvent {
+	kept := held[:0]
+	for _, h := range held {
+		if h.ev.Name != name {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,73 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// silentBackend accepts watches but never delivers any notification, like
+// inotify on most network filesystems
+type silentBackend struct {
+	events chan fsnotify.Event
+	errors chan error
+}
+
+func newSilentBackend() (watch.Backend, error) {
+	return &silentBackend{
+		events: make(chan fsnotify.Event),
+		errors: make(chan error),
+	}, nil
+}
+
+func (b *silentBackend) Add(name string) error         { return nil }
+func (b *silentBackend) Remove(name string) error      { return nil }
+func (b *silentBackend) Events() <-chan fsnotify.Event { return b.events }
+func (b *silentBackend) Errors() <-chan error          { return b.errors }
+func (b *silentBackend) Close() error                  { return nil }
+
+func TestHybridBackendReportsMissedChanges(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target},
+		watch.WithBackend(watch.HybridBackend(newSilentBackend, 10*time.Millisecond)),
+	)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: target}))
+}
+
+func TestHybridBackendDoesNotDuplicateNotifiedChanges(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target},
+		watch.WithBackend(watch.HybridBackend(watch.NewFsnotifyBackend, 50*time.Millisecond)),
+	)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Underlying, pred.IsEqualTo(fsnotify.Write))
+
+	_, _, timeout = readEventChannel(w.Events(), 2*defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
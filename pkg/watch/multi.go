@@ -0,0 +1,387 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// MultiFileWatcher watches a set of filesystem locations with a single
+// underlying fsnotify watcher, and notifies all changes on a single channel.
+// Each location is watched with the same semantics as FileWatcher.
+type MultiFileWatcher struct {
+	options
+	targets []*multiTarget
+	watcher Backend
+	watched map[string]int
+	calls   chan func()
+	filter  *eventFilter
+	mu      sync.Mutex
+
+	queue    *eventQueue
+	errs     chan error
+	done     chan struct{}
+	closeErr error
+	ctx      context.Context
+	cancel   func()
+}
+
+// multiTarget is the state of one location watched by a MultiFileWatcher
+type multiTarget struct {
+	filename   string
+	fileInfo   os.FileInfo
+	resolved   string
+	target     string
+	targetStat os.FileInfo
+	paths      []string
+}
+
+func newMultiTarget(filename string) (*multiTarget, error) {
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	t := &multiTarget{filename: filename}
+	if info, _ := os.Stat(filename); info != nil && !info.IsDir() {
+		t.fileInfo = info
+	}
+	t.resolved = resolveSymlinks(filename)
+	return t, nil
+}
+
+// NewMultiFileWatcher creates a new MultiFileWatcher
+func NewMultiFileWatcher(filenames []string, opts ...Option) (*MultiFileWatcher, error) {
+	return NewMultiFileWatcherWithContext(context.Background(), filenames, opts...)
+}
+
+// NewMultiFileWatcherWithContext creates a new MultiFileWatcher with an
+// explicit cancelation context
+func NewMultiFileWatcherWithContext(ctx context.Context, filenames []string, opts ...Option) (*MultiFileWatcher, error) {
+	var targets []*multiTarget
+	for _, filename := range filenames {
+		t, err := newMultiTarget(filename)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+
+	o := newOptions(opts)
+	n, err := o.backend()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &MultiFileWatcher{
+		options: o,
+		targets: targets,
+		watcher: n,
+		watched: make(map[string]int),
+		calls:   make(chan func()),
+		errs:    newErrorChannel(),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	w.queue = newEventQueue(ctx, w.options, len(targets))
+	var targetFilenames []string
+	for _, t := range targets {
+		targetFilenames = append(targetFilenames, t.filename)
+	}
+	w.filter = newEventFilter(w.options, targetFilenames...)
+	for _, t := range w.targets {
+		w.locate(t)
+	}
+	go w.run()
+
+	return w, nil
+}
+
+// Info returns the FileInfo of the file at the watched location filename, or
+// nil if there is no file at that location or if it is not watched
+func (w *MultiFileWatcher) Info(filename string) os.FileInfo {
+	filename, _ = filepath.Abs(filename)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, t := range w.targets {
+		if t.filename == filename {
+			return t.fileInfo
+		}
+	}
+	return nil
+}
+
+// Add starts watching the location filename, without sending an event for
+// the file currently at that location, if any. Adding a location that is
+// already watched has no effect.
+func (w *MultiFileWatcher) Add(filename string) error {
+	t, err := newMultiTarget(filename)
+	if err != nil {
+		return err
+	}
+	return w.call(func() error {
+		if w.find(t.filename) != -1 {
+			return nil
+		}
+		w.mu.Lock()
+		w.targets = append(w.targets, t)
+		w.mu.Unlock()
+		w.filter.add(t.filename)
+		w.locate(t)
+		return nil
+	})
+}
+
+// Remove stops watching the location filename
+func (w *MultiFileWatcher) Remove(filename string) error {
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	return w.call(func() error {
+		i := w.find(filename)
+		if i == -1 {
+			return fmt.Errorf("'%v' is not watched", filename)
+		}
+		t := w.targets[i]
+		for _, p := range t.paths {
+			w.removeWatch(p)
+		}
+		w.mu.Lock()
+		w.targets = append(w.targets[:i:i], w.targets[i+1:]...)
+		w.mu.Unlock()
+		w.filter.remove(t.filename)
+		return nil
+	})
+}
+
+// call runs f on the goroutine of the watcher, which owns its state
+func (w *MultiFileWatcher) call(f func() error) error {
+	result := make(chan error, 1)
+	select {
+	case w.calls <- func() { result <- f() }:
+		return <-result
+	case <-w.done:
+		return fmt.Errorf("watcher is closed")
+	}
+}
+
+func (w *MultiFileWatcher) find(filename string) int {
+	for i, t := range w.targets {
+		if t.filename == filename {
+			return i
+		}
+	}
+	return -1
+}
+
+// Events returns the readable channel on which events are sent
+func (w *MultiFileWatcher) Events() <-chan Event {
+	return w.queue.out
+}
+
+// Dropped returns the number of events dropped or coalesced according to the
+// overflow policy
+func (w *MultiFileWatcher) Dropped() uint64 {
+	return w.queue.Dropped()
+}
+
+// Errors returns the readable channel on which errors reported by the
+// underlying notification mechanism are sent. The channel is closed when the
+// watcher stops. Errors are dropped if they are not consumed.
+func (w *MultiFileWatcher) Errors() <-chan error {
+	return w.errs
+}
+
+// Done returns a channel that is closed once the watcher has stopped, after a
+// call to Close or when its context is canceled
+func (w *MultiFileWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close stops the watcher and releases associated resources, returning once
+// the watcher has stopped. It can safely be called multiple times.
+func (w *MultiFileWatcher) Close() error {
+	w.cancel()
+	<-w.done
+	return w.closeErr
+}
+
+func (w *MultiFileWatcher) run() {
+	defer close(w.done)
+	defer w.queue.wait()
+	defer close(w.errs)
+	if w.initialEvent {
+		for _, t := range w.targets {
+			if t.fileInfo != nil {
+				w.emit(Event{Type: Created, Path: t.filename, FileInfo: t.fileInfo, Time: time.Now()})
+			}
+		}
+	}
+	for {
+		select {
+		case ev := <-w.watcher.Events():
+			ev.Name = filepath.Clean(ev.Name)
+			w.logger.Printf("watch: %v", ev)
+			for _, t := range w.targets {
+				w.handleEvent(t, &ev)
+			}
+
+		case f := <-w.calls:
+			f()
+
+		case err := <-w.watcher.Errors():
+			if err != nil {
+				reportError(w.errs, w.logger, err)
+			}
+			for _, t := range w.targets {
+				w.locate(t)
+			}
+
+		case <-w.ctx.Done():
+			w.closeErr = w.watcher.Close()
+			return
+		}
+	}
+}
+
+func (w *MultiFileWatcher) handleEvent(t *multiTarget, ev *fsnotify.Event) {
+	resolved := resolveSymlinks(t.filename)
+	changed := symlinkChanged(t.resolved, resolved)
+	t.resolved = resolved
+
+	if changed {
+		w.update(t, Updated, ev.Op, func(os.FileInfo) bool { return true })
+		w.locate(t)
+	} else if (ev.Op & (fsnotify.Remove | fsnotify.Rename)) != 0 {
+		w.remove(t, ev)
+		w.locate(t)
+	} else if (ev.Op & fsnotify.Create) != 0 {
+		w.update(t, Created, ev.Op, func(info os.FileInfo) bool {
+			return info != nil && t.fileInfo == nil
+		})
+		if t.target != t.filename {
+			w.locate(t)
+		} else {
+			t.targetStat, _ = os.Stat(t.target)
+		}
+	} else {
+		evTargetStat, _ := os.Stat(ev.Name)
+		if os.SameFile(t.targetStat, evTargetStat) {
+			if t.target != t.filename {
+				w.locate(t)
+			} else {
+				w.update(t, Updated, ev.Op, func(os.FileInfo) bool { return true })
+			}
+		}
+	}
+}
+
+// update refreshes the FileInfo of t and sends an event of type e if cond
+// holds for the new FileInfo
+func (w *MultiFileWatcher) update(t *multiTarget, e EventType, op fsnotify.Op, cond func(os.FileInfo) bool) {
+	info, _ := os.Stat(t.filename)
+	if !cond(info) {
+		return
+	}
+	e = w.attrEventType(e, t.fileInfo, info)
+	w.mu.Lock()
+	t.fileInfo = info
+	w.mu.Unlock()
+
+	w.emit(Event{
+		Type:       e,
+		Path:       t.filename,
+		FileInfo:   info,
+		Time:       time.Now(),
+		Underlying: op,
+	})
+}
+
+// remove clears the FileInfo of t and sends a Deleted or Renamed event if the
+// file is no longer at its location
+func (w *MultiFileWatcher) remove(t *multiTarget, ev *fsnotify.Event) {
+	if info, _ := os.Stat(t.filename); info != nil || t.fileInfo == nil {
+		return
+	}
+	e, newPath := removal(ev, t.filename, t.fileInfo)
+	w.mu.Lock()
+	t.fileInfo = nil
+	w.mu.Unlock()
+
+	w.emit(Event{
+		Type:       e,
+		Path:       t.filename,
+		NewPath:    newPath,
+		Time:       time.Now(),
+		Underlying: ev.Op,
+	})
+}
+
+func (w *MultiFileWatcher) emit(e Event) {
+	if !w.filter.accept(e) {
+		return
+	}
+	w.queue.push(e)
+}
+
+// locate updates the fsnotify watches of t to match the closest existing
+// location of the target and all its parents, and the directory of the file
+// it resolves to if it is a symlink
+func (w *MultiFileWatcher) locate(t *multiTarget) {
+	for _, p := range t.paths {
+		w.removeWatch(p)
+	}
+	t.paths = t.paths[:0]
+
+	path, target := watchLocation(t.filename)
+	t.target = target
+	t.targetStat, _ = os.Stat(target)
+	if realDir := resolvedDir(t.resolved, path); realDir != "" {
+		if err := w.addWatch(realDir); err == nil {
+			t.paths = append(t.paths, realDir)
+		}
+	}
+	for {
+		if err := w.addWatch(path); err != nil {
+			if os.IsNotExist(err) {
+				w.logger.Printf("watch: failed to watch '%v', %v", path, err)
+			} else {
+				reportError(w.errs, w.logger, watchError(path, err))
+			}
+		} else {
+			t.paths = append(t.paths, path)
+		}
+		next, ok := parentDir(path)
+		if !ok {
+			break
+		}
+		path = next
+	}
+}
+
+// addWatch adds path to the fsnotify watcher, counting references to paths
+// shared by multiple targets
+func (w *MultiFileWatcher) addWatch(path string) error {
+	if w.watched[path] == 0 {
+		if err := w.watcher.Add(path); err != nil {
+			return err
+		}
+	}
+	w.watched[path]++
+	return nil
+}
+
+func (w *MultiFileWatcher) removeWatch(path string) {
+	w.watched[path]--
+	if w.watched[path] <= 0 {
+		delete(w.watched, path)
+		w.watcher.Remove(path)
+	}
+}
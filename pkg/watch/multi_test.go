@@ -0,0 +1,141 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func readEventChannel(
+	ch <-chan watch.Event, timeout time.Duration) (
+	watch.Event, bool, bool) {
+
+	select {
+	case e, ok := <-ch:
+		return e, ok, false
+	case <-time.After(timeout):
+		return watch.Event{}, false, true
+	}
+}
+
+// brief returns the type and path of an event, omitting time dependent
+// details
+func brief(e watch.Event) watch.Event {
+	return watch.Event{Type: e.Type, Path: e.Path}
+}
+
+func TestMultiWatchModifyingAndDeletingFiles(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	first := fs.expandFilename("path/to/first.yaml")
+	second := fs.expandFilename("path/other/second.yaml")
+	fs.createFile(first)
+	fs.createFile(second)
+
+	w, err := watch.NewMultiFileWatcher([]string{first, second})
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	assert.That(w.Info(first), pred.IsNotNil())
+
+	e, ok, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+
+	fs.appendToFile(second, []byte("aaa\n"))
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: second}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+
+	fs.delete(first)
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Deleted, Path: first}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(w.Info(first), pred.IsNil())
+
+	w.Close()
+
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(ok, pred.IsEqualTo(false), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(timeout, pred.IsEqualTo(false), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+}
+
+func TestMultiWatchCreatingFiles(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	first := fs.expandFilename("path/to/first.yaml")
+	second := fs.expandFilename("path/to/missing/second.yaml")
+	fs.createFile(first)
+
+	w, err := watch.NewMultiFileWatcher([]string{first, second})
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	defer w.Close()
+	assert.That(w.Info(second), pred.IsNil())
+
+	fs.createFile(second)
+	e, ok, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Created, Path: second}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(w.Info(second), pred.IsNotNil())
+
+	fs.appendToFile(first, []byte("aaa\n"))
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: first}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+}
+
+func TestMultiFileWatcherAddAndRemove(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	a := fs.expandFilename("path/to/a.yaml")
+	b := fs.expandFilename("other/path/b.yaml")
+	w, err := watch.NewMultiFileWatcher(nil)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	assert.That(w.Add(a), pred.IsNil())
+
+	fs.createFile(b)
+	assert.That(w.Add(b), pred.IsNil())
+	assert.That(w.Add(b), pred.IsNil())
+	assert.That(w.Info(b), pred.IsNotNil())
+
+	fs.appendToFile(b, []byte("aaa\n"))
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: b}))
+
+	assert.That(w.Remove(b), pred.IsNil())
+	assert.That(w.Remove(b), pred.IsNotNil())
+	assert.That(w.Info(b), pred.IsNil())
+
+	fs.appendToFile(b, []byte("bbb\n"))
+	_, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+
+	fs.createFile(a)
+	e, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Created, Path: a}))
+}
+
+func TestMultiFileWatcherAddAfterClose(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	w, err := watch.NewMultiFileWatcher(nil)
+	assert.That(err, pred.IsNil())
+	w.Close()
+
+	_, ok := <-w.Events()
+	assert.That(ok, pred.IsEqualTo(false))
+
+	assert.That(w.Add(fs.expandFilename("path/to/a.yaml")), pred.IsNotNil())
+}
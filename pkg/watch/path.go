@@ -0,0 +1,44 @@
+package watch
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+)
+
+// parentDir returns the parent directory of path, or false if path is the
+// root of its volume, e.g. "/", `C:\` or `\\host\share\`
+func parentDir(path string) (string, bool) {
+	parent := filepath.Dir(path)
+	if parent == path {
+		return "", false
+	}
+	return parent, true
+}
+
+// isPathPrefix reports whether dir is path or one of its parent directories
+func isPathPrefix(dir, path string) bool {
+	for {
+		if samePath(dir, path) {
+			return true
+		}
+		var ok bool
+		if path, ok = parentDir(path); !ok {
+			return false
+		}
+	}
+}
+
+// readFile reads the content of filename, retrying briefly if the file is
+// locked by a writer, which happens on Windows while the file is being
+// written
+func readFile(filename string) ([]byte, error) {
+	const attempts = 3
+	for i := 1; ; i++ {
+		content, err := ioutil.ReadFile(filename)
+		if err == nil || i == attempts || !isSharingViolation(err) {
+			return content, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
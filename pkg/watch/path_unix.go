@@ -0,0 +1,14 @@
+//go:build !windows
+
+package watch
+
+// samePath reports whether a and b designate the same location
+func samePath(a, b string) bool {
+	return a == b
+}
+
+// isSharingViolation reports whether err is caused by a file being locked by
+// another process, which never happens on POSIX systems
+func isSharingViolation(err error) bool {
+	return false
+}
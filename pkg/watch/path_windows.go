@@ -0,0 +1,20 @@
+package watch
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+)
+
+// samePath reports whether a and b designate the same location. Paths are
+// case insensitive on Windows.
+func samePath(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// isSharingViolation reports whether err is caused by a file being locked by
+// another process: ERROR_SHARING_VIOLATION or ERROR_LOCK_VIOLATION
+func isSharingViolation(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && (errno == 32 || errno == 33)
+}
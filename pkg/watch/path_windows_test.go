@@ -0,0 +1,58 @@
+package watch_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// missingDrive returns the root of a drive letter that is not mounted
+func missingDrive(t *testing.T) string {
+	for c := 'Z'; c >= 'D'; c-- {
+		root := string(c) + `:\`
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			return root
+		}
+	}
+	t.Skip("no unmounted drive letter available")
+	return ""
+}
+
+func TestWatchFileOnMissingDrive(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	w, err := watch.NewFileWatcher(missingDrive(t) + `path\to\file.yaml`)
+	assert.That(err, pred.IsNil())
+	assert.That(w.Info(), pred.IsNil())
+
+	done := make(chan error)
+	go func() { done <- w.Close() }()
+	select {
+	case err := <-done:
+		assert.That(err, pred.IsNil())
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for watcher to close")
+	}
+}
+
+func TestWatchFileWithForwardSlashes(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.getBasePath() + "/path/to/file.yaml"
+	w, err := watch.NewMultiFileWatcher([]string{target})
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.createFile("path/to/file.yaml")
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Created))
+	assert.That(e.Path, pred.IsEqualTo(fs.expandFilename(`path\to\file.yaml`)))
+}
@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"bytes"
+	"os"
+	"time"
+)
+
+// DefaultPollInterval is the polling interval used when filesystem
+// notifications are not available and a watcher falls back to polling
+const DefaultPollInterval = 5 * time.Second
+
+// fileState captures the properties of a file compared between polls
+type fileState struct {
+	info     os.FileInfo
+	checksum []byte
+}
+
+func readFileState(filename string) fileState {
+	info, err := os.Stat(filename)
+	if err != nil || info.IsDir() {
+		return fileState{}
+	}
+	return fileState{info: info, checksum: fileChecksum(filename)}
+}
+
+// compare returns the event type corresponding to the transition from s to
+// next, or 0 if nothing changed
+func (s fileState) compare(next fileState) EventType {
+	switch {
+	case s.info == nil && next.info != nil:
+		return Created
+	case s.info != nil && next.info == nil:
+		return Deleted
+	case s.info == nil:
+		return 0
+	case !s.info.ModTime().Equal(next.info.ModTime()) ||
+		s.info.Size() != next.info.Size() ||
+		!bytes.Equal(s.checksum, next.checksum):
+		return Updated
+	}
+	return 0
+}
+
+// poll watches the file by comparing its modification time, size and
+// checksum at regular intervals with its last known state
+func (w *FileWatcher) poll() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.ctx.Done():
+			return
+		}
+
+		next := readFileState(w.filename)
+		e := w.state.compare(next)
+		if e == 0 && w.attrEventType(Updated, w.state.info, next.info) == AttrChanged {
+			e = AttrChanged
+		}
+		w.state = next
+		if e == 0 {
+			continue
+		}
+		w.logger.Printf("watch: %v %v", e, w.filename)
+		w.fileInfo = next.info
+		w.send(e, 0)
+	}
+}
@@ -0,0 +1,51 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestPollingWatcher(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	w, err := watch.NewPollingWatcher(target, 10*time.Millisecond)
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+
+	e, ok, timeout := readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+
+	fs.createFile(target)
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(e, pred.IsEqualTo(watch.Created), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(w.Info(), pred.IsNotNil())
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(e, pred.IsEqualTo(watch.Updated), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+
+	fs.delete("path/to")
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(e, pred.IsEqualTo(watch.Deleted), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(w.Info(), pred.IsNil())
+
+	w.Close()
+
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(ok, pred.IsEqualTo(false), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+	assert.That(timeout, pred.IsEqualTo(false), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+}
+
+func TestPollingWatcherInvalidInterval(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := watch.NewPollingWatcher("file.yaml", 0)
+	assert.That(err, pred.IsNotNil())
+}
@@ -0,0 +1,253 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy defines how a watcher handles new events when its event
+// buffer is full because the consumer is not keeping up
+type OverflowPolicy int
+
+const (
+	// Block waits for the consumer to make room in the buffer, stalling the
+	// watcher in the meantime. This is the default.
+	Block OverflowPolicy = iota
+
+	// DropOldest discards the oldest buffered event to make room for the new
+	// one
+	DropOldest
+
+	// Coalesce merges the new event into the buffered event for the same
+	// location, if any, and otherwise blocks like Block
+	Coalesce
+)
+
+// WithBufferSize sets the number of events buffered for a slow consumer. The
+// default is one event per watched location.
+func WithBufferSize(n int) Option {
+	return func(o *options) {
+		o.bufferSize = n
+	}
+}
+
+// WithOverflowPolicy sets how new events are handled when the event buffer is
+// full. The default is Block.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(o *options) {
+		o.overflowPolicy = p
+	}
+}
+
+// WithCoalescing folds bursts of events for the same location occurring less
+// than window apart into a single event, sent once the location has been
+// quiet for window, e.g. the create, write, rename and chmod sequence of an
+// atomic write. By default, events are sent as soon as they are detected.
+func WithCoalescing(window time.Duration) Option {
+	return func(o *options) {
+		o.coalescingWindow = window
+	}
+}
+
+// ---------------------------------------------------------------------------
+// eventQueue
+// ---------------------------------------------------------------------------
+
+// eventQueue buffers the events of a watcher according to its overflow
+// policy, and delivers them on its output channel until the context is
+// canceled, at which point the output channel is closed and pending events
+// are discarded
+type eventQueue struct {
+	size   int
+	policy OverflowPolicy
+	out    chan Event
+	done   chan struct{}
+
+	window time.Duration
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []Event
+	held    map[string]*heldEvent
+	closed  bool
+	dropped uint64
+}
+
+// heldEvent is an event held back until its location has been quiet for the
+// coalescing window
+type heldEvent struct {
+	e        Event
+	deadline time.Time
+	timer    *time.Timer
+}
+
+func newEventQueue(ctx context.Context, o options, defaultSize int) *eventQueue {
+	q := &eventQueue{
+		size:   o.bufferSize,
+		policy: o.overflowPolicy,
+		out:    make(chan Event),
+		done:   make(chan struct{}),
+		window: o.coalescingWindow,
+		held:   make(map[string]*heldEvent),
+	}
+	if q.size <= 0 {
+		q.size = defaultSize
+	}
+	if q.size <= 0 {
+		q.size = 1
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	go func() {
+		<-ctx.Done()
+		q.mu.Lock()
+		q.closed = true
+		for _, h := range q.held {
+			h.timer.Stop()
+		}
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}()
+	go q.run(ctx)
+	return q
+}
+
+// push adds an event to the queue, holding it back first if a coalescing
+// window is set
+func (q *eventQueue) push(e Event) {
+	if q.window > 0 {
+		q.hold(e)
+		return
+	}
+	q.enqueue(e)
+}
+
+// hold merges e into the event held for the same location, if any, and
+// defers its delivery until the location has been quiet for the coalescing
+// window
+func (q *eventQueue) hold(e Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+
+	deadline := time.Now().Add(q.window)
+	if h, ok := q.held[e.Path]; ok {
+		h.e = coalesce(h.e, e)
+		h.deadline = deadline
+		return
+	}
+	h := &heldEvent{e: e, deadline: deadline}
+	h.timer = time.AfterFunc(q.window, func() { q.release(h) })
+	q.held[e.Path] = h
+}
+
+// release enqueues a held event once its deadline has passed
+func (q *eventQueue) release(h *heldEvent) {
+	q.mu.Lock()
+	if q.closed || q.held[h.e.Path] != h {
+		q.mu.Unlock()
+		return
+	}
+	if remaining := time.Until(h.deadline); remaining > 0 {
+		h.timer.Reset(remaining)
+		q.mu.Unlock()
+		return
+	}
+	delete(q.held, h.e.Path)
+	q.mu.Unlock()
+
+	q.enqueue(h.e)
+}
+
+// enqueue adds an event to the queue, applying the overflow policy if the
+// queue is full
+func (q *eventQueue) enqueue(e Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.pending) >= q.size {
+		switch q.policy {
+		case DropOldest:
+			q.pending = q.pending[1:]
+			q.dropped++
+			continue
+		case Coalesce:
+			if i := q.indexOf(e.Path); i != -1 {
+				q.pending[i] = coalesce(q.pending[i], e)
+				q.dropped++
+				return
+			}
+		}
+		q.cond.Wait()
+	}
+	if q.closed {
+		return
+	}
+	q.pending = append(q.pending, e)
+	q.cond.Broadcast()
+}
+
+// Dropped returns the number of events dropped or merged into other events
+// because the buffer was full
+func (q *eventQueue) Dropped() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// wait blocks until the queue has stopped delivering events
+func (q *eventQueue) wait() {
+	<-q.done
+}
+
+func (q *eventQueue) run(ctx context.Context) {
+	defer close(q.done)
+	defer close(q.out)
+	for {
+		q.mu.Lock()
+		for !q.closed && len(q.pending) == 0 {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		e := q.pending[0]
+		q.pending = q.pending[1:]
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		select {
+		case q.out <- e:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (q *eventQueue) indexOf(path string) int {
+	for i := len(q.pending) - 1; i >= 0; i-- {
+		if q.pending[i].Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// coalesce merges a new event into a pending event for the same location,
+// keeping the details of the new event and the type reflecting the overall
+// change
+func coalesce(pending, e Event) Event {
+	switch {
+	case pending.Type == Created && e.Type == Updated:
+		e.Type = Created
+	case (pending.Type == Deleted || pending.Type == Renamed) && e.Type == Created:
+		e.Type = Updated
+	case pending.Type != AttrChanged && e.Type == AttrChanged:
+		e.Type = pending.Type
+	}
+	return e
+}
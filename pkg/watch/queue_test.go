@@ -0,0 +1,113 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// collectAfterUpdates applies n updates to a file without consuming events,
+// and returns the events received afterwards and the number of dropped events
+func collectAfterUpdates(t *testing.T, n int, opts ...watch.Option) ([]watch.Event, uint64) {
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewPollingWatcher(target, 5*time.Millisecond, opts...)
+	if err != nil {
+		t.Fatalf("failed create watcher, %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < n; i++ {
+		fs.appendToFile(target, []byte("aaa\n"))
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	var events []watch.Event
+	for {
+		select {
+		case e := <-w.Events():
+			events = append(events, e)
+		case <-time.After(defaultTimeout):
+			return events, w.Dropped()
+		}
+	}
+}
+
+func TestOverflowPolicyBlock(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	events, dropped := collectAfterUpdates(t, 4, watch.WithBufferSize(1))
+	assert.That(events, pred.Length(pred.IsEqualTo(4)))
+	assert.That(dropped, pred.IsEqualTo(uint64(0)))
+}
+
+func TestOverflowPolicyDropOldest(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	events, dropped := collectAfterUpdates(t, 4,
+		watch.WithBufferSize(1),
+		watch.WithOverflowPolicy(watch.DropOldest),
+	)
+	assert.That(events, pred.Length(pred.IsEqualTo(2)))
+	assert.That(dropped, pred.IsEqualTo(uint64(2)))
+	assert.That(events[1].FileInfo.Size(), pred.IsEqualTo(int64(16)))
+}
+
+func TestOverflowPolicyCoalesce(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	events, dropped := collectAfterUpdates(t, 4,
+		watch.WithBufferSize(1),
+		watch.WithOverflowPolicy(watch.Coalesce),
+	)
+	assert.That(events, pred.Length(pred.IsEqualTo(2)))
+	assert.That(dropped, pred.IsEqualTo(uint64(2)))
+	assert.That(events[1].Type, pred.IsEqualTo(watch.Updated))
+	assert.That(events[1].FileInfo.Size(), pred.IsEqualTo(int64(16)))
+}
+
+func TestLargerBuffer(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	events, dropped := collectAfterUpdates(t, 4,
+		watch.WithBufferSize(4),
+		watch.WithOverflowPolicy(watch.DropOldest),
+	)
+	assert.That(events, pred.Length(pred.IsEqualTo(4)))
+	assert.That(dropped, pred.IsEqualTo(uint64(0)))
+}
+
+func TestCoalescingWindow(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target},
+		watch.WithCoalescing(50*time.Millisecond),
+	)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.delete("path/to/file.yaml")
+	time.Sleep(10 * time.Millisecond)
+	fs.appendToFile(target, []byte("aaa\n"))
+	time.Sleep(10 * time.Millisecond)
+	fs.appendToFile(target, []byte("bbb\n"))
+
+	e, _, timeout := readEventChannel(w.Events(), 2*defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+	assert.That(e.FileInfo.Size(), pred.IsEqualTo(int64(8)))
+
+	_, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
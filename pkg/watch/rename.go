@@ -0,0 +1,42 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// removal returns the type of the event notifying that the file last known as
+// info is no longer at filename: Renamed if ev reports a rename of filename or
+// if the file can be found under another name in the same directory, along
+// with that new name, and Deleted otherwise
+func removal(ev *fsnotify.Event, filename string, info os.FileInfo) (EventType, string) {
+	if !samePath(ev.Name, filename) {
+		return Deleted, ""
+	}
+	newPath := findRenamed(filepath.Dir(filename), info)
+	if newPath != "" || ev.Op&fsnotify.Rename != 0 {
+		return Renamed, newPath
+	}
+	return Deleted, ""
+}
+
+// findRenamed returns the path of the file in dir that is the same file as
+// info, or an empty string if there is none
+func findRenamed(dir string, info os.FileInfo) string {
+	if info == nil {
+		return ""
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if os.SameFile(info, entry) {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestFileWatcherRenamedEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/app.log")
+	fs.createFile(target)
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	fs.move("path/to/app.log", "path/to/app.log.1")
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Renamed))
+	assert.That(e.Path, pred.IsEqualTo(target))
+	assert.That(e.NewPath, pred.IsEqualTo(fs.expandFilename("path/to/app.log.1")))
+
+	fs.createFile(target)
+	e, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Created))
+}
+
+func TestMultiFileWatcherRenamedEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/app.log")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target})
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.move("path/to/app.log", "path/to/app.log.1")
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Renamed))
+	assert.That(e.NewPath, pred.IsEqualTo(fs.expandFilename("path/to/app.log.1")))
+	assert.That(w.Info(target), pred.IsNil())
+}
+
+func TestDeletedEventIsNotRenamed(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/app.log")
+	fs.createFile(target)
+	w, err := watch.NewMultiFileWatcher([]string{target})
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.delete("path/to/app.log")
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Deleted))
+	assert.That(e.NewPath, pred.IsEqualTo(""))
+}
+
+func TestGlobWatcherRenamedEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	fs.createFile("conf.d/a.yaml")
+	w, err := watch.NewGlobWatcher(fs.expandFilename("conf.d/*.yaml"))
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	fs.move("conf.d/a.yaml", "conf.d/b.yaml")
+	var events []watch.Event
+	for i := 0; i < 2; i++ {
+		e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+		assert.That(timeout, pred.IsEqualTo(false))
+		events = append(events, watch.Event{Type: e.Type, Path: e.Path, NewPath: e.NewPath})
+	}
+	renamed := watch.Event{
+		Type:    watch.Renamed,
+		Path:    fs.expandFilename("conf.d/a.yaml"),
+		NewPath: fs.expandFilename("conf.d/b.yaml"),
+	}
+	created := watch.Event{
+		Type: watch.Created,
+		Path: fs.expandFilename("conf.d/b.yaml"),
+	}
+	assert.That(containsEvent(events, renamed), pred.IsEqualTo(true), events)
+	assert.That(containsEvent(events, created), pred.IsEqualTo(true), events)
+}
+
+func containsEvent(events []watch.Event, e watch.Event) bool {
+	for _, ev := range events {
+		if ev == e {
+			return true
+		}
+	}
+	return false
+}
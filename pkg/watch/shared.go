@@ -0,0 +1,205 @@
+package watch
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// SharedBackend multiplexes a single backend between several watchers, e.g.
+// to watch the files of several independent components with a single inotify
+// instance. Each watcher only receives the notifications of the paths it
+// watches, and all the errors of the underlying backend. A watcher that does
+// not keep up with its notifications delays delivery to all other watchers.
+type SharedBackend struct {
+	backend   Backend
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	refs  map[string]int
+	views map[*sharedView]struct{}
+}
+
+// NewSharedBackend creates a new SharedBackend on top of a backend created
+// with f
+func NewSharedBackend(f BackendFactory) (*SharedBackend, error) {
+	b, err := f()
+	if err != nil {
+		return nil, err
+	}
+	s := &SharedBackend{
+		backend: b,
+		done:    make(chan struct{}),
+		refs:    make(map[string]int),
+		views:   make(map[*sharedView]struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Factory returns a factory of backends sharing the underlying backend, to
+// be passed to WithBackend. Closing those backends detaches them from the
+// shared backend without closing it.
+func (s *SharedBackend) Factory() BackendFactory {
+	return func() (Backend, error) {
+		v := &sharedView{
+			shared: s,
+			names:  make(map[string]struct{}),
+			events: make(chan fsnotify.Event),
+			errors: make(chan error),
+			done:   make(chan struct{}),
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.views[v] = struct{}{}
+		return v, nil
+	}
+}
+
+// Close closes the underlying backend. Watchers using the shared backend
+// should be closed first.
+func (s *SharedBackend) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.backend.Close()
+	})
+	return err
+}
+
+// run dispatches the notifications of the underlying backend to the views
+// watching the affected paths, and its errors to all views
+func (s *SharedBackend) run() {
+	for {
+		select {
+		case ev, ok := <-s.backend.Events():
+			if !ok {
+				return
+			}
+			name := filepath.Clean(ev.Name)
+			for _, v := range s.viewsWatching(name, filepath.Dir(name)) {
+				select {
+				case v.events <- ev:
+				case <-v.done:
+				case <-s.done:
+					return
+				}
+			}
+
+		case err, ok := <-s.backend.Errors():
+			if !ok {
+				return
+			}
+			for _, v := range s.viewsWatching() {
+				select {
+				case v.errors <- err:
+				case <-v.done:
+				case <-s.done:
+					return
+				}
+			}
+
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// viewsWatching returns the views watching any of names, or all views if no
+// name is specified
+func (s *SharedBackend) viewsWatching(names ...string) []*sharedView {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var views []*sharedView
+	for v := range s.views {
+		if len(names) == 0 || v.watches(names) {
+			views = append(views, v)
+		}
+	}
+	return views
+}
+
+// ---------------------------------------------------------------------------
+// sharedView
+// ---------------------------------------------------------------------------
+
+// sharedView is the backend of a single watcher using a SharedBackend. Paths
+// are reference counted across views, and only removed from the underlying
+// backend when no view watches them anymore.
+type sharedView struct {
+	shared    *SharedBackend
+	names     map[string]struct{}
+	events    chan fsnotify.Event
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (v *sharedView) Add(name string) error {
+	name = filepath.Clean(name)
+	s := v.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The path is always added again, since the underlying backend may have
+	// dropped it, e.g. after the path was removed
+	if err := s.backend.Add(name); err != nil {
+		return err
+	}
+	if _, ok := v.names[name]; !ok {
+		v.names[name] = struct{}{}
+		s.refs[name]++
+	}
+	return nil
+}
+
+func (v *sharedView) Remove(name string) error {
+	name = filepath.Clean(name)
+	s := v.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return v.remove(name)
+}
+
+// remove releases the reference of the view on name; s.mu must be held
+func (v *sharedView) remove(name string) error {
+	s := v.shared
+	if _, ok := v.names[name]; !ok {
+		return nil
+	}
+	delete(v.names, name)
+	s.refs[name]--
+	if s.refs[name] > 0 {
+		return nil
+	}
+	delete(s.refs, name)
+	return s.backend.Remove(name)
+}
+
+func (v *sharedView) watches(names []string) bool {
+	for _, name := range names {
+		if _, ok := v.names[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *sharedView) Events() <-chan fsnotify.Event { return v.events }
+func (v *sharedView) Errors() <-chan error          { return v.errors }
+
+func (v *sharedView) Close() error {
+	v.closeOnce.Do(func() {
+		s := v.shared
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for name := range v.names {
+			v.remove(name)
+		}
+		delete(s.views, v)
+		close(v.done)
+	})
+	return nil
+}
@@ -0,0 +1,51 @@
+package watch_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestSharedBackend(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	var created int32
+	shared, err := watch.NewSharedBackend(func() (watch.Backend, error) {
+		atomic.AddInt32(&created, 1)
+		return watch.NewFsnotifyBackend()
+	})
+	assert.That(err, pred.IsNil())
+	defer shared.Close()
+
+	app := fs.expandFilename("path/to/app.yaml")
+	logging := fs.expandFilename("path/to/logging.yaml")
+	fs.createFile(app)
+	fs.createFile(logging)
+	appWatcher, err := watch.NewMultiFileWatcher([]string{app}, watch.WithBackend(shared.Factory()))
+	assert.That(err, pred.IsNil())
+	defer appWatcher.Close()
+	logWatcher, err := watch.NewMultiFileWatcher([]string{logging}, watch.WithBackend(shared.Factory()))
+	assert.That(err, pred.IsNil())
+	assert.That(atomic.LoadInt32(&created), pred.IsEqualTo(int32(1)))
+
+	fs.appendToFile(logging, []byte("aaa\n"))
+	e, _, timeout := readEventChannel(logWatcher.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: logging}))
+	_, _, timeout = readEventChannel(appWatcher.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+
+	// Closing one watcher must not affect the other watchers sharing the
+	// same directory
+	logWatcher.Close()
+	fs.appendToFile(app, []byte("aaa\n"))
+	e, _, timeout = readEventChannel(appWatcher.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: app}))
+}
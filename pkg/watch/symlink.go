@@ -0,0 +1,39 @@
+package watch
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// resolveSymlinks returns the actual location of filename after following
+// all symlinks, or an empty string if it cannot be resolved. Tracking that
+// location detects the symlink swaps used for atomic updates, e.g. of the
+// `..data` symlink of Kubernetes ConfigMap volumes, which leave the watched
+// location itself unchanged.
+func resolveSymlinks(filename string) string {
+	resolved, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return ""
+	}
+	return resolved
+}
+
+// resolvedDir returns the directory of the resolved location of a watched
+// file if it must be watched in addition to path and its parents, or an
+// empty string otherwise
+func resolvedDir(resolved, path string) string {
+	if resolved == "" {
+		return ""
+	}
+	dir := filepath.Dir(resolved)
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return dir
+}
+
+// symlinkChanged returns true if the resolved location of a file changed
+// while the file existed before and after the change
+func symlinkChanged(previous, current string) bool {
+	return previous != "" && current != "" && previous != current
+}
@@ -0,0 +1,84 @@
+//go:build !windows
+
+package watch_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/marcus999/go-config/pkg/watch"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// setupConfigMapVolume reproduces the layout of a Kubernetes ConfigMap
+// volume, where config.yaml -> ..data/config.yaml and ..data -> ..<version>
+func setupConfigMapVolume(fs *fsTestEnv, version string) string {
+	fs.t.Helper()
+	fs.createFile("volume/" + version + "/config.yaml")
+	symlink(fs, version, "volume/..data")
+	symlink(fs, "..data/config.yaml", "volume/config.yaml")
+	return fs.expandFilename("volume/config.yaml")
+}
+
+// updateConfigMapVolume atomically repoints ..data like kubelet does
+func updateConfigMapVolume(fs *fsTestEnv, previous, version string) {
+	fs.t.Helper()
+	fs.createFile("volume/" + version + "/config.yaml")
+	fs.appendToFile("volume/"+version+"/config.yaml", []byte("updated\n"))
+	symlink(fs, version, "volume/..data_tmp")
+	fs.move("volume/..data_tmp", "volume/..data")
+	fs.delete("volume/" + previous)
+}
+
+func symlink(fs *fsTestEnv, oldname, newname string) {
+	fs.t.Helper()
+	if err := os.Symlink(oldname, fs.expandFilename(newname)); err != nil {
+		fs.t.Errorf("failed to create symlink '%v', %v", newname, err)
+	}
+}
+
+func TestWatchConfigMapSymlinkSwap(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := setupConfigMapVolume(fs, "..2024_01_01")
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	defer w.Close()
+
+	e, ok, timeout := readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+
+	updateConfigMapVolume(fs, "..2024_01_01", "..2024_01_02")
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(e, pred.IsEqualTo(watch.Updated), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+
+	fs.appendToFile("volume/..2024_01_02/config.yaml", []byte("more\n"))
+	e, ok, timeout = readChannel(w.UpdateChannel(), defaultTimeout)
+	assert.That(e, pred.IsEqualTo(watch.Updated), "e: %v, ok: %v, timeout: %v", e, ok, timeout)
+}
+
+func TestMultiWatchConfigMapSymlinkSwap(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := setupConfigMapVolume(fs, "..2024_01_01")
+	w, err := watch.NewMultiFileWatcher([]string{target})
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	defer w.Close()
+
+	updateConfigMapVolume(fs, "..2024_01_01", "..2024_01_02")
+	e, ok, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(brief(e), pred.IsEqualTo(watch.Event{Type: watch.Updated, Path: target}),
+		"e: %v, ok: %v, timeout: %v", e, ok, timeout)
+
+	e, ok, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true), "expected timeout, e: %v, ok: %v", e, ok)
+}
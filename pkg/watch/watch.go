@@ -14,16 +14,21 @@ An fsnotify object watching 'path/to/file.txt' will not fire if 'path/to' is
 renamed to 'path/not_to'. watch will detect that change and signal that the
 file has been deleted, as it is no longer present at the watched location
 
-FileWatcher objects should be created with etiher watch.New() or watch.NewCtx().
+The source of raw filesystem notifications can be replaced with WithBackend,
+e.g. with PollingBackend for filesystems that do not support notifications,
+or shared between watchers with NewSharedBackend.
 
+FileWatcher objects should be created with etiher watch.New() or watch.NewCtx().
 */
 package watch
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
@@ -40,6 +45,15 @@ const (
 
 	// Deleted is the event type sent when the watched location is removed
 	Deleted
+
+	// Renamed is the event type sent when the file at the watched location is
+	// renamed, e.g. by log rotation
+	Renamed
+
+	// AttrChanged is the event type sent when only the permissions or
+	// ownership of the file at the watched location change. It is only sent
+	// if enabled with WithAttrChanges.
+	AttrChanged
 )
 
 var eventTypes = []string{
@@ -47,32 +61,165 @@ var eventTypes = []string{
 	"Created",
 	"Updated",
 	"Deleted",
+	"Renamed",
+	"AttrChanged",
 }
 
 func (e EventType) String() string {
 	return eventTypes[int(e)]
 }
 
+// Event describes a change of a watched location
+type Event struct {
+	// Type is the type of change
+	Type EventType
+
+	// Path is the watched location
+	Path string
+
+	// NewPath is the new location of the file for Renamed events, if it could
+	// be determined, i.e. if the file was renamed within the same directory
+	NewPath string
+
+	// FileInfo describes the file at the watched location after the change,
+	// or is nil if there is no file at that location
+	FileInfo os.FileInfo
+
+	// Time is the time at which the change was detected
+	Time time.Time
+
+	// Underlying is the fsnotify operation that triggered the event, or 0 if
+	// the change was detected by polling
+	Underlying fsnotify.Op
+}
+
+func (e Event) String() string {
+	if e.NewPath != "" {
+		return fmt.Sprintf("%v %v -> %v", e.Type, e.Path, e.NewPath)
+	}
+	return fmt.Sprintf("%v %v", e.Type, e.Path)
+}
+
 // FileWatcher watches a single filesystem location and notifies xxx when
 // a file at that location is created, updated or deleted
 type FileWatcher struct {
+	options
 	filename string
 	fileInfo os.FileInfo
-	watcher  *fsnotify.Watcher
-
+	resolved string
+	watcher  Backend
+	interval time.Duration
+	state    fileState
+	filter   *eventFilter
+
+	queue    *eventQueue
+	errs     chan error
+	done     chan struct{}
+	closeErr error
 	updateCh chan EventType
-	ctx      context.Context
-	cancel   func()
+	shimOnce sync.Once
+
+	subsOnce sync.Once
+	subsMu   sync.Mutex
+	subs     map[*eventQueue]struct{}
+
+	suspendMu     sync.Mutex
+	suspended     bool
+	suspendedInfo os.FileInfo
+	missed        bool
+
+	ctx    context.Context
+	cancel func()
+}
+
+// Logger is the interface used by the watcher to report filesystem events. It
+// is satisfied by the standard library *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...interface{}) {}
+
+// options holds the settings common to all watchers
+type options struct {
+	logger              Logger
+	bufferSize          int
+	overflowPolicy      OverflowPolicy
+	checksum            bool
+	ignoreEmpty         bool
+	requireMtimeAdvance bool
+	attrChanges         bool
+	coalescingWindow    time.Duration
+	backend             BackendFactory
+	initialEvent        bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		logger:  nopLogger{},
+		backend: NewFsnotifyBackend,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Option is the base type for watcher options
+type Option func(*options)
+
+// WithLogger sets the logger used to report filesystem events. By default, or
+// if l is nil, nothing is logged.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		o.logger = l
+	}
+}
+
+// WithInitialEvent makes the watcher send a Created event for every file
+// present at a watched location when it starts, so that consumers can handle
+// the initial state and later changes with the same code.
+func WithInitialEvent() Option {
+	return func(o *options) {
+		o.initialEvent = true
+	}
 }
 
 // NewFileWatcher creates a new FileWatcher
-func NewFileWatcher(filename string) (*FileWatcher, error) {
-	return NewFileWatcherWithContext(context.Background(), filename)
+func NewFileWatcher(filename string, opts ...Option) (*FileWatcher, error) {
+	return NewFileWatcherWithContext(context.Background(), filename, opts...)
 }
 
 // NewFileWatcherWithContext creates a new FileWatcher with an explicit
-// cancelation context
-func NewFileWatcherWithContext(ctx context.Context, filename string) (*FileWatcher, error) {
+// cancelation context. If filesystem notifications are not available, e.g.
+// when inotify limits are reached, the watcher falls back to polling the
+// file every DefaultPollInterval.
+func NewFileWatcherWithContext(ctx context.Context, filename string, opts ...Option) (*FileWatcher, error) {
+	return newFileWatcher(ctx, filename, 0, opts)
+}
+
+// NewPollingWatcher creates a new FileWatcher that polls the state of the
+// file at regular intervals instead of relying on filesystem notifications,
+// for filesystems that do not support them, e.g. NFS or FUSE.
+func NewPollingWatcher(filename string, interval time.Duration, opts ...Option) (*FileWatcher, error) {
+	return NewPollingWatcherWithContext(context.Background(), filename, interval, opts...)
+}
+
+// NewPollingWatcherWithContext creates a new polling FileWatcher with an
+// explicit cancelation context
+func NewPollingWatcherWithContext(ctx context.Context, filename string, interval time.Duration, opts ...Option) (*FileWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid polling interval %v", interval)
+	}
+	return newFileWatcher(ctx, filename, interval, opts)
+}
+
+func newFileWatcher(ctx context.Context, filename string, interval time.Duration, opts []Option) (*FileWatcher, error) {
 	target, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, err
@@ -80,22 +227,35 @@ func NewFileWatcherWithContext(ctx context.Context, filename string) (*FileWatch
 	ctx, cancel := context.WithCancel(ctx)
 
 	var w = &FileWatcher{
+		options:  newOptions(opts),
 		filename: target,
-		updateCh: make(chan EventType, 1),
+		interval: interval,
+		errs:     newErrorChannel(),
+		done:     make(chan struct{}),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
 
-	n, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
+	if w.interval == 0 {
+		n, err := w.backend()
+		if err != nil {
+			w.logger.Printf("watch: notifications not available, polling every %v instead, %v",
+				DefaultPollInterval, err)
+			w.interval = DefaultPollInterval
+		}
+		w.watcher = n
 	}
-	w.watcher = n
 
 	info, _ := os.Stat(filename)
 	if info != nil && !info.IsDir() {
 		w.fileInfo = info
 	}
+	w.resolved = resolveSymlinks(target)
+	w.filter = newEventFilter(w.options, target)
+	w.queue = newEventQueue(ctx, w.options, 1)
+	if w.interval != 0 {
+		w.state = readFileState(target)
+	}
 
 	go w.run()
 
@@ -108,32 +268,194 @@ func (w *FileWatcher) Info() os.FileInfo {
 	return w.fileInfo
 }
 
-// UpdateChannel returns the readabl channel on which updates are sent
+// Events returns the readable channel on which events are sent
+func (w *FileWatcher) Events() <-chan Event {
+	return w.queue.out
+}
+
+// Dropped returns the number of events dropped or coalesced according to the
+// overflow policy
+func (w *FileWatcher) Dropped() uint64 {
+	return w.queue.Dropped()
+}
+
+// Subscribe returns a new channel receiving all subsequent events, buffered
+// independently of other subscribers according to the buffer size and
+// overflow policy of the watcher, and a function to cancel the subscription.
+// With the Block policy, a subscriber that does not keep up delays delivery to
+// all other subscribers. The channel is closed when the subscription is
+// canceled or when the watcher stops. Once Subscribe has been called, Events
+// and UpdateChannel should not be used.
+func (w *FileWatcher) Subscribe() (<-chan Event, func()) {
+	ctx, cancel := context.WithCancel(w.ctx)
+	o := w.options
+	o.coalescingWindow = 0
+	q := newEventQueue(ctx, o, 1)
+
+	w.subsMu.Lock()
+	if w.subs == nil {
+		w.subs = make(map[*eventQueue]struct{})
+	}
+	w.subs[q] = struct{}{}
+	w.subsMu.Unlock()
+	w.subsOnce.Do(func() { go w.broadcast() })
+
+	unsubscribe := func() {
+		w.subsMu.Lock()
+		delete(w.subs, q)
+		w.subsMu.Unlock()
+		cancel()
+	}
+	return q.out, unsubscribe
+}
+
+// broadcast delivers the events of the watcher to all subscribers
+func (w *FileWatcher) broadcast() {
+	for e := range w.queue.out {
+		w.subsMu.Lock()
+		subs := make([]*eventQueue, 0, len(w.subs))
+		for q := range w.subs {
+			subs = append(subs, q)
+		}
+		w.subsMu.Unlock()
+
+		for _, q := range subs {
+			q.push(e)
+		}
+	}
+}
+
+// Errors returns the readable channel on which errors reported by the
+// underlying notification mechanism are sent, e.g. inotify queue overflows or
+// permission problems. The channel is closed when the watcher stops. Errors
+// are dropped if they are not consumed.
+func (w *FileWatcher) Errors() <-chan error {
+	return w.errs
+}
+
+// UpdateChannel returns a readable channel on which the type of each event is
+// sent.
+//
+// Deprecated: use Events instead, which also identifies the changed location.
+// Both channels consume the same events and should not be used together.
 func (w *FileWatcher) UpdateChannel() <-chan EventType {
+	w.shimOnce.Do(func() {
+		w.updateCh = make(chan EventType, 1)
+		go func() {
+			defer close(w.updateCh)
+			for e := range w.queue.out {
+				w.updateCh <- e.Type
+			}
+		}()
+	})
 	return w.updateCh
 }
 
-// Close closes the watcher and releases associated resources
-func (w *FileWatcher) Close() {
+// Done returns a channel that is closed once the watcher has stopped, after a
+// call to Close or when its context is canceled
+func (w *FileWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Suspend temporarily stops the emission of events, e.g. while the
+// application itself is writing the watched file. Changes detected while
+// suspended are discarded, unless Resume is called with coalesce set.
+func (w *FileWatcher) Suspend() {
+	w.suspendMu.Lock()
+	defer w.suspendMu.Unlock()
+	if !w.suspended {
+		w.suspended = true
+		w.suspendedInfo, _ = os.Stat(w.filename)
+		w.missed = false
+	}
+}
+
+// Resume resumes the emission of events after a call to Suspend. If coalesce
+// is set and the file changed while suspended, a single event describing the
+// overall change is sent.
+func (w *FileWatcher) Resume(coalesce bool) {
+	w.suspendMu.Lock()
+	missed := w.suspended && w.missed
+	before := w.suspendedInfo
+	w.suspended = false
+	w.suspendMu.Unlock()
+	if !coalesce || !missed {
+		return
+	}
+
+	info, _ := os.Stat(w.filename)
+	e := Event{Type: Updated, Path: w.filename, FileInfo: info, Time: time.Now()}
+	switch {
+	case before == nil && info == nil:
+		return
+	case before == nil:
+		e.Type = Created
+	case info == nil:
+		e.Type = Deleted
+	}
+	w.queue.push(e)
+}
+
+// Close stops the watcher and releases associated resources, returning once
+// the watcher has stopped. It can safely be called multiple times.
+func (w *FileWatcher) Close() error {
 	w.cancel()
+	<-w.done
+	return w.closeErr
 }
 
 func (w *FileWatcher) run() {
+	defer close(w.done)
+	defer w.queue.wait()
+	defer close(w.errs)
+	if w.initialEvent && w.fileInfo != nil {
+		w.send(Created, 0)
+	}
+	if w.interval != 0 {
+		w.poll()
+		return
+	}
+
 	for {
 		path, target := watchLocation(w.filename)
 		targetStat, _ := os.Stat(target)
 
+		// A missing root, e.g. an unmounted Windows drive, cannot be watched
+		// and is polled instead
+		_, hasParent := parentDir(path)
 		err := w.watcher.Add(path)
+		if err != nil && (!os.IsNotExist(err) || !hasParent) {
+			reportError(w.errs, w.logger, watchError(path, err))
+			w.logger.Printf("watch: polling '%v' every %v instead", w.filename, DefaultPollInterval)
+			w.watcher.Close()
+			w.interval = DefaultPollInterval
+			w.state = readFileState(w.filename)
+			w.poll()
+			return
+		}
 		if err != nil {
 			continue
 		}
 		w.watchParents(path)
+		realDir := resolvedDir(w.resolved, path)
+		if realDir != "" {
+			w.watcher.Add(realDir)
+		}
 
 	watchloop:
 		for {
 			select {
-			case ev := <-w.watcher.Events:
-				if (ev.Op & fsnotify.Remove) != 0 {
+			case ev := <-w.watcher.Events():
+				ev.Name = filepath.Clean(ev.Name)
+				resolved := resolveSymlinks(w.filename)
+				if symlinkChanged(w.resolved, resolved) {
+					w.resolved = resolved
+					w.handleEvent(&ev)
+					break watchloop
+				}
+				w.resolved = resolved
+
+				if (ev.Op & (fsnotify.Remove | fsnotify.Rename)) != 0 {
 					w.handleDeleteEvent(&ev)
 					break watchloop
 				} else if (ev.Op & fsnotify.Create) != 0 {
@@ -141,6 +463,7 @@ func (w *FileWatcher) run() {
 					if target != w.filename {
 						break watchloop
 					}
+					targetStat, _ = os.Stat(target)
 				} else {
 					evTargetStat, _ := os.Stat(ev.Name)
 					if os.SameFile(targetStat, evTargetStat) {
@@ -152,24 +475,29 @@ func (w *FileWatcher) run() {
 					}
 				}
 
-			case <-w.watcher.Errors:
+			case err := <-w.watcher.Errors():
+				if err != nil {
+					reportError(w.errs, w.logger, err)
+				}
 				break watchloop
 
 			case <-w.ctx.Done():
-				close(w.updateCh)
-				w.watcher.Close()
+				w.closeErr = w.watcher.Close()
 				return
 			}
 		}
 
 		w.watcher.Remove(path)
+		if realDir != "" {
+			w.watcher.Remove(realDir)
+		}
 	}
 }
 
 func (w *FileWatcher) watchParents(path string) {
 	for {
-		next := filepath.Dir(path)
-		if next == path {
+		next, ok := parentDir(path)
+		if !ok {
 			break
 		}
 		path = next
@@ -178,29 +506,61 @@ func (w *FileWatcher) watchParents(path string) {
 }
 
 func (w *FileWatcher) handleEvent(ev *fsnotify.Event) {
-	log.Printf("watch: %v", ev)
+	w.logger.Printf("watch: %v", ev)
+	prev := w.fileInfo
 	w.fileInfo, _ = os.Stat(w.filename)
-	w.updateCh <- Updated
+	w.send(w.attrEventType(Updated, prev, w.fileInfo), ev.Op)
 }
 
 func (w *FileWatcher) handleCreateEvent(ev *fsnotify.Event) {
-	log.Printf("watch: %v", ev)
+	w.logger.Printf("watch: %v", ev)
 	newFileInfo, _ := os.Stat(w.filename)
 	if newFileInfo != nil && w.fileInfo == nil {
 		w.fileInfo = newFileInfo
-		w.updateCh <- Created
+		w.send(Created, ev.Op)
 	}
 }
 
 func (w *FileWatcher) handleDeleteEvent(ev *fsnotify.Event) {
-	log.Printf("watch: %v", ev)
+	w.logger.Printf("watch: %v", ev)
 	newFileInfo, _ := os.Stat(w.filename)
 	if newFileInfo == nil && w.fileInfo != nil {
+		e, newPath := removal(ev, w.filename, w.fileInfo)
 		w.fileInfo = nil
-		w.updateCh <- Deleted
+		w.emit(Event{
+			Type:       e,
+			Path:       w.filename,
+			NewPath:    newPath,
+			Time:       time.Now(),
+			Underlying: ev.Op,
+		})
 	}
 }
 
+func (w *FileWatcher) send(e EventType, op fsnotify.Op) {
+	w.emit(Event{
+		Type:       e,
+		Path:       w.filename,
+		FileInfo:   w.fileInfo,
+		Time:       time.Now(),
+		Underlying: op,
+	})
+}
+
+func (w *FileWatcher) emit(e Event) {
+	if !w.filter.accept(e) {
+		return
+	}
+	w.suspendMu.Lock()
+	suspended := w.suspended
+	w.missed = w.missed || suspended
+	w.suspendMu.Unlock()
+	if suspended {
+		return
+	}
+	w.queue.push(e)
+}
+
 func watchLocation(path string) (watchPath, watchTarget string) {
 	watchPath = path
 	watchTarget = path
@@ -208,7 +568,11 @@ func watchLocation(path string) (watchPath, watchTarget string) {
 		if info, err := os.Stat(watchPath); err == nil && info.IsDir() {
 			return
 		}
+		parent, ok := parentDir(watchPath)
+		if !ok {
+			return
+		}
 		watchTarget = watchPath
-		watchPath = filepath.Dir(watchPath)
+		watchPath = parent
 	}
 }
@@ -1,9 +1,16 @@
 package watch_test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/marcus999/go-config/pkg/watch"
 
 	"github.com/marcus999/go-testpredicate"
@@ -217,3 +224,270 @@ func TestWatchMovingParentFolderOutOfPlace2(t *testing.T) {
 
 	fs.teardown()
 }
+
+func TestWatchEventDetails(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil(), "failed create watcher, %v", err)
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	start := time.Now()
+	fs.appendToFile(target, []byte("aaa\n"))
+
+	select {
+	case e := <-w.Events():
+		assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+		assert.That(e.Path, pred.IsEqualTo(target))
+		assert.That(e.FileInfo, pred.IsNotNil())
+		assert.That(e.FileInfo.Size(), pred.IsEqualTo(int64(4)))
+		assert.That(e.Time.Before(start), pred.IsEqualTo(false))
+		assert.That(e.Underlying, pred.IsNotEqualTo(fsnotify.Op(0)))
+		assert.That(e.String(), pred.IsEqualTo("Updated "+target))
+	case <-time.After(defaultTimeout):
+		t.Errorf("timeout waiting for event")
+	}
+}
+
+func TestErrorsChannelIsClosedOnClose(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	w, err := watch.NewFileWatcher(fs.expandFilename("path/to/file.yaml"))
+	assert.That(err, pred.IsNil())
+	w.Close()
+
+	select {
+	case _, ok := <-w.Errors():
+		assert.That(ok, pred.IsEqualTo(false))
+	case <-time.After(defaultTimeout):
+		t.Errorf("timeout waiting for errors channel to close")
+	}
+}
+
+func TestCloseIsSynchronousAndIdempotent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	w, err := watch.NewFileWatcher(fs.expandFilename("path/to/file.yaml"))
+	assert.That(err, pred.IsNil())
+
+	assert.That(w.Close(), pred.IsNil())
+	assert.That(w.Close(), pred.IsNil())
+
+	select {
+	case <-w.Done():
+	default:
+		t.Errorf("done channel not closed after Close")
+	}
+	_, ok := <-w.Events()
+	assert.That(ok, pred.IsEqualTo(false))
+}
+
+func TestDoneIsClosedWhenContextIsCanceled(t *testing.T) {
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := watch.NewFileWatcherWithContext(ctx, fs.expandFilename("path/to/file.yaml"))
+	if err != nil {
+		t.Fatalf("failed create watcher, %v", err)
+	}
+	cancel()
+
+	select {
+	case <-w.Done():
+	case <-time.After(defaultTimeout):
+		t.Errorf("timeout waiting for watcher to stop")
+	}
+}
+
+func TestSuspendDiscardsEvents(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	w.Suspend()
+	fs.appendToFile(target, []byte("aaa\n"))
+	time.Sleep(defaultTimeout)
+	w.Resume(false)
+
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
+
+func TestResumeCoalescesEvents(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	w.Suspend()
+	fs.createFile(target)
+	time.Sleep(defaultTimeout / 2)
+	fs.appendToFile(target, []byte("aaa\n"))
+	time.Sleep(defaultTimeout)
+	w.Resume(true)
+
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Created))
+	assert.That(e.FileInfo, pred.IsNotNil())
+
+	_, _, timeout = readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
+
+// recordingLogger records the messages logged by a watcher
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.messages)
+}
+
+func TestWatchEventsAreLoggedToInjectedLogger(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	var stdlog bytes.Buffer
+	log.SetOutput(&stdlog)
+	defer log.SetOutput(os.Stderr)
+
+	logger := &recordingLogger{}
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewFileWatcher(target, watch.WithLogger(logger))
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(logger.count(), pred.Gt(0))
+	assert.That(stdlog.String(), pred.IsEqualTo(""))
+}
+
+func TestWatchEventsAreNotLoggedByDefault(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	var stdlog bytes.Buffer
+	log.SetOutput(&stdlog)
+	defer log.SetOutput(os.Stderr)
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(stdlog.String(), pred.IsEqualTo(""))
+}
+
+func TestInitialEvent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewFileWatcher(target, watch.WithInitialEvent())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	e, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Created))
+	assert.That(e.FileInfo, pred.IsNotNil())
+}
+
+func TestNoInitialEventForMissingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	w, err := watch.NewFileWatcher(target, watch.WithInitialEvent())
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+
+	_, _, timeout := readEventChannel(w.Events(), defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(true))
+}
+
+func TestSubscribe(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+	fs := newFsTestEnv(t)
+	defer fs.teardown()
+
+	target := fs.expandFilename("path/to/file.yaml")
+	fs.createFile(target)
+	w, err := watch.NewFileWatcher(target)
+	assert.That(err, pred.IsNil())
+	defer w.Close()
+	time.Sleep(defaultTimeout)
+
+	ch1, unsubscribe1 := w.Subscribe()
+	ch2, unsubscribe2 := w.Subscribe()
+	defer unsubscribe2()
+
+	fs.appendToFile(target, []byte("aaa\n"))
+	for _, ch := range []<-chan watch.Event{ch1, ch2} {
+		e, _, timeout := readEventChannel(ch, defaultTimeout)
+		assert.That(timeout, pred.IsEqualTo(false))
+		assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+	}
+
+	unsubscribe1()
+	_, ok, timeout := readEventChannel(ch1, defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(ok, pred.IsEqualTo(false))
+
+	fs.appendToFile(target, []byte("bbb\n"))
+	e, _, timeout := readEventChannel(ch2, defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(e.Type, pred.IsEqualTo(watch.Updated))
+
+	w.Close()
+	_, ok, timeout = readEventChannel(ch2, defaultTimeout)
+	assert.That(timeout, pred.IsEqualTo(false))
+	assert.That(ok, pred.IsEqualTo(false))
+}
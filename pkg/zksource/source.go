@@ -0,0 +1,187 @@
+/*
+Package zksource provides a configuration source reading a ZooKeeper znode,
+and reloading the configuration whenever the znode changes.
+
+The package does not depend on a specific ZooKeeper client library. Instead,
+it relies on the Client interface, which takes a few lines to implement over
+an existing client, e.g. github.com/go-zookeeper/zk:
+
+	type zkClient struct{ conn *zk.Conn }
+
+	func (c zkClient) GetW(path string) ([]byte, int32, <-chan struct{}, error) {
+		data, stat, events, err := c.conn.GetW(path)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		changed := make(chan struct{})
+		go func() {
+			<-events
+			close(changed)
+		}()
+		return data, stat.Version, changed, nil
+	}
+
+	src, err := zksource.New(zkClient{conn}, "/config/frontend")
+	loader, err := config.NewLoaderFromSource(src, defaultConfig)
+*/
+package zksource
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRetryInterval is the default delay before reading the znode again
+// after a failure
+const DefaultRetryInterval = 5 * time.Second
+
+// Client is the subset of a ZooKeeper client used by the source
+type Client interface {
+	// GetW returns the data and version of the znode at path, along with a
+	// channel receiving a value or closed once the znode changes or is
+	// deleted, or when the watch is lost
+	GetW(path string) (data []byte, version int32, changed <-chan struct{}, err error)
+}
+
+// Source is a config.Source reading the data of a ZooKeeper znode
+type Source struct {
+	client        Client
+	path          string
+	retryInterval time.Duration
+	onError       func(error)
+
+	mu      sync.Mutex
+	data    []byte
+	version int32
+
+	changes chan struct{}
+	ctx     context.Context
+	cancel  func()
+	done    chan struct{}
+}
+
+// Option is the base type for Source options
+type Option func(*Source)
+
+// WithRetryInterval sets the delay before reading the znode again after a
+// failure
+func WithRetryInterval(d time.Duration) Option {
+	return func(s *Source) {
+		s.retryInterval = d
+	}
+}
+
+// WithErrorHandler attaches a function to be called when reading the znode
+// fails in the background
+func WithErrorHandler(f func(error)) Option {
+	return func(s *Source) {
+		s.onError = f
+	}
+}
+
+// New creates a new Source reading the znode at path, and watching it for
+// changes
+func New(client Client, path string, opts ...Option) (*Source, error) {
+	s := &Source{
+		client:        client,
+		path:          path,
+		retryInterval: DefaultRetryInterval,
+		changes:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	data, version, watch, err := client.GetW(path)
+	if err != nil {
+		return nil, err
+	}
+	s.data, s.version = data, version
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	go s.run(watch)
+	return s, nil
+}
+
+// Version returns the version of the znode the configuration is currently
+// read from
+func (s *Source) Version() int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// Read returns the last data read from the znode
+func (s *Source) Read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data, nil
+}
+
+// Changes returns the channel notified when the znode changes
+func (s *Source) Changes() <-chan struct{} {
+	return s.changes
+}
+
+// Close stops watching the znode
+func (s *Source) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Source implementation
+// ---------------------------------------------------------------------------
+
+func (s *Source) run(watch <-chan struct{}) {
+	defer close(s.done)
+	defer close(s.changes)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-watch:
+		}
+
+		// ZooKeeper watches fire once; read the znode again to get its new
+		// data and set a new watch
+		for {
+			data, version, w, err := s.client.GetW(s.path)
+			if err == nil {
+				watch = w
+				s.update(data, version)
+				break
+			}
+			if s.onError != nil {
+				s.onError(err)
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(s.retryInterval):
+			}
+		}
+	}
+}
+
+// update records the new data of the znode, and notifies the loader if it
+// changed. The data is compared as well, as the version restarts from 0 when
+// the znode is deleted and created again.
+func (s *Source) update(data []byte, version int32) {
+	s.mu.Lock()
+	changed := version != s.version || !bytes.Equal(data, s.data)
+	s.data, s.version = data, version
+	s.mu.Unlock()
+
+	if changed {
+		select {
+		case s.changes <- struct{}{}:
+		case <-s.ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package zksource_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/zksource"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type testConfig struct {
+	Name string `json:"name"`
+}
+
+// fakeClient is an in-memory ZooKeeper client holding a single znode
+type fakeClient struct {
+	mu       sync.Mutex
+	data     []byte
+	version  int32
+	err      error
+	watchers []chan struct{}
+}
+
+func (c *fakeClient) GetW(path string) ([]byte, int32, <-chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, 0, nil, c.err
+	}
+	w := make(chan struct{})
+	c.watchers = append(c.watchers, w)
+	return c.data, c.version, w, nil
+}
+
+func (c *fakeClient) set(data string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data, c.err = []byte(data), err
+	c.version++
+	for _, w := range c.watchers {
+		close(w)
+	}
+	c.watchers = nil
+}
+
+func TestSourceWatchesZnode(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	client := &fakeClient{data: []byte("name: first\n")}
+	src, err := zksource.New(client, "/config/app")
+	assert.That(err, pred.IsNil())
+
+	reloaded := make(chan interface{}, 10)
+	l, err := config.NewLoaderFromSource(src, testConfig{},
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) { reloaded <- cfg }),
+	)
+	assert.That(err, pred.IsNil())
+	defer l.Close()
+	assert.That(l.Get().(*testConfig).Name, pred.IsEqualTo("first"))
+
+	for _, name := range []string{"second", "third"} {
+		client.set("name: "+name+"\n", nil)
+		select {
+		case cfg := <-reloaded:
+			assert.That(cfg.(*testConfig).Name, pred.IsEqualTo(name))
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for reload")
+		}
+	}
+	assert.That(src.Version(), pred.IsEqualTo(int32(2)))
+}
+
+func TestSourceRetriesOnError(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	errs := make(chan error, 10)
+	client := &fakeClient{data: []byte("name: first\n")}
+	src, err := zksource.New(client, "/config/app",
+		zksource.WithRetryInterval(10*time.Millisecond),
+		zksource.WithErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer src.Close()
+
+	client.set("", errors.New("connection lost"))
+	select {
+	case err := <-errs:
+		assert.That(err.Error(), pred.IsEqualTo("connection lost"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for error")
+	}
+
+	client.mu.Lock()
+	client.data, client.err = []byte("name: second\n"), nil
+	client.mu.Unlock()
+	select {
+	case <-src.Changes():
+		data, _ := src.Read()
+		assert.That(string(data), pred.IsEqualTo("name: second\n"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for change")
+	}
+}
+
+func TestSourceWithMissingZnode(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := zksource.New(&fakeClient{err: errors.New("node does not exist")}, "/config/app")
+	assert.That(err, pred.IsNotNil())
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+)
+
+// DefaultPollInterval is the polling interval used when filesystem
+// notifications are not available and the loader falls back to polling
+const DefaultPollInterval = watch.DefaultPollInterval
+
+// OptPollingWatch watches the configuration files by periodically checking
+// their modification time, size and content, instead of relying on
+// filesystem notifications. This is needed on filesystems that do not
+// deliver notifications, like NFS or some container mounts. Without this
+// option, polling is used only if notifications cannot be set up, e.g. when
+// inotify limits are reached.
+func OptPollingWatch(interval time.Duration) Option {
+	return func(c *Loader) {
+		c.pollInterval = interval
+	}
+}
@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestPollingWatch(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: initial\n")
+	defer cleanup()
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptPollingWatch(10*time.Millisecond),
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("initial"))
+
+	ioutil.WriteFile(filename, []byte("name: updated\n"), 0666)
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("updated"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload after update")
+	}
+
+	os.Remove(filename)
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo(testConfigDefaults.Name))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload after delete")
+	}
+
+	ioutil.WriteFile(filename, []byte("name: created\n"), 0666)
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*testConfig).Name, pred.IsEqualTo("created"))
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload after create")
+	}
+}
+
+func TestPollingWatchIgnoresUnchangedFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: initial\n")
+	defer cleanup()
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptPollingWatch(10*time.Millisecond),
+		config.OptDebounceInterval(0),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.That(reloaded, pred.Length(pred.IsEqualTo(0)))
+}
@@ -0,0 +1,73 @@
+package config
+
+import "fmt"
+
+// PrepareFunc prepares the adoption of a new configuration by a subsystem.
+// It returns a commit function called once all subsystems are prepared and
+// the configuration is active, and a rollback function called if another
+// subsystem fails to prepare. Either function can be nil.
+type PrepareFunc func(cfg interface{}) (commit func(), rollback func(), err error)
+
+// PrepareHandler attaches a function to be called with a reloaded
+// configuration before it is made active. If any prepare handler fails, the
+// handlers already prepared are rolled back in reverse order and the current
+// configuration stays active, so that multiple subsystems adopt a new
+// configuration atomically.
+func PrepareHandler(f PrepareFunc) Option {
+	return func(c *Loader) {
+		c.prepareHandlers.add(f)
+	}
+}
+
+// AddPrepareHandler attaches a prepare function, like the PrepareHandler
+// option but after the loader is created. It returns a function that detaches
+// the handler.
+func (c *Loader) AddPrepareHandler(f PrepareFunc) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.prepareHandlers.add(f)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.prepareHandlers.remove(id)
+	}
+}
+
+// prepare runs all prepare handlers in order, and returns the commit
+// functions to call once cfg is active. On failure, the handlers already
+// prepared are rolled back.
+func (c *Loader) prepare(cfg interface{}) ([]func(), error) {
+	c.mu.Lock()
+	handlers := c.prepareHandlers.handlers()
+	c.mu.Unlock()
+
+	var commits, rollbacks []func()
+	for _, h := range handlers {
+		commit, rollback, err := c.invokePrepareHandler(h.f, cfg)
+		if err != nil {
+			for i := len(rollbacks) - 1; i >= 0; i-- {
+				if rollbacks[i] != nil {
+					c.invokeHandler(rollbacks[i])
+				}
+			}
+			return nil, err
+		}
+		commits = append(commits, commit)
+		rollbacks = append(rollbacks, rollback)
+	}
+	return commits, nil
+}
+
+// invokePrepareHandler calls a prepare handler through invokeHandler, turning
+// a recovered panic into an error
+func (c *Loader) invokePrepareHandler(f PrepareFunc, cfg interface{}) (
+	commit func(), rollback func(), err error) {
+
+	r := c.invokeHandler(func() {
+		commit, rollback, err = f(cfg)
+	})
+	if r != nil {
+		return nil, nil, fmt.Errorf("prepare handler panic: %v", r)
+	}
+	return commit, rollback, err
+}
@@ -0,0 +1,76 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestPrepareHandlersCommit(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var events []string
+	prepare := func(name string) config.PrepareFunc {
+		return func(cfg interface{}) (func(), func(), error) {
+			events = append(events, "prepare "+name)
+			return func() { events = append(events, "commit "+name) },
+				func() { events = append(events, "rollback "+name) },
+				nil
+		}
+	}
+
+	var c *config.Loader
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.PrepareHandler(prepare("a")),
+		config.PrepareHandler(prepare("b")),
+		config.PrepareHandler(func(cfg interface{}) (func(), func(), error) {
+			return func() {
+				events = append(events, "active "+c.Get().(*testConfig).Name)
+			}, nil, nil
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	assert.That(events, pred.IsEmpty())
+
+	err = c.Update([]byte("name: updated\n"))
+	assert.That(err, pred.IsNil())
+	assert.That(events, pred.IsEqualTo([]string{
+		"prepare a", "prepare b", "commit a", "commit b", "active updated",
+	}))
+}
+
+func TestPrepareHandlersRollback(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var events []string
+	reloaded := 0
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.ReloadHandler(func(cfg interface{}) { reloaded++ }),
+		config.PrepareHandler(func(cfg interface{}) (func(), func(), error) {
+			events = append(events, "prepare a")
+			return func() { events = append(events, "commit a") },
+				func() { events = append(events, "rollback a") },
+				nil
+		}),
+		config.PrepareHandler(func(cfg interface{}) (func(), func(), error) {
+			events = append(events, "prepare b")
+			return nil, nil, nil
+		}),
+		config.PrepareHandler(func(cfg interface{}) (func(), func(), error) {
+			return nil, nil, fmt.Errorf("cannot adopt %v", cfg.(*testConfig).Name)
+		}),
+	)
+	assert.That(err, pred.IsNil())
+
+	err = c.Update([]byte("name: updated\n"))
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.IsEqualTo("cannot adopt updated"))
+	assert.That(events, pred.IsEqualTo([]string{"prepare a", "prepare b", "rollback a"}))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("initial"))
+	assert.That(c.Status().LastError, pred.IsEqualTo(err))
+	assert.That(reloaded, pred.IsEqualTo(0))
+}
@@ -0,0 +1,43 @@
+package config
+
+// profilesKey is the top-level key holding profile-specific sections
+const profilesKey = "profiles"
+
+// applyProfile removes the profiles section from the raw document, and
+// merges the section of the named profile, if any, over the rest of the
+// document
+func applyProfile(doc interface{}, profile string) interface{} {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return doc
+	}
+	profiles, _ := m[profilesKey].(map[string]interface{})
+	delete(m, profilesKey)
+
+	if override, ok := profiles[profile]; ok && profile != "" {
+		return mergeDocuments(m, override)
+	}
+	return m
+}
+
+// mergeDocuments merges the src raw document over dst. Maps are merged
+// recursively; any other value from src replaces the value from dst.
+func mergeDocuments(dst, src interface{}) interface{} {
+	dm, ok := dst.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	sm, ok := src.(map[string]interface{})
+	if !ok {
+		return src
+	}
+
+	for k, v := range sm {
+		if existing, ok := dm[k]; ok {
+			dm[k] = mergeDocuments(existing, v)
+		} else {
+			dm[k] = v
+		}
+	}
+	return dm
+}
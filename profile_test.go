@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type profileTestConfig struct {
+	Name string
+	Port int
+	Log  struct {
+		Level  string
+		Format string
+	}
+}
+
+const profileTestContent = `
+name: base
+port: 8080
+log:
+  level: debug
+  format: text
+profiles:
+  prod:
+    port: 80
+    log:
+      level: warn
+  staging:
+    name: staging
+`
+
+func TestProfileMergedOverBase(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, profileTestContent, profileTestConfig{},
+		config.OptStrictParsing(),
+		config.OptProfile("prod"),
+	)
+	assert.That(errs, pred.IsEmpty())
+
+	c := cfg.(*profileTestConfig)
+	assert.That(c.Name, pred.IsEqualTo("base"))
+	assert.That(c.Port, pred.IsEqualTo(80))
+	assert.That(c.Log.Level, pred.IsEqualTo("warn"))
+	assert.That(c.Log.Format, pred.IsEqualTo("text"))
+}
+
+func TestProfileUnknownUsesBase(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, profileTestContent, profileTestConfig{},
+		config.OptStrictParsing(),
+		config.OptProfile("dev"),
+	)
+	assert.That(errs, pred.IsEmpty())
+
+	c := cfg.(*profileTestConfig)
+	assert.That(c.Name, pred.IsEqualTo("base"))
+	assert.That(c.Port, pred.IsEqualTo(8080))
+	assert.That(c.Log.Level, pred.IsEqualTo("debug"))
+}
+
+func TestProfileFromEnv(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	os.Setenv("TEST_CONFIG_PROFILE", "staging")
+	defer os.Unsetenv("TEST_CONFIG_PROFILE")
+
+	cfg, errs := loadConfig(t, profileTestContent, profileTestConfig{},
+		config.OptProfileFromEnv("TEST_CONFIG_PROFILE"),
+	)
+	assert.That(errs, pred.IsEmpty())
+
+	c := cfg.(*profileTestConfig)
+	assert.That(c.Name, pred.IsEqualTo("staging"))
+	assert.That(c.Port, pred.IsEqualTo(8080))
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OptErrorDeduplication suppresses repeated identical errors, e.g. from a
+// configuration file that stays malformed for hours. The first occurrence of
+// an error is delivered to the error handlers as usual; repetitions are then
+// delivered as a RepeatedError summary after interval, and at doubling
+// intervals after that. A different error or a successful load resets the
+// deduplication.
+func OptErrorDeduplication(interval time.Duration) Option {
+	return func(c *Loader) {
+		c.errorLimiter = &errorLimiter{interval: interval}
+	}
+}
+
+// RepeatedError summarizes the repetitions of an error suppressed by
+// OptErrorDeduplication
+type RepeatedError struct {
+	Err   error
+	Count int
+	Since time.Time
+}
+
+func (e *RepeatedError) Error() string {
+	return fmt.Sprintf("%v (repeated %v times since %v)",
+		e.Err, e.Count, e.Since.Format(time.RFC3339))
+}
+
+// Unwrap returns the repeated error
+func (e *RepeatedError) Unwrap() error {
+	return e.Err
+}
+
+// ---------------------------------------------------------------------------
+// errorLimiter implementation
+// ---------------------------------------------------------------------------
+
+type errorLimiter struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	last    string
+	count   int
+	since   time.Time
+	delay   time.Duration
+	nextDue time.Time
+}
+
+// filter returns the error to deliver to error handlers in place of err, or
+// nil if err should be suppressed
+func (l *errorLimiter) filter(err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if msg := err.Error(); msg != l.last {
+		l.last = msg
+		l.count = 1
+		l.since = now
+		l.delay = l.interval
+		l.nextDue = now.Add(l.delay)
+		return err
+	}
+
+	l.count++
+	if now.Before(l.nextDue) {
+		return nil
+	}
+	l.delay *= 2
+	l.nextDue = now.Add(l.delay)
+	return &RepeatedError{Err: err, Count: l.count, Since: l.since}
+}
+
+func (l *errorLimiter) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.last = ""
+}
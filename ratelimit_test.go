@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// pushSource is a Source whose content and change notifications are driven
+// by the test
+type pushSource struct {
+	mu      sync.Mutex
+	content []byte
+	changes chan struct{}
+}
+
+func newPushSource(content string) *pushSource {
+	return &pushSource{content: []byte(content), changes: make(chan struct{})}
+}
+
+func (s *pushSource) push(content string) {
+	s.mu.Lock()
+	s.content = []byte(content)
+	s.mu.Unlock()
+	s.changes <- struct{}{}
+}
+
+func (s *pushSource) Read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.content, nil
+}
+
+func (s *pushSource) Changes() <-chan struct{} { return s.changes }
+func (s *pushSource) Close() error             { close(s.changes); return nil }
+
+func TestErrorDeduplication(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var mu sync.Mutex
+	var errs []error
+	src := newPushSource("name: valid\n")
+	c, err := config.NewLoaderFromSource(src, testConfigDefaults,
+		config.OptStrictParsing(),
+		config.OptDebounceInterval(0),
+		config.OptErrorDeduplication(200*time.Millisecond),
+		config.ErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		src.push("nmae: invalid\n")
+	}
+	time.Sleep(250 * time.Millisecond)
+	src.push("nmae: invalid\n")
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	assert.That(errs, pred.Length(pred.IsEqualTo(2)))
+	var repeated *config.RepeatedError
+	assert.That(errors.As(errs[1], &repeated), pred.IsEqualTo(true))
+	assert.That(repeated.Count, pred.IsEqualTo(6))
+	mu.Unlock()
+
+	src.push("name: valid\n")
+	time.Sleep(50 * time.Millisecond)
+	src.push("nmae: invalid\n")
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	assert.That(errs, pred.Length(pred.IsEqualTo(3)))
+	assert.That(errors.As(errs[2], &repeated), pred.IsEqualTo(false))
+	mu.Unlock()
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// RawSection captures a subtree of the configuration without decoding it,
+// so that it can be decoded later on demand into a type unknown to the main
+// configuration struct, e.g. the configuration of a plugin.
+type RawSection struct {
+	data interface{}
+}
+
+var rawSectionType = reflect.TypeOf(RawSection{})
+
+// rawSectionDecodeHook captures the raw value of RawSection fields
+func rawSectionDecodeHook(data interface{}, to reflect.Type) (interface{}, error) {
+	if to != rawSectionType {
+		return data, nil
+	}
+	return RawSection{data: data}, nil
+}
+
+// IsEmpty returns true if the section is missing or null in the
+// configuration.
+func (s RawSection) IsEmpty() bool {
+	return s.data == nil
+}
+
+// Decode decodes the section into v, which must be a non-nil pointer. Like
+// the main configuration, the section is merged over the existing content of
+// v, which can hold defaults.
+func (s RawSection) Decode(v interface{}) error {
+	d := &decoder{hooks: builtinDecodeHooks}
+	return d.decode(s.data, v)
+}
+
+// DecodeStrict is like Decode, but fails on fields that do not match any
+// field of v.
+func (s RawSection) DecodeStrict(v interface{}) error {
+	d := &decoder{hooks: builtinDecodeHooks, strict: true}
+	return d.decode(s.data, v)
+}
+
+// MarshalJSON returns the raw content of the section.
+func (s RawSection) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.data)
+}
+
+// UnmarshalJSON captures the raw content of the section.
+func (s *RawSection) UnmarshalJSON(b []byte) error {
+	var data interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&data); err != nil {
+		return err
+	}
+	s.data = data
+	return nil
+}
@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type pluginHostConfig struct {
+	Name    string                       `json:"name"`
+	Plugins map[string]config.RawSection `json:"plugins"`
+	Extra   config.RawSection            `json:"extra"`
+}
+
+type cachePluginConfig struct {
+	Size    int           `json:"size"`
+	TTL     time.Duration `json:"ttl"`
+	Enabled bool          `json:"enabled"`
+}
+
+func TestRawSection(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+name: host
+plugins:
+  cache:
+    size: 10
+    ttl: 5m
+`, pluginHostConfig{})
+	assert.That(errs, pred.IsEmpty())
+
+	cfg := icfg.(*pluginHostConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("host"))
+	assert.That(cfg.Extra.IsEmpty(), pred.IsEqualTo(true))
+	assert.That(cfg.Plugins["cache"].IsEmpty(), pred.IsEqualTo(false))
+
+	cache := cachePluginConfig{Size: 1, Enabled: true}
+	err := cfg.Plugins["cache"].Decode(&cache)
+	assert.That(err, pred.IsNil())
+	assert.That(cache.Size, pred.IsEqualTo(10))
+	assert.That(cache.TTL, pred.IsEqualTo(5*time.Minute))
+	assert.That(cache.Enabled, pred.IsEqualTo(true))
+}
+
+func TestRawSectionDecodeErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	icfg, errs := loadConfig(t, `
+plugins:
+  cache:
+    size: large
+    color: red
+`, pluginHostConfig{})
+	assert.That(errs, pred.IsEmpty())
+	section := icfg.(*pluginHostConfig).Plugins["cache"]
+
+	var cache cachePluginConfig
+	err := section.Decode(&cache)
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("size"))
+
+	var loose struct{}
+	err = section.Decode(&loose)
+	assert.That(err, pred.IsNil())
+	err = section.DecodeStrict(&loose)
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("color"))
+}
+
+func TestRawSectionMarshalJSON(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var section config.RawSection
+	err := section.UnmarshalJSON([]byte(`{"size":10}`))
+	assert.That(err, pred.IsNil())
+
+	j, err := section.MarshalJSON()
+	assert.That(err, pred.IsNil())
+	assert.That(string(j), pred.IsEqualTo(`{"size":10}`))
+}
@@ -0,0 +1,46 @@
+package config
+
+import "fmt"
+
+// OptRecoverHandlerPanics recovers from panics raised by reload, change,
+// validation and error handlers, reporting the recovered value to f instead
+// of crashing the process from the background reload goroutine. A panicking
+// validation handler aborts the update like a validation error.
+func OptRecoverHandlerPanics(f func(recovered interface{})) Option {
+	return func(c *Loader) {
+		c.panicHandler = f
+	}
+}
+
+// invokeHandler calls f, recovering from any panic if OptRecoverHandlerPanics
+// is set, in which case the recovered value is returned
+func (c *Loader) invokeHandler(f func()) (recovered interface{}) {
+	if c.panicHandler == nil {
+		f()
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			recovered = r
+			c.panicHandler(r)
+		}
+	}()
+	f()
+	return nil
+}
+
+// invokeValidationHandler calls a validation handler through invokeHandler,
+// turning a recovered panic into an error
+func (c *Loader) invokeValidationHandler(
+	validate func(interface{}) (interface{}, error), cfg interface{}) (
+	validated interface{}, err error) {
+
+	r := c.invokeHandler(func() {
+		validated, err = validate(cfg)
+	})
+	if r != nil {
+		return nil, fmt.Errorf("validation handler panic: %v", r)
+	}
+	return validated, err
+}
@@ -0,0 +1,60 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestRecoverReloadHandlerPanics(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var recovered []interface{}
+	var reloaded []interface{}
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.OptRecoverHandlerPanics(func(r interface{}) {
+			recovered = append(recovered, r)
+		}),
+		config.ReloadHandler(func(cfg interface{}) {
+			panic("buggy handler")
+		}),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded = append(reloaded, cfg)
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(c.Update([]byte("name: updated\n")), pred.IsNil())
+	assert.That(recovered, pred.IsEqualTo([]interface{}{"buggy handler"}))
+	assert.That(reloaded, pred.Length(pred.IsEqualTo(1)))
+}
+
+func TestRecoverValidationHandlerPanics(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var recovered []interface{}
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.OptRecoverHandlerPanics(func(r interface{}) {
+			recovered = append(recovered, r)
+		}),
+		config.OptKeepLatestOnFailure(),
+		config.ValidationHandler(func(cfg interface{}) (interface{}, error) {
+			if cfg.(*testConfig).Name == "boom" {
+				panic("buggy validation")
+			}
+			return cfg, nil
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	err = c.Update([]byte("name: boom\n"))
+	assert.That(err, pred.IsNotNil())
+	assert.That(err.Error(), pred.Contains("buggy validation"))
+	assert.That(recovered, pred.Length(pred.IsEqualTo(1)))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("initial"))
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces secret values in redacted documents
+const RedactedValue = "<redacted>"
+
+// secretKeyWords lists the words identifying keys that hold secrets
+var secretKeyWords = []string{
+	"password", "passwd", "secret", "token", "credential", "apikey",
+	"privatekey", "accesskey",
+}
+
+// IsSecretKey reports whether a configuration key looks like it holds a
+// secret, e.g. "password", "api_token", "clientSecret" or "private-key".
+func IsSecretKey(key string) bool {
+	k := strings.ToLower(key)
+	k = strings.NewReplacer("_", "", "-", "", ".", "").Replace(k)
+	for _, w := range secretKeyWords {
+		if strings.Contains(k, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactDocument returns a copy of a raw configuration document where the
+// values of secret keys, as reported by IsSecretKey, and encrypted values are
+// replaced by RedactedValue.
+func RedactDocument(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		r := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if IsSecretKey(k) && e != nil {
+				r[k] = RedactedValue
+			} else {
+				r[k] = RedactDocument(e)
+			}
+		}
+		return r
+
+	case []interface{}:
+		r := make([]interface{}, len(v))
+		for i, e := range v {
+			r[i] = RedactDocument(e)
+		}
+		return r
+
+	case string:
+		if strings.HasPrefix(v, EncryptedValuePrefix) {
+			return RedactedValue
+		}
+	}
+	return doc
+}
+
+// isSecretPath returns true if any key of a dotted path is a secret key
+func isSecretPath(path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		if idx := strings.Index(key, "["); idx != -1 {
+			key = key[:idx]
+		}
+		if IsSecretKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedConfig returns the raw document of the active configuration, with
+// secret values redacted as with RedactDocument. Values that were encrypted in
+// the configuration file, set from the environment, or held by fields tagged
+// with `secret:"..."` are redacted as well, since their keys alone do not
+// identify them as secrets.
+func (c *Loader) redactedConfig() (interface{}, error) {
+	c.mu.Lock()
+	cfg := c.config.Load()
+	paths := make(map[string]bool, len(c.redactedPaths))
+	for path := range c.redactedPaths {
+		paths[path] = true
+	}
+	c.mu.Unlock()
+
+	walkNamedFields(reflect.ValueOf(cfg), "", c.keyNaming, func(f fieldInfo) {
+		if _, ok := f.Field.Tag.Lookup("secret"); ok {
+			paths[f.Path] = true
+		}
+	})
+	doc, err := encodeValue(reflect.ValueOf(cfg), c.keyNaming)
+	if err != nil {
+		return nil, err
+	}
+	return RedactDocument(redactPaths(doc, paths, "")), nil
+}
+
+// redactPaths returns a copy of a raw document where the non-nil values at
+// the given paths are replaced by RedactedValue
+func redactPaths(doc interface{}, paths map[string]bool, path string) interface{} {
+	if path != "" && paths[path] {
+		if doc == nil {
+			return nil
+		}
+		return RedactedValue
+	}
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		r := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			r[k] = redactPaths(e, paths, keyPath(path, k))
+		}
+		return r
+
+	case []interface{}:
+		r := make([]interface{}, len(v))
+		for i, e := range v {
+			r[i] = redactPaths(e, paths, indexPath(path, i))
+		}
+		return r
+	}
+	return doc
+}
@@ -0,0 +1,46 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// Test secret redaction
+// ---------------------------------------------------------------------------
+
+func TestIsSecretKey(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	for _, key := range []string{"password", "DB_PASSWORD", "api_token", "clientSecret", "private-key", "apiKey"} {
+		assert.That(config.IsSecretKey(key), pred.IsEqualTo(true))
+	}
+	assert.That(config.IsSecretKey("name"), pred.IsEqualTo(false))
+	assert.That(config.IsSecretKey("port"), pred.IsEqualTo(false))
+}
+
+func TestRedactDocument(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	doc := map[string]interface{}{
+		"name": "test",
+		"db": map[string]interface{}{
+			"password": "secret",
+			"hosts":    []interface{}{"a", "b"},
+		},
+		"key": config.EncryptedValuePrefix + "abcd",
+	}
+	assert.That(config.RedactDocument(doc), pred.IsEqualTo(map[string]interface{}{
+		"name": "test",
+		"db": map[string]interface{}{
+			"password": config.RedactedValue,
+			"hosts":    []interface{}{"a", "b"},
+		},
+		"key": config.RedactedValue,
+	}))
+	assert.That(doc["db"].(map[string]interface{})["password"], pred.IsEqualTo("secret"))
+}
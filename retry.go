@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// OptReadRetry retries reading the configuration when it fails with a
+// transient I/O error, e.g. EBUSY or ESTALE on NFS, or a sharing violation on
+// Windows. Up to attempts retries are made, waiting for backoff before the
+// first one and doubling the delay before each of the following ones. The
+// reload only fails if all attempts fail.
+func OptReadRetry(attempts int, backoff time.Duration) Option {
+	return func(c *Loader) {
+		c.readRetries = attempts
+		c.readBackoff = backoff
+	}
+}
+
+// readSource reads the content of the configuration source, retrying on
+// transient errors according to OptReadRetry. Read failures are reported as
+// IOError, unless the source reports a ParseError.
+func (c *Loader) readSource() ([]byte, error) {
+	content, err := c.source.Read()
+	delay := c.readBackoff
+	for i := 0; i < c.readRetries && err != nil && isTransientError(err); i++ {
+		c.logger.Printf("transient read error, retrying in %v: %v", delay, err)
+		time.Sleep(delay)
+		delay *= 2
+		content, err = c.source.Read()
+	}
+	if err != nil {
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			err = &IOError{Err: err}
+		}
+		return nil, err
+	}
+	return content, nil
+}
+
+// isTransientError returns true for I/O errors that are expected to resolve
+// on their own after a short delay
+func isTransientError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	if errno.Temporary() {
+		return true
+	}
+	for _, e := range transientErrnos {
+		if errno == e {
+			return true
+		}
+	}
+	return false
+}
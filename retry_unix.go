@@ -0,0 +1,8 @@
+//go:build !windows
+
+package config
+
+import "syscall"
+
+// transientErrnos lists the platform specific transient error codes
+var transientErrnos = []syscall.Errno{syscall.EBUSY, syscall.ESTALE}
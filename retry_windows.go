@@ -0,0 +1,7 @@
+package config
+
+import "syscall"
+
+// transientErrnos lists the platform specific transient error codes:
+// ERROR_SHARING_VIOLATION and ERROR_LOCK_VIOLATION
+var transientErrnos = []syscall.Errno{32, 33}
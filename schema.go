@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/marcus999/go-config/pkg/schema"
+)
+
+// GenerateSchema returns a JSON Schema describing the configuration files
+// accepted for the defaults configuration struct, following the same key
+// naming rules as the decoder. Field descriptions are taken from their
+// `doc:"..."` tag, and non-zero default values are recorded as defaults. With
+// OptStrictParsing, unknown keys are rejected by the schema.
+func GenerateSchema(defaults interface{}, opts ...Option) (*schema.Schema, error) {
+	v := reflect.ValueOf(defaults)
+	if !v.IsValid() || !isStructType(v.Type()) {
+		return nil, fmt.Errorf("cannot generate schema for non-struct type %T", defaults)
+	}
+	c := newLoader(defaults, opts)
+	g := schemaGenerator{naming: c.keyNaming, strict: c.strictParsing}
+	s := g.schema(v.Type(), v)
+	s.Default = nil
+	return s, nil
+}
+
+type schemaGenerator struct {
+	naming KeyNaming
+	strict bool
+}
+
+// schema returns the schema of values of type t. v is the default value, or
+// an invalid value when there is none.
+func (g *schemaGenerator) schema(t reflect.Type, v reflect.Value) *schema.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			if v.IsNil() {
+				v = reflect.Value{}
+			} else {
+				v = v.Elem()
+			}
+		}
+	}
+
+	s := g.typeSchema(t, v)
+	if v.IsValid() && !v.IsZero() && t.Kind() != reflect.Struct {
+		if d, err := encodeValue(v, g.naming); err == nil {
+			s.Default = d
+		}
+	}
+	return s
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type, v reflect.Value) *schema.Schema {
+	switch t {
+	case durationType, byteSizeType:
+		return &schema.Schema{Type: schema.Types{"string", "number"}}
+	case urlType, ipNetType:
+		return &schema.Schema{Type: schema.Types{"string"}}
+	case rawSectionType:
+		return &schema.Schema{}
+	}
+	p := reflect.PtrTo(t)
+	if p.Implements(jsonUnmarshalerType) {
+		return &schema.Schema{}
+	}
+	if p.Implements(textUnmarshalerType) {
+		return &schema.Schema{Type: schema.Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schema.Schema{Type: schema.Types{"boolean"}}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &schema.Schema{Type: schema.Types{"integer"}}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min := 0.0
+		return &schema.Schema{Type: schema.Types{"integer"}, Minimum: &min}
+
+	case reflect.Float32, reflect.Float64:
+		return &schema.Schema{Type: schema.Types{"number"}}
+
+	case reflect.String:
+		return &schema.Schema{Type: schema.Types{"string"}}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema.Schema{Type: schema.Types{"string"}}
+		}
+		return &schema.Schema{
+			Type:  schema.Types{"array"},
+			Items: g.schema(t.Elem(), reflect.Value{}),
+		}
+
+	case reflect.Map:
+		return &schema.Schema{
+			Type:                 schema.Types{"object"},
+			AdditionalProperties: g.schema(t.Elem(), reflect.Value{}),
+		}
+
+	case reflect.Struct:
+		return g.structSchema(t, v)
+	}
+	return &schema.Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type, v reflect.Value) *schema.Schema {
+	s := &schema.Schema{
+		Type:       schema.Types{"object"},
+		Properties: map[string]*schema.Schema{},
+	}
+	if g.strict {
+		s.AdditionalProperties = schema.False
+	}
+
+	for _, f := range structFields(t, g.naming) {
+		sf := t.FieldByIndex(f.index)
+		fs := g.schema(sf.Type, fieldValue(v, f.index))
+		fs.Description = sf.Tag.Get("doc")
+		if f.deprecated {
+			if fs.Description != "" {
+				fs.Description += " "
+			}
+			fs.Description += "Deprecated"
+			if f.deprecation != "" {
+				fs.Description += ": " + f.deprecation
+			}
+		}
+		s.Properties[f.name] = fs
+	}
+	return s
+}
+
+// fieldValue returns the field of v at index, or an invalid value if v is
+// invalid or the field is reached through a nil pointer
+func fieldValue(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if !v.IsValid() {
+			return v
+		}
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
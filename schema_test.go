@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/schema"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type schemaTestConfig struct {
+	Name    string        `json:"name" doc:"Name of the service"`
+	Port    uint16        `json:"port"`
+	Timeout time.Duration `json:"timeout"`
+	Tags    []string      `json:"tags"`
+	Limits  map[string]int
+	Server  struct {
+		Host string `json:"host"`
+	} `json:"server"`
+	Old string `json:"old" deprecated:"use name"`
+}
+
+// ---------------------------------------------------------------------------
+// Test schema generation
+// ---------------------------------------------------------------------------
+
+func TestGenerateSchema(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := schemaTestConfig{Name: "svc", Timeout: time.Second}
+	s, err := config.GenerateSchema(defaults)
+	assert.That(err, pred.IsNil())
+
+	assert.That(s.Type, pred.IsEqualTo(schema.Types{"object"}))
+	assert.That(s.AdditionalProperties == nil, pred.IsEqualTo(true))
+	assert.That(s.Properties["name"].Type, pred.IsEqualTo(schema.Types{"string"}))
+	assert.That(s.Properties["name"].Description, pred.IsEqualTo("Name of the service"))
+	assert.That(s.Properties["name"].Default, pred.IsEqualTo("svc"))
+	assert.That(s.Properties["port"].Type, pred.IsEqualTo(schema.Types{"integer"}))
+	assert.That(s.Properties["port"].Default, pred.IsNil())
+	assert.That(s.Properties["timeout"].Default, pred.IsEqualTo("1s"))
+	assert.That(s.Properties["tags"].Items.Type, pred.IsEqualTo(schema.Types{"string"}))
+	assert.That(s.Properties["Limits"].AdditionalProperties.Type, pred.IsEqualTo(schema.Types{"integer"}))
+	assert.That(s.Properties["server"].Properties["host"].Type, pred.IsEqualTo(schema.Types{"string"}))
+	assert.That(s.Properties["old"].Description, pred.IsEqualTo("Deprecated: use name"))
+}
+
+func TestGenerateSchemaWithStrictParsing(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	s, err := config.GenerateSchema(&schemaTestConfig{}, config.OptStrictParsing())
+	assert.That(err, pred.IsNil())
+
+	data, err := json.Marshal(s)
+	assert.That(err, pred.IsNil())
+	assert.That(string(data), pred.Contains(`"additionalProperties":false`))
+
+	errs := s.Validate(map[string]interface{}{"name": "x", "unknown": true})
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+}
+
+func TestGenerateSchemaWithNonStruct(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	_, err := config.GenerateSchema(42)
+	assert.That(err, pred.IsNotNil())
+}
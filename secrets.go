@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// OptSecretsDir reads the fields tagged with `secret:"name"` from the file
+// of that name in dir, following the Docker and Kubernetes convention of
+// mounting one file per secret, e.g. /run/secrets/db_password. A trailing
+// newline is ignored, and values are decoded like environment variables. The
+// option can be repeated; directories are searched in order, and fields
+// without a matching file keep their value. When loading from a file, the
+// secret files are watched like the configuration file, so that rotated
+// secrets are reloaded.
+func OptSecretsDir(dir string) Option {
+	return func(c *Loader) {
+		c.secretsDirs = append(c.secretsDirs, dir)
+	}
+}
+
+// applySecretFiles overrides the fields of cfg tagged with `secret:"name"`
+// with the content of the corresponding file of the secrets directories
+func (c *Loader) applySecretFiles(cfg interface{}) error {
+	if len(c.secretsDirs) == 0 {
+		return nil
+	}
+	d := c.newDecoder()
+	var errs []error
+	walkFields(reflect.ValueOf(cfg), "", func(f fieldInfo) {
+		name := f.Field.Tag.Get("secret")
+		if name == "" || !f.Value.CanSet() {
+			return
+		}
+		value, ok, err := c.readSecretFile(name)
+		if err == nil && ok {
+			err = decodeScalar(d, value, f)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("secret %v: %w", name, err))
+		}
+	})
+	return joinErrors(errs)
+}
+
+// readSecretFile returns the content of the first secret file with the given
+// name, or false if there is none
+func (c *Loader) readSecretFile(name string) (string, bool, error) {
+	for _, dir := range c.secretsDirs {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+	return "", false, nil
+}
+
+// secretFilenames returns the names of all the secret files that the default
+// configuration can read, for watching
+func (c *Loader) secretFilenames() []string {
+	var filenames []string
+	walkFields(reflect.ValueOf(c.defaultConfig), "", func(f fieldInfo) {
+		if name := f.Field.Tag.Get("secret"); name != "" {
+			for _, dir := range c.secretsDirs {
+				filenames = append(filenames, filepath.Join(dir, name))
+			}
+		}
+	})
+	return filenames
+}
@@ -0,0 +1,107 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type secretsDirConfig struct {
+	DB struct {
+		User     string `json:"user"`
+		Password string `json:"password" secret:"db_password"`
+		Port     int    `json:"port" secret:"db_port"`
+	} `json:"db"`
+	Token string `json:"token" secret:"api_token"`
+}
+
+func writeSecretsDir(t *testing.T, files map[string]string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go-config-secrets-")
+	if err != nil {
+		t.Fatalf("failed to create secrets directory, %v", err)
+	}
+	for name, content := range files {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// ---------------------------------------------------------------------------
+// Test secrets directories
+// ---------------------------------------------------------------------------
+
+func TestOptSecretsDir(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	run, cleanupRun := writeSecretsDir(t, map[string]string{
+		"db_password": "s3cr3t\n",
+	})
+	defer cleanupRun()
+	etc, cleanupEtc := writeSecretsDir(t, map[string]string{
+		"db_password": "ignored\n",
+		"db_port":     "6543",
+	})
+	defer cleanupEtc()
+
+	icfg, errs := loadConfig(t, "db:\n  user: admin\n  password: default\ntoken: t0k3n\n",
+		secretsDirConfig{}, config.OptSecretsDir(run), config.OptSecretsDir(etc))
+	assert.That(errs, pred.IsEmpty())
+	cfg := icfg.(*secretsDirConfig)
+	assert.That(cfg.DB.User, pred.IsEqualTo("admin"))
+	assert.That(cfg.DB.Password, pred.IsEqualTo("s3cr3t"))
+	assert.That(cfg.DB.Port, pred.IsEqualTo(6543))
+	assert.That(cfg.Token, pred.IsEqualTo("t0k3n"))
+}
+
+func TestOptSecretsDirWithInvalidValue(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	dir, cleanup := writeSecretsDir(t, map[string]string{
+		"db_port": "not-a-number",
+	})
+	defer cleanup()
+
+	_, errs := loadConfig(t, "token: t0k3n\n", secretsDirConfig{}, config.OptSecretsDir(dir))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+	assert.That(errs[0].Error(), pred.Contains("secret db_port"))
+}
+
+func TestOptSecretsDirReloadsOnRotation(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "db:\n  user: admin\n")
+	defer cleanup()
+	dir, cleanupDir := writeSecretsDir(t, map[string]string{
+		"db_password": "first",
+	})
+	defer cleanupDir()
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, secretsDirConfig{},
+		config.OptSecretsDir(dir),
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get().(*secretsDirConfig).DB.Password, pred.IsEqualTo("first"))
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(filepath.Join(dir, "db_password"), []byte("second"), 0600)
+	select {
+	case cfg := <-reloaded:
+		assert.That(cfg.(*secretsDirConfig).DB.Password, pred.IsEqualTo("second"))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for reload after secret rotation")
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus999/go-config/pkg/watch"
+)
+
+// Source provides the raw content of a configuration, and notifies the
+// loader when that content changes
+type Source interface {
+	// Read returns the current content of the configuration
+	Read() ([]byte, error)
+
+	// Changes returns a channel receiving a value every time the content of
+	// the configuration may have changed, and closed when the source is
+	// closed. It returns nil if the content never changes.
+	Changes() <-chan struct{}
+
+	// Close stops watching for changes and releases associated resources
+	Close() error
+}
+
+// ErrorSource is implemented by sources that report errors occurring while
+// watching for changes. The loader passes those errors to its error handlers
+// as *WatchError.
+type ErrorSource interface {
+	Source
+
+	// Errors returns a channel receiving errors that occur while watching for
+	// changes, and closed when the source is closed
+	Errors() <-chan error
+}
+
+// ---------------------------------------------------------------------------
+// file source
+// ---------------------------------------------------------------------------
+
+// fileSource reads a configuration file, merging optional overlay files over
+// it in order, and watches all of them along with additional files the
+// configuration depends on. With overlays, the document of each file is
+// selected before merging, and the merged document is returned as JSON.
+type fileSource struct {
+	filename string
+	overlays []string
+	selector documentSelector
+	watched  []string
+	closers  []func() error
+	changes  chan struct{}
+	errors   chan error
+	logger   Logger
+	wg       sync.WaitGroup
+}
+
+func newFileSource(filename string, overlays, watched []string, pollInterval time.Duration, logger Logger, opts []watch.Option) *fileSource {
+	s := &fileSource{
+		filename: filename,
+		overlays: overlays,
+		watched:  watched,
+		changes:  make(chan struct{}),
+		errors:   make(chan error),
+		logger:   logger,
+	}
+
+	files := append([]string{filename}, overlays...)
+	s.watch(append(files, watched...), pollInterval, opts)
+	go func() {
+		s.wg.Wait()
+		close(s.changes)
+		close(s.errors)
+	}()
+	return s
+}
+
+func (s *fileSource) Read() ([]byte, error) {
+	content, err := ioutil.ReadFile(s.filename)
+	if err != nil || len(s.overlays) == 0 {
+		return content, err
+	}
+
+	doc, err := selectDocument(content, s.selector)
+	if err != nil {
+		return nil, &ParseError{Err: fmt.Errorf("%v: %w", s.filename, err)}
+	}
+	for _, overlay := range s.overlays {
+		overlayContent, err := ioutil.ReadFile(overlay)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		overlayDoc, err := selectOverlayDocument(overlayContent, s.selector)
+		if err != nil {
+			return nil, &ParseError{Err: fmt.Errorf("%v: %w", overlay, err)}
+		}
+		if overlayDoc != nil {
+			doc = mergeDocuments(doc, overlayDoc)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func (s *fileSource) Changes() <-chan struct{} {
+	return s.changes
+}
+
+func (s *fileSource) Errors() <-chan error {
+	return s.errors
+}
+
+func (s *fileSource) Close() error {
+	var err error
+	for _, closeWatcher := range s.closers {
+		if cerr := closeWatcher(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// watch starts watching filenames with a single notification based watcher,
+// or with one polling watcher per file if pollInterval is set or if
+// notifications are not available. opts apply to the notification based
+// watcher.
+func (s *fileSource) watch(filenames []string, pollInterval time.Duration, opts []watch.Option) {
+	if pollInterval <= 0 {
+		opts = append([]watch.Option{watch.WithLogger(s.logger)}, opts...)
+		w, err := watch.NewMultiFileWatcher(filenames, opts...)
+		if err == nil {
+			s.closers = append(s.closers, w.Close)
+			s.wg.Add(2)
+			go forwardChanges(s, w.Events())
+			go forwardErrors(s, w.Errors())
+			return
+		}
+		s.logger.Printf("failed to watch %v, polling every %v instead: %v",
+			filenames, DefaultPollInterval, err)
+		pollInterval = DefaultPollInterval
+	}
+
+	for _, filename := range filenames {
+		w, err := watch.NewPollingWatcher(filename, pollInterval, watch.WithLogger(s.logger))
+		if err != nil {
+			s.logger.Printf("failed to watch %v: %v", filename, err)
+			continue
+		}
+		s.closers = append(s.closers, w.Close)
+		s.wg.Add(2)
+		go forwardChanges(s, w.Events())
+		go forwardErrors(s, w.Errors())
+	}
+}
+
+// forwardChanges notifies a change of the source for every watcher event
+// received on ch, until ch is closed
+func forwardChanges[E any](s *fileSource, ch <-chan E) {
+	defer s.wg.Done()
+	for e := range ch {
+		s.logger.Printf("watcher event: %v", e)
+		s.changes <- struct{}{}
+	}
+}
+
+// forwardErrors reports the errors of a watcher received on ch, until ch is
+// closed
+func forwardErrors(s *fileSource, ch <-chan error) {
+	defer s.wg.Done()
+	for err := range ch {
+		s.errors <- err
+	}
+}
+
+// overlayFilenames returns the names of the overlay files of a configuration
+// file in increasing order of precedence, e.g. "config.prod.yaml" and
+// "config.local.yaml" for "config.yaml" and the "prod" environment.
+func overlayFilenames(filename, env string) []string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+
+	var overlays []string
+	if env != "" {
+		overlays = append(overlays, base+"."+env+ext)
+	}
+	return append(overlays, base+".local"+ext)
+}
+
+// ---------------------------------------------------------------------------
+// in-memory source
+// ---------------------------------------------------------------------------
+
+type bytesSource struct {
+	data []byte
+}
+
+func (s *bytesSource) Read() ([]byte, error) {
+	return s.data, nil
+}
+
+func (s *bytesSource) Changes() <-chan struct{} {
+	return nil
+}
+
+func (s *bytesSource) Close() error {
+	return nil
+}
@@ -0,0 +1,261 @@
+package config_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestLoaderFromBytes(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var reloaded []interface{}
+	c, err := config.NewLoaderFromBytes([]byte("name: embedded\n"), testConfigDefaults,
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded = append(reloaded, cfg)
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	cfg := c.Get().(*testConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("embedded"))
+	assert.That(cfg.Port, pred.IsEqualTo(testConfigDefaults.Port))
+	assert.That(reloaded, pred.IsEmpty())
+
+	err = c.Update([]byte("name: updated\nport: 80\n"))
+	assert.That(err, pred.IsNil())
+	assert.That(reloaded, pred.Length(pred.IsEqualTo(1)))
+
+	cfg = c.Get().(*testConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("updated"))
+	assert.That(cfg.Port, pred.IsEqualTo(80))
+}
+
+func TestLoaderFromBytesUpdateError(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: embedded\n"), testConfigDefaults,
+		config.OptKeepLatestOnFailure(),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	err = c.Update([]byte("port: invalid\n"))
+	assert.That(err, pred.IsNotNil())
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("embedded"))
+	assert.That(c.Status().LastError, pred.IsEqualTo(err))
+}
+
+func TestLoaderFromReader(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromReader(strings.NewReader("name: reader\n"), testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("reader"))
+}
+
+func TestLoaderFromStdin(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	r, w, err := os.Pipe()
+	assert.That(err, pred.IsNil())
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	go func() {
+		w.Write([]byte("name: stdin\nport: 80\n"))
+		w.Close()
+	}()
+
+	c, err := config.NewLoader("-", testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	cfg := c.Get().(*testConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("stdin"))
+	assert.That(cfg.Port, pred.IsEqualTo(80))
+}
+
+func TestOverlayFiles(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: base\nport: 8080\n")
+	defer cleanup()
+	dir := filepath.Dir(filename)
+	ioutil.WriteFile(filepath.Join(dir, "config.prod.yaml"), []byte("port: 80\n"), 0666)
+
+	reloaded := make(chan interface{}, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptOverlays("prod"),
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.ReloadHandler(func(cfg interface{}) {
+			reloaded <- cfg
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	cfg := c.Get().(*testConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("base"))
+	assert.That(cfg.Port, pred.IsEqualTo(80))
+
+	time.Sleep(100 * time.Millisecond)
+	ioutil.WriteFile(filepath.Join(dir, "config.local.yaml"), []byte("name: local\n"), 0666)
+
+	select {
+	case icfg := <-reloaded:
+		cfg = icfg.(*testConfig)
+		assert.That(cfg.Name, pred.IsEqualTo("local"))
+		assert.That(cfg.Port, pred.IsEqualTo(80))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for reload")
+	}
+}
+
+func TestOverlayFilesWithMultiDocument(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, multiDocumentContent)
+	defer cleanup()
+	dir := filepath.Dir(filename)
+	ioutil.WriteFile(filepath.Join(dir, "config.local.yaml"), []byte("name: local\n"), 0666)
+
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptOverlays(""),
+		config.OptDocumentIndex(1),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	cfg := c.Get().(*testConfig)
+	assert.That(c.Status().LastError, pred.IsNil())
+	assert.That(cfg.Name, pred.IsEqualTo("local"))
+	assert.That(cfg.Port, pred.IsEqualTo(1234))
+
+	ioutil.WriteFile(filepath.Join(dir, "config.local.yaml"),
+		[]byte("port: 1\n---\nkind: AppConfig\nport: 2\n"), 0666)
+	doc, err := config.ReadDocument(filename,
+		config.OptOverlays(""),
+		config.OptDocumentMatching("kind", "AppConfig"),
+	)
+	assert.That(err, pred.IsNil())
+	assert.That(doc, pred.IsEqualTo(map[string]interface{}{
+		"kind": "AppConfig", "name": "second", "port": json.Number("2"),
+	}))
+
+	err = c.Update([]byte(multiDocumentContent))
+	assert.That(err, pred.IsNil())
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("second"))
+}
+
+func TestOverlayFilesIgnoredByDefault(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: base\n")
+	defer cleanup()
+	ioutil.WriteFile(filepath.Join(filepath.Dir(filename), "config.local.yaml"), []byte("name: local\n"), 0666)
+
+	c, err := config.NewLoader(filename, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("base"))
+}
+
+type flakySource struct {
+	failures int
+	err      error
+	reads    int
+}
+
+func (s *flakySource) Read() ([]byte, error) {
+	s.reads++
+	if s.reads <= s.failures {
+		return nil, s.err
+	}
+	return []byte("name: flaky\n"), nil
+}
+
+func (s *flakySource) Changes() <-chan struct{} { return nil }
+func (s *flakySource) Close() error             { return nil }
+
+func TestReadRetryOnTransientError(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	src := &flakySource{
+		failures: 2,
+		err:      &os.PathError{Op: "open", Path: "config.yaml", Err: syscall.EBUSY},
+	}
+	c, err := config.NewLoaderFromSource(src, testConfigDefaults,
+		config.OptReadRetry(3, time.Millisecond),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(src.reads, pred.IsEqualTo(3))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("flaky"))
+}
+
+func TestReadRetryIgnoresPermanentError(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	src := &flakySource{
+		failures: 2,
+		err:      &os.PathError{Op: "open", Path: "config.yaml", Err: syscall.EACCES},
+	}
+	c, err := config.NewLoaderFromSource(src, testConfigDefaults,
+		config.OptReadRetry(3, time.Millisecond),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(src.reads, pred.IsEqualTo(1))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo(testConfigDefaults.Name))
+}
+
+type failingWatchSource struct {
+	errors chan error
+}
+
+func (s *failingWatchSource) Read() ([]byte, error)    { return []byte("name: watched\n"), nil }
+func (s *failingWatchSource) Changes() <-chan struct{} { return nil }
+func (s *failingWatchSource) Errors() <-chan error     { return s.errors }
+func (s *failingWatchSource) Close() error             { close(s.errors); return nil }
+
+func TestWatchErrorsArePassedToErrorHandlers(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	errs := make(chan error, 1)
+	src := &failingWatchSource{errors: make(chan error)}
+	c, err := config.NewLoaderFromSource(src, testConfigDefaults,
+		config.ErrorHandler(func(err error) {
+			errs <- err
+		}),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	src.errors <- syscall.EACCES
+	select {
+	case err := <-errs:
+		var watchErr *config.WatchError
+		assert.That(errors.As(err, &watchErr), pred.IsEqualTo(true))
+		assert.That(errors.Is(err, syscall.EACCES), pred.IsEqualTo(true))
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for watch error")
+	}
+}
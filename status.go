@@ -0,0 +1,86 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Status describes the state of a Loader and of the configuration it
+// currently holds
+type Status struct {
+	// LastReload is the time at which the active configuration was applied
+	LastReload time.Time
+
+	// Generation is the number of configurations applied so far, including
+	// the initial one
+	Generation uint64
+
+	// LastError is the error that occurred during the last attempt to load
+	// the configuration file, or nil if it was loaded successfully
+	LastError error
+
+	// UsingDefaults is true when the active configuration is the default
+	// configuration, because the configuration file failed to load
+	UsingDefaults bool
+
+	// Checksum is the hex encoded SHA-256 of the content of the
+	// configuration file last read, or an empty string if it could not be
+	// read
+	Checksum string
+
+	// Paused is true if the propagation of configuration changes is paused
+	Paused bool
+}
+
+// Status returns the current status of the loader
+func (c *Loader) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.status
+	s.Paused = c.paused
+	return s
+}
+
+// Generation returns the number of configurations applied so far, including
+// the initial one. Consumers caching state derived from the configuration can
+// compare it to detect staleness.
+func (c *Loader) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status.Generation
+}
+
+// LastReload returns the time at which the active configuration was applied
+func (c *Loader) LastReload() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status.LastReload
+}
+
+func (c *Loader) setChecksum(content []byte, err error) {
+	var checksum string
+	if err == nil {
+		sum := sha256.Sum256(content)
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.Checksum = checksum
+}
+
+func (c *Loader) setLoadResult(err error, applied, usingDefaults bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.LastError = err
+	c.loads++
+	if err != nil {
+		c.loadErrors++
+	}
+	if applied {
+		c.status.Generation++
+		c.status.LastReload = time.Now()
+		c.status.UsingDefaults = usingDefaults
+	}
+}
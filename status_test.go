@@ -0,0 +1,112 @@
+package config_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+func TestStatusAfterSuccessfulLoad(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: test\n")
+	defer cleanup()
+
+	start := time.Now()
+	c, err := config.NewLoader(filename, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+
+	s := c.Status()
+	assert.That(s.LastError, pred.IsNil())
+	assert.That(s.UsingDefaults, pred.IsEqualTo(false))
+	assert.That(s.LastReload.Before(start), pred.IsEqualTo(false))
+	assert.That(s.Checksum, pred.IsEqualTo(fmt.Sprintf("%x", sha256.Sum256([]byte("name: test\n")))))
+	assert.That(s.Paused, pred.IsEqualTo(false))
+}
+
+func TestStatusAfterFailedLoad(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "port: not-a-number\n")
+	defer cleanup()
+
+	c, err := config.NewLoader(filename, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+
+	s := c.Status()
+	assert.That(s.LastError, pred.IsNotNil())
+	assert.That(s.UsingDefaults, pred.IsEqualTo(true))
+	assert.That(s.Checksum, pred.Matches(`^[0-9a-f]{64}$`))
+	assert.That(c.Get(), pred.IsEqualTo(&testConfigDefaults))
+}
+
+func TestStatusWithMissingFile(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoader("a/b/c.yaml", testConfigDefaults)
+	assert.That(err, pred.IsNil())
+
+	s := c.Status()
+	assert.That(s.LastError, pred.IsNotNil())
+	assert.That(s.UsingDefaults, pred.IsEqualTo(true))
+	assert.That(s.Checksum, pred.IsEqualTo(""))
+}
+
+func TestStatusKeepLatestOnFailure(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: test\n")
+	defer cleanup()
+
+	errs := make(chan error, 10)
+	c, err := config.NewLoader(filename, testConfigDefaults,
+		config.OptKeepLatestOnFailure(),
+		config.OptDebounceInterval(20*time.Millisecond),
+		config.ErrorHandler(func(err error) { errs <- err }),
+	)
+	assert.That(err, pred.IsNil())
+	checksum := c.Status().Checksum
+	time.Sleep(100 * time.Millisecond)
+
+	ioutil.WriteFile(filename, []byte("port: not-a-number\n"), 0666)
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for reload error")
+	}
+
+	s := c.Status()
+	assert.That(s.LastError, pred.IsNotNil())
+	assert.That(s.UsingDefaults, pred.IsEqualTo(false))
+	assert.That(s.Checksum, pred.IsNotEqualTo(checksum))
+	assert.That(c.Get().(*testConfig).Name, pred.IsEqualTo("test"))
+}
+
+func TestGenerationAndLastReload(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	c, err := config.NewLoaderFromBytes([]byte("name: initial\n"), testConfigDefaults,
+		config.OptKeepLatestOnFailure(),
+	)
+	assert.That(err, pred.IsNil())
+	defer c.Close()
+
+	assert.That(c.Generation(), pred.IsEqualTo(uint64(1)))
+	initial := c.LastReload()
+	assert.That(initial.IsZero(), pred.IsEqualTo(false))
+
+	c.Update([]byte("name: updated\n"))
+	assert.That(c.Generation(), pred.IsEqualTo(uint64(2)))
+	assert.That(c.LastReload().Before(initial), pred.IsEqualTo(false))
+
+	c.Update([]byte("port: invalid\n"))
+	assert.That(c.Generation(), pred.IsEqualTo(uint64(2)))
+	assert.That(c.Status().Generation, pred.IsEqualTo(uint64(2)))
+}
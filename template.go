@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// WriteTemplate writes a commented YAML configuration file for the defaults
+// configuration struct, listing every field with its default value, preceded
+// by the description found in its `doc:"..."` tag. Keys follow the same
+// naming rules as the decoder, and deprecated fields are omitted.
+func WriteTemplate(w io.Writer, defaults interface{}, opts ...Option) error {
+	v := reflect.ValueOf(defaults)
+	if !v.IsValid() || !isStructType(v.Type()) {
+		return fmt.Errorf("cannot generate template for non-struct type %T", defaults)
+	}
+	c := newLoader(defaults, opts)
+	tw := templateWriter{naming: c.keyNaming}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if err := tw.writeStruct(v.Type(), v, ""); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, tw.b.String())
+	return err
+}
+
+type templateWriter struct {
+	naming KeyNaming
+	b      strings.Builder
+}
+
+// writeStruct writes the fields of struct type t at the given indentation. v
+// is the default value, or an invalid value when there is none.
+func (tw *templateWriter) writeStruct(t reflect.Type, v reflect.Value, indent string) error {
+	for _, f := range structFields(t, tw.naming) {
+		if f.deprecated {
+			continue
+		}
+		if tw.b.Len() > 0 && indent == "" {
+			tw.b.WriteString("\n")
+		}
+		sf := t.FieldByIndex(f.index)
+		fv := fieldValue(v, f.index)
+		if doc := sf.Tag.Get("doc"); doc != "" {
+			for _, line := range strings.Split(doc, "\n") {
+				tw.b.WriteString(strings.TrimRight(indent+"# "+line, " ") + "\n")
+			}
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if fv.IsValid() {
+				if fv.IsNil() {
+					fv = reflect.Value{}
+				} else {
+					fv = fv.Elem()
+				}
+			}
+		}
+		if ft.Kind() == reflect.Struct && !isLeafType(ft) && ft != rawSectionType {
+			tw.b.WriteString(indent + f.name + ":\n")
+			if err := tw.writeStruct(ft, fv, indent+"  "); err != nil {
+				return err
+			}
+			continue
+		}
+		if !fv.IsValid() {
+			fv = reflect.Zero(ft)
+		}
+		if err := tw.writeValue(f.name, fv, indent); err != nil {
+			return fmt.Errorf("%v: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+// writeValue writes a single key and its value, as a nested YAML block for
+// non-empty lists and maps
+func (tw *templateWriter) writeValue(key string, v reflect.Value, indent string) error {
+	value, err := encodeValue(v, tw.naming)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		tw.b.WriteString(indent + key + ":\n")
+		return nil
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	text := strings.TrimRight(string(data), "\n")
+
+	block := false
+	switch x := value.(type) {
+	case map[string]interface{}:
+		block = len(x) != 0
+	case []interface{}:
+		block = len(x) != 0
+	}
+	if !block {
+		tw.b.WriteString(indent + key + ": " + text + "\n")
+		return nil
+	}
+	tw.b.WriteString(indent + key + ":\n")
+	for _, line := range strings.Split(text, "\n") {
+		tw.b.WriteString(indent + "  " + line + "\n")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// Test template generation
+// ---------------------------------------------------------------------------
+
+func TestWriteTemplate(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var b strings.Builder
+	err := config.WriteTemplate(&b, &docConfig{
+		Endpoint: "localhost",
+		Port:     8080,
+		Timeout:  time.Second,
+		Tags:     []string{"a", "b"},
+	})
+	assert.That(err, pred.IsNil())
+	assert.That(b.String(), pred.IsEqualTo(`# Upstream endpoint
+endpoint: localhost
+
+# Listening port
+port: 8080
+
+timeout: 1s
+
+# Tags, separated | by pipes
+tags:
+  - a
+  - b
+
+tls:
+  # Path to the certificate file
+  cert_file: ""
+  # Path to the key file
+  key_file: ""
+`))
+}
+
+func TestWriteTemplateLoadsAsDefaults(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	defaults := schemaTestConfig{Name: "svc", Timeout: time.Second, Limits: map[string]int{"a": 1}}
+	var b strings.Builder
+	err := config.WriteTemplate(&b, defaults)
+	assert.That(err, pred.IsNil())
+	assert.That(b.String(), pred.Contains("# Name of the service\nname: svc\n"))
+	assert.That(strings.Contains(b.String(), "old:"), pred.IsEqualTo(false))
+
+	icfg, errs := loadConfig(t, b.String(), schemaTestConfig{}, config.OptStrictParsing())
+	assert.That(errs, pred.IsEmpty())
+	cfg := icfg.(*schemaTestConfig)
+	assert.That(cfg.Name, pred.IsEqualTo("svc"))
+	assert.That(cfg.Timeout, pred.IsEqualTo(time.Second))
+	assert.That(cfg.Limits, pred.IsEqualTo(map[string]int{"a": 1}))
+}
+
+func TestWriteTemplateWithNonStruct(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var b strings.Builder
+	err := config.WriteTemplate(&b, 42)
+	assert.That(err, pred.IsNotNil())
+}
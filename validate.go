@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/marcus999/go-config/pkg/schema"
+)
+
+// OptSchema checks the raw content of the configuration file against a JSON
+// Schema before decoding it. All mismatches are reported at once, each as a
+// ValidationError, and prevent the configuration from being applied.
+func OptSchema(s *schema.Schema) Option {
+	return func(c *Loader) {
+		c.schema = s
+	}
+}
+
+// checkSchema validates a raw document against the schema set with
+// OptSchema, if any
+func (c *Loader) checkSchema(doc interface{}) error {
+	if c.schema == nil {
+		return nil
+	}
+	var errs []error
+	for _, err := range c.schema.Validate(doc) {
+		errs = append(errs, &ValidationError{Err: err})
+	}
+	return joinErrors(errs)
+}
+
+// Validate checks a configuration file the same way the loader would load
+// it, with strict parsing enabled, and returns all the problems found: parse
+// errors, unknown or mistyped fields, JSON Schema mismatches when used with
+// OptSchema, and errors returned by validation handlers. It returns nil if
+// the file is valid.
+func Validate(filename string, defaultConfig interface{}, opts ...Option) error {
+	opts = append([]Option{OptStrictParsing()}, opts...)
+	_, err := Load(filename, defaultConfig, opts...)
+	return err
+}
@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/marcus999/go-config"
+	"github.com/marcus999/go-config/pkg/schema"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+// ---------------------------------------------------------------------------
+// Test schema validation
+// ---------------------------------------------------------------------------
+
+func testConfigSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 3},
+			"port": {"type": "integer", "maximum": 65535}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("invalid test schema, %v", err)
+	}
+	return s
+}
+
+func TestOptSchema(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, "name: ab\nport: 70000\n", testConfigDefaults,
+		config.OptSchema(testConfigSchema(t)))
+	assert.That(errs, pred.Length(pred.IsEqualTo(1)))
+
+	var verr *config.ValidationError
+	assert.That(errors.As(errs[0], &verr), pred.IsEqualTo(true))
+	var merr *config.MultiError
+	assert.That(errors.As(errs[0], &merr), pred.IsEqualTo(true))
+	assert.That(merr.Errors, pred.Length(pred.IsEqualTo(2)))
+	assert.That(cfg.(*testConfig).Name, pred.IsEqualTo(testConfigDefaults.Name))
+}
+
+func TestOptSchemaWithValidContent(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	cfg, errs := loadConfig(t, "name: abc\nport: 8080\n", testConfigDefaults,
+		config.OptSchema(testConfigSchema(t)))
+	assert.That(errs, pred.IsEmpty())
+	assert.That(cfg.(*testConfig).Port, pred.IsEqualTo(8080))
+}
+
+// ---------------------------------------------------------------------------
+// Test config.Validate()
+// ---------------------------------------------------------------------------
+
+func TestValidate(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: valid\nport: 80\n")
+	defer cleanup()
+
+	err := config.Validate(filename, testConfigDefaults)
+	assert.That(err, pred.IsNil())
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: [1]\nport: abc\nextra: 1\n")
+	defer cleanup()
+
+	err := config.Validate(filename, testConfigDefaults)
+	var merr *config.MultiError
+	assert.That(errors.As(err, &merr), pred.IsEqualTo(true))
+	assert.That(merr.Errors, pred.Length(pred.IsEqualTo(3)))
+}
+
+func TestValidateRunsValidationHandlers(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	filename, cleanup := writeConfigFile(t, "name: valid\nport: 80\n")
+	defer cleanup()
+
+	err := config.Validate(filename, testConfigDefaults,
+		config.ValidationHandler(func(cfg interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("port %v is reserved", cfg.(*testConfig).Port)
+		}))
+	var verr *config.ValidationError
+	assert.That(errors.As(err, &verr), pred.IsEqualTo(true))
+}
@@ -0,0 +1,61 @@
+package config
+
+import "fmt"
+
+// Warning describes a non-fatal issue found in the configuration, e.g. the
+// use of a deprecated key. Warnings do not prevent the configuration from
+// being applied.
+type Warning struct {
+	// Path is the path of the key the warning applies to, e.g. "server.port"
+	Path string
+
+	// Message describes the issue
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%v: %v", w.Path, w.Message)
+}
+
+// WarningHandler attaches a function to be called for each warning found
+// while loading the configuration, e.g. for keys matching fields tagged with
+// `deprecated:"use server.listen_addr"`.
+func WarningHandler(f func(w Warning)) Option {
+	return func(c *Loader) {
+		c.warningHandlers.add(f)
+	}
+}
+
+// AddWarningHandler attaches a function to be called for each warning, like
+// the WarningHandler option but after the loader is created. It returns a
+// function that detaches the handler.
+func (c *Loader) AddWarningHandler(f func(w Warning)) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.warningHandlers.add(f)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.warningHandlers.remove(id)
+	}
+}
+
+func (c *Loader) handleWarning(w Warning) {
+	c.mu.Lock()
+	handlers := c.warningHandlers.handlers()
+	c.mu.Unlock()
+
+	for _, h := range handlers {
+		c.invokeHandler(func() { h.f(w) })
+	}
+}
+
+// deprecationWarning returns the warning for a key matching a field tagged
+// with `deprecated:"..."`, where the tag value is an optional hint
+func deprecationWarning(path, hint string) Warning {
+	msg := "deprecated"
+	if hint != "" {
+		msg += ", " + hint
+	}
+	return Warning{Path: path, Message: msg}
+}
@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/marcus999/go-config"
+
+	"github.com/marcus999/go-testpredicate"
+	"github.com/marcus999/go-testpredicate/pred"
+)
+
+type deprecatedTestConfig struct {
+	Server struct {
+		ListenAddr string `json:"listen_addr"`
+		Host       string `json:"host" deprecated:"use server.listen_addr"`
+		Legacy     bool   `json:"legacy" deprecated:""`
+	} `json:"server"`
+}
+
+func TestDeprecatedFieldWarnings(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var warnings []config.Warning
+	icfg, errs := loadConfig(t, `
+server:
+  host: localhost
+  legacy: true
+`, deprecatedTestConfig{}, config.WarningHandler(func(w config.Warning) {
+		warnings = append(warnings, w)
+	}))
+	assert.That(errs, pred.IsEmpty())
+	assert.That(icfg.(*deprecatedTestConfig).Server.Host, pred.IsEqualTo("localhost"))
+	assert.That(warnings, pred.IsEqualTo([]config.Warning{
+		{Path: "server.host", Message: "deprecated, use server.listen_addr"},
+		{Path: "server.legacy", Message: "deprecated"},
+	}))
+	assert.That(warnings[0].String(), pred.IsEqualTo("server.host: deprecated, use server.listen_addr"))
+}
+
+func TestDeprecatedFieldWithoutKey(t *testing.T) {
+	assert := testpredicate.NewAsserter(t)
+
+	var warnings []config.Warning
+	_, errs := loadConfig(t, `
+server:
+  listen_addr: localhost:8080
+`, deprecatedTestConfig{}, config.WarningHandler(func(w config.Warning) {
+		warnings = append(warnings, w)
+	}))
+	assert.That(errs, pred.IsEmpty())
+	assert.That(warnings, pred.IsEmpty())
+}